@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"regexp"
+
 	"github.com/BurntSushi/toml"
 
 	"clock/internal/logger"
@@ -8,11 +11,21 @@ import (
 
 // Config 应用配置
 type Config struct {
-	Server  ServerConfig  `toml:"server"`
-	Storage StorageConfig `toml:"storage"`
-	Log     LogConfig     `toml:"log"`
-	Auth    AuthConfig    `toml:"auth"`
-	Message MessageConfig `toml:"message"`
+	Server    ServerConfig    `toml:"server"`
+	Storage   StorageConfig   `toml:"storage"`
+	Log       LogConfig       `toml:"log"`
+	Auth      AuthConfig      `toml:"auth"`
+	Message   MessageConfig   `toml:"message"`
+	Delete    DeleteConfig    `toml:"delete"`
+	TaskLog   TaskLogConfig   `toml:"tasklog"`
+	Retention RetentionConfig `toml:"retention"`
+	Masking   MaskingConfig   `toml:"masking"`
+	Journal   JournalConfig   `toml:"journal"`
+	Notify    NotifyConfig    `toml:"notify"`
+	Webhook   WebhookConfig   `toml:"webhook"`
+	Anomaly   AnomalyConfig   `toml:"anomaly"`
+	Report    ReportConfig    `toml:"report"`
+	Audit     AuditConfig     `toml:"audit"`
 }
 
 // ServerConfig 服务器配置
@@ -46,7 +59,104 @@ type AuthConfig struct {
 
 // MessageConfig 消息配置
 type MessageConfig struct {
-	Size int `toml:"size"`
+	Size    int `toml:"size"`
+	History int `toml:"history"` // 保留的最近事件数，用于断线重连时回放
+}
+
+// DeleteConfig 删除策略配置
+type DeleteConfig struct {
+	Trash       bool `toml:"trash"`        // 启用回收站（软删除，可恢复）
+	CascadeLogs bool `toml:"cascade_logs"` // 彻底删除时是否同时删除执行日志
+}
+
+// TaskLogConfig 任务输出存储配置
+type TaskLogConfig struct {
+	Backend   string `toml:"backend"`    // 存储后端: db, file
+	Dir       string `toml:"dir"`        // file 后端的输出目录
+	MaxOutput int    `toml:"max_output"` // 日志输出默认最大保存大小（KB），超出时保留头部和尾部
+}
+
+// RetentionConfig 日志保留策略配置
+//
+// 容器和任务可单独覆盖 max_age、max_count、failure_max_age；容器和任务的大小上限只约束各自范围内的日志，
+// 与全局 max_size 分别生效。
+type RetentionConfig struct {
+	Interval      int `toml:"interval"`        // 后台清理间隔（分钟），0 关闭
+	MaxAge        int `toml:"max_age"`         // 日志保留天数，0 不限
+	MaxCount      int `toml:"max_count"`       // 每个任务保留的日志条数，0 不限
+	MaxSize       int `toml:"max_size"`        // 日志输出总大小上限（MB），超出时从最旧的日志开始清理，0 不限
+	FailureMaxAge int `toml:"failure_max_age"` // 失败日志保留天数，设置后失败日志不计入 max_count，-1 永久保留，0 与 max_age 相同
+}
+
+// MaskingConfig 任务输出脱敏配置，匹配内容在实时推送和日志中替换为 ***
+type MaskingConfig struct {
+	SecretEnv      []string `toml:"secret_env"`      // 敏感环境变量名（支持 * 通配），其值在输出中脱敏
+	Patterns       []string `toml:"patterns"`        // 自定义正则，包含命名分组 secret 时只替换该分组
+	DisableBuiltin bool     `toml:"disable_builtin"` // 关闭内置的常见凭据格式（AWS/GitHub/Slack 密钥、JWT、URL 密码等）
+}
+
+// JournalConfig 批次事件日志配置，持久化实时事件用于回放历史批次
+type JournalConfig struct {
+	Enable bool `toml:"enable"`  // 启用事件日志（记录 run_start/run_end/task_start/task_end/meta）
+	Output bool `toml:"output"`  // 同时记录 stdout/stderr 输出行
+	MaxAge int  `toml:"max_age"` // 事件保留天数，0 不限
+}
+
+// NotifyConfig 通知配置，规则在容器和任务上配置，引用这里定义的通道
+type NotifyConfig struct {
+	StderrLines int                   `toml:"stderr_lines"` // 消息附带的最近错误输出行数
+	BaseURL     string                `toml:"base_url"`     // 界面访问地址，用于生成消息中的日志链接
+	Channels    []NotifyChannelConfig `toml:"channels"`
+}
+
+// NotifyChannelConfig 通知通道配置
+type NotifyChannelConfig struct {
+	Name     string            `toml:"name"`      // 通道名称，规则按名称引用
+	Type     string            `toml:"type"`      // webhook, email, slack, dingtalk, feishu
+	URL      string            `toml:"url"`       // webhook 地址
+	Headers  map[string]string `toml:"headers"`   // webhook 附加请求头
+	SMTPHost string            `toml:"smtp_host"` // 邮件服务器
+	SMTPPort int               `toml:"smtp_port"` // 邮件服务器端口，465 使用 TLS，其他端口支持时使用 STARTTLS
+	Username string            `toml:"username"`
+	Password string            `toml:"password"`
+	From     string            `toml:"from"`
+	To       []string          `toml:"to"`
+	Template string            `toml:"template"` // 消息模板（Go text/template），为空使用默认模板
+}
+
+// WebhookConfig 外发 webhook 投递配置，订阅在界面或 API 中管理
+type WebhookConfig struct {
+	Workers     int `toml:"workers"`      // 并发投递数
+	Timeout     int `toml:"timeout"`      // 单次请求超时（秒）
+	MaxAttempts int `toml:"max_attempts"` // 最大尝试次数，超过后标记为失败
+	Backoff     int `toml:"backoff"`      // 首次重试间隔（秒），之后每次翻倍
+	MaxBackoff  int `toml:"max_backoff"`  // 重试间隔上限（秒）
+	MaxAge      int `toml:"max_age"`      // 投递记录保留天数，0 不限
+}
+
+// AnomalyConfig 耗时异常检测配置，根据任务最近的成功执行耗时判断本次执行是否异常
+type AnomalyConfig struct {
+	Enable        bool    `toml:"enable"`         // 启用检测，发布 anomaly 事件
+	Method        string  `toml:"method"`         // percentile: 超过分位数；stddev: 超过均值加 N 倍标准差
+	Percentile    float64 `toml:"percentile"`     // 分位数（0-100），默认 99
+	Sigma         float64 `toml:"sigma"`          // 标准差倍数，默认 3
+	Window        int     `toml:"window"`         // 统计使用的最近执行记录数，默认 50
+	MinRuns       int     `toml:"min_runs"`       // 成功执行少于该数量时不检测，默认 10
+	MinDuration   int     `toml:"min_duration"`   // 阈值下限（秒），避免短任务的正常波动被标记，默认 10
+	TimeoutFactor float64 `toml:"timeout_factor"` // 建议超时时间为阈值的倍数，默认 1.5
+}
+
+// ReportConfig 运行汇总报表配置，报表通过通知通道发送
+type ReportConfig struct {
+	Daily    string   `toml:"daily"`    // 日报调度（cron 表达式），汇总前一天，为空不发送
+	Weekly   string   `toml:"weekly"`   // 周报调度（cron 表达式），汇总此前 7 天，为空不发送
+	Channels []string `toml:"channels"` // 发送报表的通知通道名称
+	Top      int      `toml:"top"`      // 失败列表和最慢任务的条数，默认 10
+}
+
+// AuditConfig 审计记录配置
+type AuditConfig struct {
+	MaxAge int `toml:"max_age"` // 审计记录保留天数，0 不限
 }
 
 // Load 从文件加载配置
@@ -60,6 +170,68 @@ func Load(path string) (*Config, error) {
 	if cfg.Message.Size <= 0 {
 		cfg.Message.Size = 1000
 	}
+	if cfg.Message.History <= 0 {
+		cfg.Message.History = 10000
+	}
+	if cfg.Notify.StderrLines <= 0 {
+		cfg.Notify.StderrLines = 20
+	}
+	if cfg.Webhook.Workers <= 0 {
+		cfg.Webhook.Workers = 4
+	}
+	if cfg.Webhook.Timeout <= 0 {
+		cfg.Webhook.Timeout = 10
+	}
+	if cfg.Webhook.MaxAttempts <= 0 {
+		cfg.Webhook.MaxAttempts = 8
+	}
+	if cfg.Webhook.Backoff <= 0 {
+		cfg.Webhook.Backoff = 10
+	}
+	if cfg.Webhook.MaxBackoff <= 0 {
+		cfg.Webhook.MaxBackoff = 3600
+	}
+	if cfg.Anomaly.Method == "" {
+		cfg.Anomaly.Method = "percentile"
+	}
+	if cfg.Anomaly.Method != "percentile" && cfg.Anomaly.Method != "stddev" {
+		return nil, fmt.Errorf("invalid anomaly method %q", cfg.Anomaly.Method)
+	}
+	if cfg.Anomaly.Percentile <= 0 || cfg.Anomaly.Percentile > 100 {
+		cfg.Anomaly.Percentile = 99
+	}
+	if cfg.Anomaly.Sigma <= 0 {
+		cfg.Anomaly.Sigma = 3
+	}
+	if cfg.Anomaly.Window <= 0 {
+		cfg.Anomaly.Window = 50
+	}
+	if cfg.Anomaly.MinRuns <= 0 {
+		cfg.Anomaly.MinRuns = 10
+	}
+	if cfg.Anomaly.MinDuration <= 0 {
+		cfg.Anomaly.MinDuration = 10
+	}
+	if cfg.Anomaly.TimeoutFactor < 1 {
+		cfg.Anomaly.TimeoutFactor = 1.5
+	}
+	if cfg.Report.Top <= 0 {
+		cfg.Report.Top = 10
+	}
+	if cfg.TaskLog.Backend == "" {
+		cfg.TaskLog.Backend = "db"
+	}
+	if cfg.TaskLog.Dir == "" {
+		cfg.TaskLog.Dir = "tasklogs"
+	}
+	if cfg.TaskLog.MaxOutput <= 0 {
+		cfg.TaskLog.MaxOutput = 10240
+	}
+	for _, pattern := range cfg.Masking.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid masking pattern %q: %w", pattern, err)
+		}
+	}
 
 	// 初始化日志
 	logCfg := &logger.Config{
@@ -0,0 +1,54 @@
+package domain
+
+import "strings"
+
+// APITokenPrefix API 令牌前缀，用于区分 API 令牌和 JWT
+const APITokenPrefix = "clk_"
+
+// API 令牌权限范围
+const (
+	ScopeReadContainers  = "read:containers"  // 查看容器、任务、关系图和执行状态
+	ScopeRunContainer    = "run:container"    // 运行和取消容器、任务和任务组
+	ScopeWriteContainers = "write:containers" // 修改容器、任务、关系和通知规则
+	ScopeReadLogs        = "read:logs"        // 查看日志和批次事件
+	ScopeWriteLogs       = "write:logs"       // 删除日志
+	ScopeAdmin           = "admin"            // 管理用户、授权、webhook 和日志存储，包含全部权限范围
+)
+
+// Scopes 全部权限范围
+var Scopes = []string{
+	ScopeReadContainers,
+	ScopeRunContainer,
+	ScopeWriteContainers,
+	ScopeReadLogs,
+	ScopeWriteLogs,
+	ScopeAdmin,
+}
+
+// APIToken 个人 API 令牌，以所属用户的角色访问接口，并受权限范围限制
+type APIToken struct {
+	ID         int    `json:"id" gorm:"primaryKey"`                // 令牌ID
+	UserID     int    `json:"user_id" gorm:"index:idx_token_user"` // 所属用户ID
+	Name       string `json:"name" gorm:"size:64"`                 // 名称
+	Prefix     string `json:"prefix" gorm:"size:16"`               // 令牌开头的字符，用于识别令牌
+	Hash       string `json:"-" gorm:"size:64;uniqueIndex"`        // 令牌的 SHA-256 哈希
+	Scopes     string `json:"scopes"`                              // 权限范围，逗号分隔
+	ExpireAt   int64  `json:"expire_at"`                           // 过期时间，0 表示永不过期
+	LastUsedAt int64  `json:"last_used_at"`                        // 最近使用时间
+	CreateAt   int64  `json:"create_at"`                           // 创建时间
+}
+
+// TableName 指定表名
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// HasScope 判断令牌是否包含权限范围
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package domain
+
+// 审计操作
+const (
+	AuditCreate = "create" // 新建
+	AuditUpdate = "update" // 修改（包括启用、禁用、恢复和修改密码）
+	AuditDelete = "delete" // 删除
+	AuditRun    = "run"    // 运行、重试和手动发送
+	AuditCancel = "cancel" // 取消
+	AuditSave   = "save"   // 新建或修改，记录时根据对象原来是否存在确定为 create 或 update
+)
+
+// 审计对象类型
+const (
+	AuditTargetTask       = "task"
+	AuditTargetContainer  = "container"
+	AuditTargetGroup      = "group"
+	AuditTargetRelation   = "relation"
+	AuditTargetNode       = "node"
+	AuditTargetLog        = "log"
+	AuditTargetRun        = "run"
+	AuditTargetNotifyRule = "notify_rule"
+	AuditTargetWebhook    = "webhook"
+	AuditTargetDelivery   = "delivery"
+	AuditTargetUser       = "user"
+	AuditTargetRole       = "role_binding"
+	AuditTargetToken      = "api_token"
+	AuditTargetReport     = "report"
+)
+
+// AuditLog 审计记录，记录通过接口进行的修改、运行和取消操作
+type AuditLog struct {
+	ID         int    `json:"id" gorm:"primaryKey"`                              // 记录ID
+	Actor      string `json:"actor" gorm:"size:64;index:idx_audit_actor"`        // 操作用户（JWT 中的用户名）
+	UserID     int    `json:"user_id"`                                           // 操作用户ID
+	TokenID    int    `json:"token_id"`                                          // 使用的 API 令牌ID，JWT 登录时为 0
+	Action     string `json:"action" gorm:"size:16"`                             // 操作: create, update, delete, run, cancel
+	TargetType string `json:"target_type" gorm:"size:32;index:idx_audit_target"` // 对象类型
+	TargetID   string `json:"target_id" gorm:"size:64;index:idx_audit_target"`   // 对象ID
+	Diff       string `json:"diff"`                                              // 对象修改前后变化的字段（JSON: {"字段": {"before": 值, "after": 值}}）
+	Detail     string `json:"detail"`                                            // 无法读取对象时记录的请求参数
+	Method     string `json:"method" gorm:"size:8"`                              // 请求方法
+	Path       string `json:"path"`                                              // 请求路径
+	IP         string `json:"ip" gorm:"size:64"`                                 // 客户端IP
+	CreateAt   int64  `json:"create_at" gorm:"index"`                            // 操作时间(秒)
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditChange 字段修改前后的值
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
@@ -16,6 +16,39 @@ const (
 	DBBackendPostgres = "postgres"
 )
 
+// 日志输出流
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+	LogStreamMeta   = "meta" // 执行器写入的说明行（如截断标记）
+	LogStreamAll    = "all"  // 按时间顺序合并的全部输出（仅用于读取）
+)
+
+// 日志输出格式
+const (
+	LogFormatBlob  = "blob"  // stdout、stderr 分别整体保存（旧格式）
+	LogFormatLines = "lines" // 按时间顺序保存的行记录
+)
+
+// 日志输出存储后端
+const (
+	LogStorageDB   = "db"
+	LogStorageFile = "file"
+)
+
+// 关系图导出格式
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+	GraphFormatSVG     = "svg"
+)
+
+// 关系图节点类型
+const (
+	NodeKindTask  = "task"
+	NodeKindGroup = "group"
+)
+
 // StatusText 返回状态文本
 func StatusText(status int) string {
 	switch status {
@@ -1,15 +1,25 @@
 package domain
 
+import "gorm.io/gorm"
+
 // Container 任务容器实体
 type Container struct {
-	Cid        int    `json:"cid" gorm:"primaryKey"`        // 主键
-	EntryID    int    `json:"entry_id"`                     // cron生成的调度ID
-	Name       string `json:"name"`                         // 名称
-	Expression string `json:"expression"`                   // cron表达式
-	Status     int    `json:"status" gorm:"default:1"`      // 当前状态
-	Disable    bool   `json:"disable"`                      // 是否禁用
-	Blocking   bool   `json:"blocking" gorm:"default:true"` // 阻塞模式（上次未完成则跳过）
-	UpdateAt   int64  `json:"update_at"`                    // 修改时间
+	Cid              int    `json:"cid" gorm:"primaryKey"`        // 主键
+	EntryID          int    `json:"entry_id"`                     // cron生成的调度ID
+	Name             string `json:"name"`                         // 名称
+	Expression       string `json:"expression"`                   // cron表达式
+	Status           int    `json:"status" gorm:"default:1"`      // 当前状态
+	Disable          bool   `json:"disable"`                      // 是否禁用
+	Blocking         bool   `json:"blocking" gorm:"default:true"` // 阻塞模式（上次未完成则跳过）
+	LogMaxAge        int    `json:"log_max_age"`                  // 日志保留天数，0 继承全局配置，-1 不限
+	LogMaxCount      int    `json:"log_max_count"`                // 每个任务的日志保留条数，0 继承全局配置，-1 不限
+	LogFailureMaxAge int    `json:"log_failure_max_age"`          // 失败日志保留天数，0 继承全局配置，-1 不限
+	LogMaxSize       int    `json:"log_max_size"`                 // 容器内所有日志的输出总大小上限(MB)，0 不限
+	SlaDeadline      string `json:"sla_deadline"`                 // SLA 截止时间："06:00" 为调度时间之后的该时刻，"4h30m" 为相对调度时间的偏移
+	SlaMaxDuration   int    `json:"sla_max_duration"`             // SLA 预期最长运行时间(秒)，0 不检查
+	UpdateAt         int64  `json:"update_at"`                    // 修改时间
+
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"` // 删除时间（回收站）
 }
 
 // TableName 指定表名
@@ -0,0 +1,25 @@
+package domain
+
+// 通知规则的触发范围（容器规则）
+const (
+	NotifyScopeRun  = "run"  // 容器的一次调度批次结束
+	NotifyScopeTask = "task" // 容器内任一任务结束
+)
+
+// NotifyRule 通知规则实体，挂在容器或任务上，按结束状态选择是否通知
+type NotifyRule struct {
+	ID       int    `json:"id" gorm:"primaryKey"`            // 规则ID
+	Cid      int    `json:"cid" gorm:"index:idx_notify_cid"` // 容器ID
+	Tid      int    `json:"tid" gorm:"index:idx_notify_tid"` // 任务ID（0 表示容器规则）
+	Scope    string `json:"scope"`                           // 容器规则的触发范围: run, task
+	Channel  string `json:"channel"`                         // 通知通道名称（config.toml 中定义）
+	Statuses string `json:"statuses"`                        // 触发通知的结束状态，逗号分隔: success, failure, cancelled
+	Template string `json:"template" gorm:"type:text"`       // 消息模板，为空使用通道模板
+	Disable  bool   `json:"disable"`                         // 禁用规则
+	UpdateAt int64  `json:"update_at"`                       // 修改时间
+}
+
+// TableName 指定表名
+func (NotifyRule) TableName() string {
+	return "notify_rules"
+}
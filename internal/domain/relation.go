@@ -1,12 +1,21 @@
 package domain
 
+import "gorm.io/gorm"
+
 // Relation 任务关系实体（DAG边）
+//
+// 端点可以是任务或任务组：Gid/NextGid 非 0 时该端点为任务组，
+// 执行时展开为任务组的入口/出口任务。
 type Relation struct {
-	Rid      int   `json:"rid" gorm:"primaryKey"`                     // 关系ID
-	Cid      int   `json:"cid" gorm:"index:idx_relation_cid"`         // 容器ID
-	Tid      int   `json:"tid" gorm:"uniqueIndex:uidx_tid_next"`      // 前置任务ID
-	NextTid  int   `json:"next_tid" gorm:"uniqueIndex:uidx_tid_next"` // 后续任务ID
-	UpdateAt int64 `json:"update_at"`                                 // 修改时间
+	Rid      int   `json:"rid" gorm:"primaryKey"`                          // 关系ID
+	Cid      int   `json:"cid" gorm:"index:idx_relation_cid"`              // 容器ID
+	Tid      int   `json:"tid" gorm:"uniqueIndex:uidx_relation_edge"`      // 前置任务ID
+	NextTid  int   `json:"next_tid" gorm:"uniqueIndex:uidx_relation_edge"` // 后续任务ID
+	Gid      int   `json:"gid" gorm:"uniqueIndex:uidx_relation_edge"`      // 前置任务组ID
+	NextGid  int   `json:"next_gid" gorm:"uniqueIndex:uidx_relation_edge"` // 后续任务组ID
+	UpdateAt int64 `json:"update_at"`                                      // 修改时间
+
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // 删除时间（随任务/容器进入回收站）
 }
 
 // TableName 指定表名
@@ -21,6 +30,8 @@ type Link struct {
 	Cid     int    `json:"cid"`
 	Tid     int    `json:"tid"`
 	NextTid int    `json:"next_tid"`
+	Gid     int    `json:"gid,omitempty"`
+	NextGid int    `json:"next_gid,omitempty"`
 }
 
 // ToLink 将Relation转换为Link
@@ -30,11 +41,18 @@ func (r *Relation) ToLink() Link {
 		Cid:     r.Cid,
 		Tid:     r.Tid,
 		NextTid: r.NextTid,
+		Gid:     r.Gid,
+		NextGid: r.NextGid,
 	}
 }
 
 // RelationGraph 关系图（视图对象）
 type RelationGraph struct {
-	Nodes []Node `json:"nodes"`
-	Links []Link `json:"links"`
+	Nodes  []Node      `json:"nodes"`
+	Links  []Link      `json:"links"`
+	Groups []TaskGroup `json:"groups"`
+
+	CriticalPath      []int `json:"critical_path,omitempty"`      // 关键路径上的任务ID（按执行顺序）
+	CriticalDuration  int64 `json:"critical_duration,omitempty"`  // 关键路径总耗时(毫秒)
+	EstimatedDuration int64 `json:"estimated_duration,omitempty"` // 预计容器总耗时(毫秒)
 }
@@ -0,0 +1,119 @@
+package domain
+
+import "sort"
+
+// Role 角色，高级角色包含低级角色的全部权限
+type Role string
+
+// 角色定义
+const (
+	RoleViewer   Role = "viewer"   // 查看关系图、执行状态和日志
+	RoleOperator Role = "operator" // 运行和取消
+	RoleEditor   Role = "editor"   // 修改容器、任务、关系和通知规则
+	RoleAdmin    Role = "admin"    // 管理用户和授权
+)
+
+// roleLevels 角色级别
+var roleLevels = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleEditor:   3,
+	RoleAdmin:    4,
+}
+
+// Level 返回角色级别，无效角色为 0
+func (r Role) Level() int {
+	return roleLevels[r]
+}
+
+// Valid 判断角色是否有效
+func (r Role) Valid() bool {
+	return r.Level() > 0
+}
+
+// Includes 判断角色是否包含 other 的权限
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && r.Level() >= other.Level()
+}
+
+// RoleBinding 用户角色授权，Cid 为 0 时对全部容器生效
+type RoleBinding struct {
+	ID       int   `json:"id" gorm:"primaryKey"`                             // 授权ID
+	UserID   int   `json:"user_id" gorm:"uniqueIndex:uidx_binding_user_cid"` // 用户ID
+	Cid      int   `json:"cid" gorm:"uniqueIndex:uidx_binding_user_cid"`     // 容器ID（0 表示全局）
+	Role     Role  `json:"role" gorm:"size:16"`                              // 角色
+	CreateAt int64 `json:"create_at"`                                        // 创建时间
+}
+
+// TableName 指定表名
+func (RoleBinding) TableName() string {
+	return "role_bindings"
+}
+
+// Permissions 用户生效的权限（视图对象）
+type Permissions struct {
+	Global     Role         `json:"global"`     // 全局角色，为空表示没有全局授权
+	Containers map[int]Role `json:"containers"` // 容器角色
+}
+
+// NewPermissions 合并用户的全部授权
+func NewPermissions(bindings []*RoleBinding) *Permissions {
+	p := &Permissions{Containers: make(map[int]Role)}
+	for _, binding := range bindings {
+		if binding.Cid == 0 {
+			p.Global = maxRole(p.Global, binding.Role)
+		} else {
+			p.Containers[binding.Cid] = maxRole(p.Containers[binding.Cid], binding.Role)
+		}
+	}
+	return p
+}
+
+// Role 返回用户在容器中的生效角色（全局角色和容器角色取高者），cid 为 0 时返回全局角色
+func (p *Permissions) Role(cid int) Role {
+	if cid == 0 {
+		return p.Global
+	}
+	return maxRole(p.Global, p.Containers[cid])
+}
+
+// Can 判断用户在容器中是否具有 role 的权限，cid 为 0 时要求全局角色
+func (p *Permissions) Can(cid int, role Role) bool {
+	return p.Role(cid).Includes(role)
+}
+
+// CanAny 判断用户是否在全局或任一容器中具有 role 的权限
+func (p *Permissions) CanAny(role Role) bool {
+	if p.Global.Includes(role) {
+		return true
+	}
+	for _, r := range p.Containers {
+		if r.Includes(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibleCids 返回用户可查看的容器ID，具有全局查看权限时返回 nil（不限）
+func (p *Permissions) VisibleCids() []int {
+	if p.Global.Includes(RoleViewer) {
+		return nil
+	}
+	cids := make([]int, 0, len(p.Containers))
+	for cid, role := range p.Containers {
+		if role.Includes(RoleViewer) {
+			cids = append(cids, cid)
+		}
+	}
+	sort.Ints(cids)
+	return cids
+}
+
+// maxRole 返回级别较高的角色
+func maxRole(a, b Role) Role {
+	if b.Level() > a.Level() {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,20 @@
+package domain
+
+// RunEvent 批次事件日志（持久化的实时事件，用于回放历史批次）
+type RunEvent struct {
+	ID         int64  `json:"id" gorm:"primaryKey"`                // 记录ID（批次内按此排序）
+	RunID      string `json:"run_id" gorm:"index:idx_event_runid"` // 批次ID
+	Ts         int64  `json:"ts" gorm:"index"`                     // 事件时间(毫秒)
+	Kind       string `json:"kind"`                                // 事件类型
+	Tid        int    `json:"tid"`                                 // 任务ID
+	Cid        int    `json:"cid"`                                 // 容器ID
+	TaskName   string `json:"task_name"`                           // 任务名称
+	Status     string `json:"status"`                              // 结束状态（task_end / run_end）
+	DurationMs int64  `json:"duration_ms"`                         // 耗时(毫秒)
+	Msg        string `json:"msg"`                                 // 消息内容
+}
+
+// TableName 指定表名
+func (RunEvent) TableName() string {
+	return "run_events"
+}
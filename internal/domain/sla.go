@@ -0,0 +1,27 @@
+package domain
+
+// SLA 未达成类型
+const (
+	SlaKindDeadline = "deadline" // 截止时间前未成功完成
+	SlaKindDuration = "duration" // 运行时间超过预期
+)
+
+// SlaMiss SLA 未达成记录
+type SlaMiss struct {
+	ID          int    `json:"id" gorm:"primaryKey"`         // 记录ID
+	Cid         int    `json:"cid" gorm:"index:idx_sla_cid"` // 容器ID
+	Tid         int    `json:"tid"`                          // 任务ID（容器级 SLA 为 0）
+	RunID       string `json:"run_id"`                       // 批次ID（未运行时为空）
+	Kind        string `json:"kind"`                         // 类型: deadline, duration
+	ScheduledAt int64  `json:"scheduled_at"`                 // 逻辑调度时间(秒)，deadline 类型有效
+	Deadline    int64  `json:"deadline"`                     // 截止时间(秒)，deadline 类型有效
+	Expected    int64  `json:"expected"`                     // 预期最长运行时间(毫秒)，duration 类型有效
+	Actual      int64  `json:"actual"`                       // 发现时已运行的时间(毫秒)
+	Msg         string `json:"msg"`                          // 说明
+	CreateAt    int64  `json:"create_at" gorm:"index"`       // 发现时间(秒)
+}
+
+// TableName 指定表名
+func (SlaMiss) TableName() string {
+	return "sla_misses"
+}
@@ -1,19 +1,31 @@
 package domain
 
+import "gorm.io/gorm"
+
 // Task 任务实体
 type Task struct {
-	Tid       int    `json:"tid" gorm:"primaryKey"`         // 任务ID
-	Cid       int    `json:"cid" gorm:"index:idx_task_cid"` // 容器ID
-	Command   string `json:"command"`                       // bash命令
-	Name      string `json:"name"`                          // 任务名称
-	Directory string `json:"directory"`                     // 工作目录
-	Disable   bool   `json:"disable"`                       // 是否禁用
-	Status    int    `json:"status" gorm:"default:1"`       // 当前状态
-	Timeout   int    `json:"timeout"`                       // 超时时间(秒)
-	UpdateAt  int64  `json:"update_at"`                     // 修改时间
-	LogEnable bool   `json:"log_enable"`                    // 是否启用日志
-	PointX    int    `json:"point_x"`                       // 可视化坐标X
-	PointY    int    `json:"point_y"`                       // 可视化坐标Y
+	Tid              int    `json:"tid" gorm:"primaryKey"`         // 任务ID
+	Cid              int    `json:"cid" gorm:"index:idx_task_cid"` // 容器ID
+	Gid              int    `json:"gid"`                           // 所属任务组ID（0 表示不分组）
+	Command          string `json:"command"`                       // bash命令
+	Name             string `json:"name"`                          // 任务名称
+	Directory        string `json:"directory"`                     // 工作目录
+	Disable          bool   `json:"disable"`                       // 是否禁用
+	Status           int    `json:"status" gorm:"default:1"`       // 当前状态
+	Timeout          int    `json:"timeout"`                       // 超时时间(秒)
+	Duration         int64  `json:"duration"`                      // 最近一次执行耗时(毫秒)
+	UpdateAt         int64  `json:"update_at"`                     // 修改时间
+	LogEnable        bool   `json:"log_enable"`                    // 是否启用日志
+	MaxOutput        int    `json:"max_output"`                    // 日志输出最大保存大小(KB)，0 使用全局配置
+	LogMaxAge        int    `json:"log_max_age"`                   // 日志保留天数，0 继承容器/全局配置，-1 不限
+	LogMaxCount      int    `json:"log_max_count"`                 // 日志保留条数，0 继承容器/全局配置，-1 不限
+	LogFailureMaxAge int    `json:"log_failure_max_age"`           // 失败日志保留天数，0 继承容器/全局配置，-1 不限
+	LogMaxSize       int    `json:"log_max_size"`                  // 该任务日志的输出总大小上限(MB)，0 不限（与容器和全局上限分别生效）
+	SlaMaxDuration   int    `json:"sla_max_duration"`              // SLA 预期最长运行时间(秒)，0 不检查；与 Timeout 不同，超出后只告警不终止
+	PointX           int    `json:"point_x"`                       // 可视化坐标X
+	PointY           int    `json:"point_y"`                       // 可视化坐标Y
+
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"` // 删除时间（回收站）
 }
 
 // TableName 指定表名
@@ -23,19 +35,47 @@ func (Task) TableName() string {
 
 // Node 关系图节点（视图对象）
 type Node struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	Status int    `json:"status"`
-	X      int    `json:"x"`
-	Y      int    `json:"y"`
+	ID     int        `json:"id"`
+	Kind   string     `json:"kind"` // task | group（折叠的任务组）
+	Name   string     `json:"name"`
+	Status int        `json:"status"`
+	Gid    int        `json:"gid,omitempty"`
+	X      int        `json:"x"`
+	Y      int        `json:"y"`
+	Stats  *NodeStats `json:"stats,omitempty"`
+}
+
+// NodeStats 节点历史执行统计（视图对象）
+type NodeStats struct {
+	RecentStatuses []int `json:"recent_statuses"` // 最近 N 次执行状态（新的在前）
+	AvgDuration    int64 `json:"avg_duration"`    // 成功执行的平均耗时(毫秒)
+	P95Duration    int64 `json:"p95_duration"`    // 成功执行耗时的 P95(毫秒)
+	Critical       bool  `json:"critical"`        // 是否位于关键路径上
+}
+
+// DurationStats 任务历史执行耗时统计（视图对象），耗时单位为毫秒
+type DurationStats struct {
+	Tid              int   `json:"tid"`
+	Runs             int   `json:"runs"`              // 参与统计的成功执行数
+	Mean             int64 `json:"mean"`              // 平均耗时
+	Stddev           int64 `json:"stddev"`            // 标准差
+	P50              int64 `json:"p50"`               // 中位数
+	P95              int64 `json:"p95"`               // P95
+	P99              int64 `json:"p99"`               // P99
+	Max              int64 `json:"max"`               // 最长耗时
+	Threshold        int64 `json:"threshold"`         // 异常阈值，样本不足时为 0
+	Timeout          int   `json:"timeout"`           // 当前超时时间(秒)
+	SuggestedTimeout int   `json:"suggested_timeout"` // 建议超时时间(秒)，样本不足时为 0
 }
 
 // ToNode 将Task转换为Node
 func (t *Task) ToNode() Node {
 	return Node{
 		ID:     t.Tid,
+		Kind:   NodeKindTask,
 		Name:   t.Name,
 		Status: t.Status,
+		Gid:    t.Gid,
 		X:      t.PointX,
 		Y:      t.PointY,
 	}
@@ -0,0 +1,16 @@
+package domain
+
+// TaskGroup 任务组实体（容器内的子DAG，可嵌套）
+type TaskGroup struct {
+	Gid       int    `json:"gid" gorm:"primaryKey"`          // 任务组ID
+	Cid       int    `json:"cid" gorm:"index:idx_group_cid"` // 容器ID
+	ParentGid int    `json:"parent_gid"`                     // 父任务组ID（0 表示顶层）
+	Name      string `json:"name"`                           // 名称
+	Collapsed bool   `json:"collapsed"`                      // 关系图中默认折叠
+	UpdateAt  int64  `json:"update_at"`                      // 修改时间
+}
+
+// TableName 指定表名
+func (TaskGroup) TableName() string {
+	return "task_groups"
+}
@@ -2,15 +2,44 @@ package domain
 
 // TaskLog 任务执行日志
 type TaskLog struct {
-	Lid      string `json:"lid" gorm:"primaryKey"`        // 日志ID
-	Tid      int    `json:"tid" gorm:"index:idx_log_tid"` // 任务ID
-	Cid      int    `json:"cid" gorm:"index:idx_log_cid"` // 容器ID
-	StdOut   string `json:"std_out"`                      // 标准输出
-	StdErr   string `json:"std_err"`                      // 标准错误
-	UpdateAt int64  `json:"update_at" gorm:"index"`       // 创建时间
+	Lid       string `json:"lid" gorm:"primaryKey"`              // 日志ID
+	RunID     string `json:"run_id" gorm:"index:idx_log_runid"`  // 批次ID（单独运行时为空）
+	Tid       int    `json:"tid" gorm:"index:idx_log_tid"`       // 任务ID
+	Cid       int    `json:"cid" gorm:"index:idx_log_cid"`       // 容器ID
+	Status    int    `json:"status" gorm:"index:idx_log_status"` // 任务结束状态（运行中为 0）
+	Storage   string `json:"storage"`                            // 输出存储后端（db, file）
+	Format    string `json:"format"`                             // 输出格式（blob, lines），为空表示 blob
+	LineCount int64  `json:"line_count"`                         // 行记录数（lines 格式）
+	StartAt   int64  `json:"start_at"`                           // 开始时间（毫秒），用于计算行的相对时间
+	OutSize   int64  `json:"out_size"`                           // 标准输出字节数
+	ErrSize   int64  `json:"err_size"`                           // 标准错误字节数
+	Running   bool   `json:"running"`                            // 任务仍在执行，输出持续写入中
+	Truncated bool   `json:"truncated"`                          // 输出超过上限，仅保留头部和尾部
+	StdOut    string `json:"std_out,omitempty"`                  // 标准输出（db 后端）
+	StdErr    string `json:"std_err,omitempty"`                  // 标准错误（db 后端）
+	Records   string `json:"-"`                                  // 行记录（db 后端，lines 格式）
+	Indexed   bool   `json:"indexed"`                            // 输出已写入全文索引
+	UpdateAt  int64  `json:"update_at" gorm:"index"`             // 创建时间
+
+	Matches []LogMatch `json:"matches,omitempty" gorm:"-"` // 搜索命中的行（仅搜索时返回）
 }
 
 // TableName 指定表名
 func (TaskLog) TableName() string {
 	return "task_logs"
 }
+
+// LogLine 日志行记录
+type LogLine struct {
+	No     int64  `json:"no"`     // 行号（从 1 开始，截断后保留原行号）
+	Ts     int64  `json:"ts"`     // 时间戳（毫秒）
+	Offset int64  `json:"offset"` // 相对日志开始的毫秒数
+	Stream string `json:"stream"` // stdout, stderr, meta
+	Text   string `json:"text"`   // 行内容
+}
+
+// LogMatch 日志搜索命中的行
+type LogMatch struct {
+	No      int64  `json:"no"`      // 行号
+	Snippet string `json:"snippet"` // 高亮片段，命中内容以 <mark></mark> 标记
+}
@@ -0,0 +1,20 @@
+package domain
+
+// TaskRun 任务执行记录（每次执行一条，用于历史统计）
+type TaskRun struct {
+	ID       int    `json:"id" gorm:"primaryKey"`              // 记录ID
+	RunID    string `json:"run_id" gorm:"index:idx_run_runid"` // 批次ID（单独运行时为空）
+	Tid      int    `json:"tid" gorm:"index:idx_run_tid"`      // 任务ID
+	Cid      int    `json:"cid" gorm:"index:idx_run_cid"`      // 容器ID
+	Status   int    `json:"status"`                            // 结束状态
+	ExitCode int    `json:"exit_code"`                         // 进程退出码（未正常退出为 -1）
+	StartAt  int64  `json:"start_at"`                          // 开始时间(毫秒)
+	EndAt    int64  `json:"end_at" gorm:"index"`               // 结束时间(毫秒)
+	Duration int64  `json:"duration"`                          // 耗时(毫秒)
+	Msg      string `json:"msg"`                               // 错误信息
+}
+
+// TableName 指定表名
+func (TaskRun) TableName() string {
+	return "task_runs"
+}
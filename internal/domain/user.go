@@ -0,0 +1,18 @@
+package domain
+
+// User 用户
+type User struct {
+	ID                 int    `json:"id" gorm:"primaryKey"`            // 用户ID
+	Name               string `json:"name" gorm:"size:64;uniqueIndex"` // 用户名
+	Password           string `json:"-"`                               // bcrypt 密码哈希
+	Disable            bool   `json:"disable"`                         // 是否禁用
+	MustChangePassword bool   `json:"must_change_password"`            // 下次登录后必须修改密码（初始账号或被重置密码）
+	LastLoginAt        int64  `json:"last_login_at"`                   // 最近登录时间
+	CreateAt           int64  `json:"create_at"`                       // 创建时间
+	UpdateAt           int64  `json:"update_at"`                       // 修改时间
+}
+
+// TableName 指定表名
+func (User) TableName() string {
+	return "users"
+}
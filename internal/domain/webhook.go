@@ -0,0 +1,47 @@
+package domain
+
+// 投递状态
+const (
+	DeliveryPending = "pending" // 等待投递或重试
+	DeliverySuccess = "success" // 投递成功
+	DeliveryFailed  = "failed"  // 超过最大尝试次数
+)
+
+// Webhook 外发 webhook 订阅，将选中类型的事件以 JSON POST 投递到 URL
+type Webhook struct {
+	ID       int    `json:"id" gorm:"primaryKey"` // 订阅ID
+	Name     string `json:"name"`                 // 名称
+	URL      string `json:"url"`                  // 投递地址
+	Secret   string `json:"secret,omitempty"`     // 签名密钥（HMAC-SHA256），查询时不返回
+	Kinds    string `json:"kinds"`                // 订阅的事件类型，逗号分隔，为空订阅全部生命周期事件
+	Cid      int    `json:"cid"`                  // 只投递该容器的事件，0 不限
+	Disable  bool   `json:"disable"`              // 禁用订阅
+	UpdateAt int64  `json:"update_at"`            // 修改时间
+}
+
+// TableName 指定表名
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// WebhookDelivery webhook 投递记录，失败时按指数退避重试
+type WebhookDelivery struct {
+	ID        string `json:"id" gorm:"primaryKey;size:32"`                     // 投递ID
+	WebhookID int    `json:"webhook_id" gorm:"index:idx_delivery_webhook"`     // 订阅ID
+	EventID   int64  `json:"event_id"`                                         // 事件ID
+	Kind      string `json:"kind"`                                             // 事件类型
+	Payload   string `json:"payload" gorm:"type:text"`                         // 请求体
+	Status    string `json:"status" gorm:"index:idx_delivery_due,priority:1"`  // 投递状态
+	Attempts  int    `json:"attempts"`                                         // 已尝试次数
+	NextAt    int64  `json:"next_at" gorm:"index:idx_delivery_due,priority:2"` // 下次尝试时间(毫秒)
+	LastCode  int    `json:"last_code"`                                        // 最后一次响应状态码
+	LastError string `json:"last_error"`                                       // 最后一次错误信息
+	Duration  int64  `json:"duration"`                                         // 最后一次请求耗时(毫秒)
+	CreateAt  int64  `json:"create_at" gorm:"index"`                           // 创建时间(毫秒)
+	UpdateAt  int64  `json:"update_at"`                                        // 修改时间(毫秒)
+}
+
+// TableName 指定表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -15,6 +15,16 @@ const (
 	ErrDatabase
 	// ErrScheduler 调度器错误
 	ErrScheduler
+	// ErrInvalidParam 参数错误
+	ErrInvalidParam
+	// ErrStorage 日志存储错误
+	ErrStorage
+	// ErrNotify 通知发送错误
+	ErrNotify
+	// ErrUnauthorized 未认证
+	ErrUnauthorized
+	// ErrForbidden 无权限
+	ErrForbidden
 )
 
 // AppError 应用错误
@@ -72,6 +82,48 @@ func Scheduler(err error) *AppError {
 	}
 }
 
+// InvalidParam 创建参数错误
+func InvalidParam(msg string) *AppError {
+	return &AppError{
+		Code:    ErrInvalidParam,
+		Message: msg,
+	}
+}
+
+// Storage 创建日志存储错误
+func Storage(err error) *AppError {
+	return &AppError{
+		Code:    ErrStorage,
+		Message: "storage error",
+		Err:     err,
+	}
+}
+
+// Notify 创建通知发送错误
+func Notify(err error) *AppError {
+	return &AppError{
+		Code:    ErrNotify,
+		Message: "notify error",
+		Err:     err,
+	}
+}
+
+// Unauthorized 创建未认证错误
+func Unauthorized(msg string) *AppError {
+	return &AppError{
+		Code:    ErrUnauthorized,
+		Message: msg,
+	}
+}
+
+// Forbidden 创建无权限错误
+func Forbidden(msg string) *AppError {
+	return &AppError{
+		Code:    ErrForbidden,
+		Message: msg,
+	}
+}
+
 // IsNotFound 判断是否为未找到错误
 func IsNotFound(err error) bool {
 	var appErr *AppError
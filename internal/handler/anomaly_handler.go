@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/logger"
+	"clock/internal/service"
+)
+
+// AnomalyHandler 耗时异常检测处理器
+type AnomalyHandler struct {
+	anomalyDetector service.AnomalyDetector
+}
+
+// NewAnomalyHandler 创建耗时异常检测处理器
+func NewAnomalyHandler(anomalyDetector service.AnomalyDetector) *AnomalyHandler {
+	return &AnomalyHandler{
+		anomalyDetector: anomalyDetector,
+	}
+}
+
+// GetDurationStats 获取任务的耗时统计和建议超时时间
+func (h *AnomalyHandler) GetDurationStats(c echo.Context) error {
+	tid, err := getPathInt(c, "tid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	stats, err := h.anomalyDetector.Stats(tid)
+	if err != nil {
+		logger.Errorf("[GetDurationStats] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, stats)
+}
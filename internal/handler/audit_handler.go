@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/logger"
+	"clock/internal/repository"
+	"clock/internal/service"
+)
+
+// AuditHandler 审计记录处理器
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditHandler 创建审计记录处理器
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetAudits 分页查询审计记录（actor、action、target_type、target_id 过滤，left_ts、right_ts 为时间范围，单位秒）
+func (h *AuditHandler) GetAudits(c echo.Context) error {
+	query := &repository.AuditQuery{
+		Page: repository.Page{
+			Count:   getQueryIntDefault(c, "count", 10),
+			Index:   getQueryIntDefault(c, "index", 1),
+			LeftTs:  getQueryInt64Default(c, "left_ts", 0),
+			RightTs: getQueryInt64Default(c, "right_ts", 0),
+		},
+		Actor:      c.QueryParam("actor"),
+		Action:     c.QueryParam("action"),
+		TargetType: c.QueryParam("target_type"),
+		TargetID:   c.QueryParam("target_id"),
+	}
+
+	result, err := h.auditService.List(query)
+	if err != nil {
+		logger.Errorf("[GetAudits] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, result)
+}
@@ -8,17 +8,21 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"clock/internal/config"
+	"clock/internal/logger"
+	"clock/internal/service"
 )
 
 // AuthHandler 认证处理器
 type AuthHandler struct {
-	cfg *config.AuthConfig
+	cfg         *config.AuthConfig
+	userService service.UserService
 }
 
 // NewAuthHandler 创建认证处理器
-func NewAuthHandler(cfg *config.AuthConfig) *AuthHandler {
+func NewAuthHandler(cfg *config.AuthConfig, userService service.UserService) *AuthHandler {
 	return &AuthHandler{
-		cfg: cfg,
+		cfg:         cfg,
+		userService: userService,
 	}
 }
 
@@ -30,7 +34,9 @@ type LoginRequest struct {
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token              string `json:"token"`
+	Name               string `json:"name"`
+	MustChangePassword bool   `json:"must_change_password"` // 必须先修改密码才能使用其他接口
 }
 
 // Login 用户登录
@@ -41,14 +47,17 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	}
 
 	// 验证用户名密码
-	if req.User != h.cfg.User || req.Password != h.cfg.Password {
-		return Unauthorized(c, "invalid username or password")
+	user, err := h.userService.Authenticate(req.User, req.Password)
+	if err != nil {
+		logger.Warnf("[Login] user %s failed: %v", req.User, err)
+		return HandleError(c, err)
 	}
 
 	// 生成JWT token
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
-	claims["name"] = req.User
+	claims["name"] = user.Name
+	claims["uid"] = user.ID
 	claims["exp"] = time.Now().Add(time.Hour * 24).Unix()
 
 	t, err := token.SignedString([]byte(h.cfg.JWTSecret))
@@ -68,5 +77,5 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		Expires:  time.Now().Add(24 * time.Hour),
 	})
 
-	return OK(c, LoginResponse{Token: t})
+	return OK(c, LoginResponse{Token: t, Name: user.Name, MustChangePassword: user.MustChangePassword})
 }
@@ -5,6 +5,7 @@ import (
 
 	"clock/internal/domain"
 	"clock/internal/logger"
+	"clock/internal/middleware"
 	"clock/internal/repository"
 	"clock/internal/service"
 )
@@ -29,7 +30,9 @@ func (h *ContainerHandler) GetContainers(c echo.Context) error {
 			Index: getQueryIntDefault(c, "index", 1),
 			Order: c.QueryParam("order"),
 		},
-		Name: c.QueryParam("name"),
+		Cids:    middleware.VisibleCids(c),
+		Name:    c.QueryParam("name"),
+		Trashed: getQueryBool(c, "trashed"),
 	}
 
 	result, err := h.containerService.List(query)
@@ -79,7 +82,12 @@ func (h *ContainerHandler) DeleteContainer(c echo.Context) error {
 		return BadRequest(c, err.Error())
 	}
 
-	if err := h.containerService.Delete(cid); err != nil {
+	opts := &service.DeleteOptions{
+		Purge:       getQueryBool(c, "purge"),
+		CascadeLogs: getQueryBool(c, "logs"),
+	}
+
+	if err := h.containerService.Delete(cid, opts); err != nil {
 		logger.Errorf("[DeleteContainer] failed: %v", err)
 		return HandleError(c, err)
 	}
@@ -87,6 +95,21 @@ func (h *ContainerHandler) DeleteContainer(c echo.Context) error {
 	return OK(c, nil)
 }
 
+// RestoreContainer 从回收站恢复容器
+func (h *ContainerHandler) RestoreContainer(c echo.Context) error {
+	cid, err := getPathInt(c, "cid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.containerService.Restore(cid); err != nil {
+		logger.Errorf("[RestoreContainer] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
 // RunContainer 执行容器
 func (h *ContainerHandler) RunContainer(c echo.Context) error {
 	cid, err := getQueryInt(c, "cid")
@@ -101,3 +124,24 @@ func (h *ContainerHandler) RunContainer(c echo.Context) error {
 
 	return OK(c, nil)
 }
+
+// CloneContainer 克隆容器（包括任务和关系）
+func (h *ContainerHandler) CloneContainer(c echo.Context) error {
+	cid, err := getPathInt(c, "cid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	var opts service.CloneOptions
+	if err := c.Bind(&opts); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	container, err := h.containerService.Clone(cid, &opts)
+	if err != nil {
+		logger.Errorf("[CloneContainer] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, container.Cid)
+}
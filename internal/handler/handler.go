@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -66,3 +67,25 @@ func getQueryInt64Default(c echo.Context, key string, defaultValue int64) int64
 
 	return intValue
 }
+
+// getQueryBool 从查询参数获取布尔值（解析失败视为 false）
+func getQueryBool(c echo.Context, key string) bool {
+	value, err := strconv.ParseBool(c.QueryParam(key))
+	if err != nil {
+		return false
+	}
+	return value
+}
+
+// getQueryIntList 从查询参数获取逗号分隔的整数列表（忽略无法解析的项）
+func getQueryIntList(c echo.Context, key string) []int {
+	var result []int
+	for _, item := range strings.Split(c.QueryParam(key), ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		result = append(result, value)
+	}
+	return result
+}
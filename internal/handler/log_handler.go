@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"mime"
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 
+	"clock/internal/domain"
 	"clock/internal/logger"
+	"clock/internal/middleware"
 	"clock/internal/repository"
 	"clock/internal/service"
 )
@@ -29,8 +34,10 @@ func (h *LogHandler) GetLogs(c echo.Context) error {
 			LeftTs:  getQueryInt64Default(c, "left_ts", 0),
 			RightTs: getQueryInt64Default(c, "right_ts", 0),
 		},
-		Tid: getQueryIntDefault(c, "tid", 0),
-		Cid: getQueryIntDefault(c, "cid", 0),
+		Tid:    getQueryIntDefault(c, "tid", 0),
+		Cid:    getQueryIntDefault(c, "cid", 0),
+		Cids:   middleware.VisibleCids(c),
+		Search: c.QueryParam("search"),
 	}
 
 	result, err := h.taskLogService.List(query)
@@ -85,3 +92,106 @@ func (h *LogHandler) DeleteAllLogs(c echo.Context) error {
 
 	return OK(c, nil)
 }
+
+// GetLogOutput 流式读取日志输出（stream: stdout, stderr）
+func (h *LogHandler) GetLogOutput(c echo.Context) error {
+	lid := c.Param("lid")
+	if lid == "" {
+		return BadRequest(c, "lid is required")
+	}
+	stream := c.QueryParam("stream")
+	if stream == "" {
+		stream = domain.LogStreamStdout
+	}
+
+	rc, err := h.taskLogService.OpenOutput(lid, stream)
+	if err != nil {
+		logger.Errorf("[GetLogOutput] failed: %v", err)
+		return HandleError(c, err)
+	}
+	defer rc.Close()
+
+	return c.Stream(http.StatusOK, "text/plain; charset=utf-8", rc)
+}
+
+// DownloadLog 下载单条日志的纯文本输出
+func (h *LogHandler) DownloadLog(c echo.Context) error {
+	lid := c.Param("lid")
+	if lid == "" {
+		return BadRequest(c, "lid is required")
+	}
+	return h.download(c, &service.LogDownloadOptions{Lid: lid})
+}
+
+// DownloadRunLog 下载批次内所有任务的纯文本输出
+func (h *LogHandler) DownloadRunLog(c echo.Context) error {
+	runID := c.Param("runid")
+	if runID == "" {
+		return BadRequest(c, "run_id is required")
+	}
+	return h.download(c, &service.LogDownloadOptions{RunID: runID})
+}
+
+// download 输出日志下载内容（stream: stdout, stderr, all；gzip=true 压缩下载），支持 Range 请求
+func (h *LogHandler) download(c echo.Context, opts *service.LogDownloadOptions) error {
+	opts.Stream = c.QueryParam("stream")
+	opts.Gzip = c.QueryParam("gzip") == "true" || c.QueryParam("gzip") == "1"
+
+	download, err := h.taskLogService.Download(opts)
+	if err != nil {
+		logger.Errorf("[DownloadLog] failed: %v", err)
+		return HandleError(c, err)
+	}
+	defer download.Content.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, download.ContentType)
+	res.Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": download.Name}))
+	http.ServeContent(res, c.Request(), download.Name, download.ModTime, download.Content)
+	return nil
+}
+
+// GetLogLines 查询日志行记录（stream 过滤输出流，from/to 指定行号范围，limit 限制行数）
+func (h *LogHandler) GetLogLines(c echo.Context) error {
+	lid := c.Param("lid")
+	if lid == "" {
+		return BadRequest(c, "lid is required")
+	}
+
+	query := &repository.LogLineQuery{
+		Stream: c.QueryParam("stream"),
+		From:   getQueryInt64Default(c, "from", 1),
+		To:     getQueryInt64Default(c, "to", 0),
+		Limit:  getQueryIntDefault(c, "limit", 0),
+	}
+
+	result, err := h.taskLogService.Lines(lid, query)
+	if err != nil {
+		logger.Errorf("[GetLogLines] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, result)
+}
+
+// MigrateLogs 将数据库中的历史输出迁移到当前存储后端
+func (h *LogHandler) MigrateLogs(c echo.Context) error {
+	migrated, err := h.taskLogService.Migrate()
+	if err != nil {
+		logger.Errorf("[MigrateLogs] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, map[string]int{"migrated": migrated})
+}
+
+// ReindexLogs 为尚未建立全文索引的日志建立索引
+func (h *LogHandler) ReindexLogs(c echo.Context) error {
+	indexed, err := h.taskLogService.Reindex()
+	if err != nil {
+		logger.Errorf("[ReindexLogs] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, map[string]int{"indexed": indexed})
+}
@@ -4,27 +4,149 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"clock/internal/logger"
+	"clock/internal/middleware"
 	"clock/internal/service"
 )
 
 // MessageHandler 消息处理器
 type MessageHandler struct {
 	messageService service.MessageService
+	journal        service.EventJournal
 }
 
 // NewMessageHandler 创建消息处理器
-func NewMessageHandler(messageService service.MessageService) *MessageHandler {
+func NewMessageHandler(messageService service.MessageService, journal service.EventJournal) *MessageHandler {
 	return &MessageHandler{
 		messageService: messageService,
+		journal:        journal,
 	}
 }
 
 // GetTaskStatus SSE 推送任务状态（结构化事件流）
+//
+// 支持过滤参数：cid、tid、runId、kinds（逗号分隔的事件类型），过滤在服务端完成。
+// 断线重连时根据 Last-Event-ID 回放错过的事件。
 func (h *MessageHandler) GetTaskStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+	events := h.messageService.Subscribe(ctx, parseStreamFilter(c), lastEventID(c))
+	return writeEvents(c, events)
+}
+
+// FollowRun SSE 跟随单个批次（runId）或单个任务（tid）的事件，结束后发送 end 事件并关闭
+//
+// 未收到结束事件就关闭时不发送 end，客户端按 Last-Event-ID 重连。
+func (h *MessageHandler) FollowRun(c echo.Context) error {
+	stream, err := h.messageService.Follow(c.Request().Context(), parseStreamFilter(c), lastEventID(c))
+	if err != nil {
+		return HandleError(c, err)
+	}
+	if err := writeEvents(c, stream.Events); err != nil {
+		return err
+	}
+	if stream.Ended() {
+		writeEnd(c)
+	}
+	return nil
+}
+
+// GetRunEvents 获取批次的事件日志
+func (h *MessageHandler) GetRunEvents(c echo.Context) error {
+	runID := c.Param("runid")
+	if runID == "" {
+		return BadRequest(c, "run_id is required")
+	}
+
+	events, err := h.journal.Events(runID)
+	if err != nil {
+		logger.Errorf("[GetRunEvents] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, events)
+}
+
+// ReplayRun SSE 按原始顺序回放已结束批次的事件（speed 为速度倍数，0 或不传时立即输出全部事件）
+func (h *MessageHandler) ReplayRun(c echo.Context) error {
+	runID := c.Param("runid")
+	if runID == "" {
+		return BadRequest(c, "run_id is required")
+	}
+
+	speed := 0.0
+	if value := c.QueryParam("speed"); value != "" {
+		var err error
+		if speed, err = strconv.ParseFloat(value, 64); err != nil {
+			return BadRequest(c, "invalid speed")
+		}
+	}
+
+	events, err := h.journal.Replay(c.Request().Context(), runID, &service.ReplayOptions{
+		Speed:  speed,
+		Filter: parseStreamFilter(c),
+		LastID: lastEventID(c),
+	})
+	if err != nil {
+		return HandleError(c, err)
+	}
+	return writeFiniteEvents(c, events)
+}
+
+// writeFiniteEvents 输出有限的事件流，结束后发送 end 事件通知客户端关闭，避免 EventSource 自动重连
+func writeFiniteEvents(c echo.Context, events <-chan service.StreamEvent) error {
+	if err := writeEvents(c, events); err != nil {
+		return err
+	}
+	writeEnd(c)
+	return nil
+}
+
+// writeEnd 发送 end 事件通知客户端关闭，客户端已断开时不发送
+func writeEnd(c echo.Context) {
+	if c.Request().Context().Err() != nil {
+		return
+	}
+	_, _ = fmt.Fprint(c.Response().Writer, "event: end\ndata: {}\n\n")
+	c.Response().Flush()
+}
+
+// parseStreamFilter 解析事件流过滤参数，只推送当前用户可查看的容器的事件
+func parseStreamFilter(c echo.Context) *service.StreamFilter {
+	filter := &service.StreamFilter{
+		Cid:   getQueryIntDefault(c, "cid", 0),
+		Cids:  middleware.VisibleCids(c),
+		Tid:   getQueryIntDefault(c, "tid", 0),
+		RunID: c.QueryParam("runId"),
+	}
+	for _, kind := range strings.Split(c.QueryParam("kinds"), ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			filter.Kinds = append(filter.Kinds, kind)
+		}
+	}
+	return filter
+}
+
+// lastEventID 读取客户端最后收到的事件 ID（Last-Event-ID 请求头，或 lastEventId 查询参数）
+func lastEventID(c echo.Context) int64 {
+	value := c.Request().Header.Get("Last-Event-ID")
+	if value == "" {
+		value = c.QueryParam("lastEventId")
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// writeEvents 以 SSE 格式输出事件，直到客户端断开或事件流关闭
+func writeEvents(c echo.Context, events <-chan service.StreamEvent) error {
 	res := c.Response()
 	res.Header().Set(echo.HeaderContentType, "text/event-stream")
 	res.Header().Set(echo.HeaderCacheControl, "no-cache")
@@ -38,7 +160,6 @@ func (h *MessageHandler) GetTaskStatus(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
-	events := h.messageService.Subscribe(ctx)
 
 	// initial comment to ensure the stream is established
 	_, _ = fmt.Fprint(res.Writer, ": ok\n\n")
@@ -72,8 +193,8 @@ func (h *MessageHandler) GetTaskStatus(c echo.Context) error {
 	}
 }
 
-// GetMessages 获取任务统计
+// GetMessages 获取任务统计（只统计可查看的容器）
 func (h *MessageHandler) GetMessages(c echo.Context) error {
-	counters := h.messageService.GetCounters()
+	counters := h.messageService.GetCounters(middleware.VisibleCids(c))
 	return OK(c, counters)
 }
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/service"
+)
+
+// NotifyHandler 通知处理器
+type NotifyHandler struct {
+	notifyService service.NotifyService
+}
+
+// NewNotifyHandler 创建通知处理器
+func NewNotifyHandler(notifyService service.NotifyService) *NotifyHandler {
+	return &NotifyHandler{
+		notifyService: notifyService,
+	}
+}
+
+// GetChannels 获取已配置的通知通道
+func (h *NotifyHandler) GetChannels(c echo.Context) error {
+	return OK(c, h.notifyService.Channels())
+}
+
+// GetRules 获取容器或任务的通知规则
+func (h *NotifyHandler) GetRules(c echo.Context) error {
+	cid := getQueryIntDefault(c, "cid", 0)
+	tid := getQueryIntDefault(c, "tid", 0)
+
+	rules, err := h.notifyService.ListRules(cid, tid)
+	if err != nil {
+		logger.Errorf("[GetRules] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, rules)
+}
+
+// PutRule 创建或更新通知规则
+func (h *NotifyHandler) PutRule(c echo.Context) error {
+	var rule domain.NotifyRule
+	if err := c.Bind(&rule); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	if err := h.notifyService.SaveRule(&rule); err != nil {
+		logger.Errorf("[PutRule] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, rule.ID)
+}
+
+// DeleteRule 删除通知规则
+func (h *NotifyHandler) DeleteRule(c echo.Context) error {
+	id, err := getPathInt(c, "id")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.notifyService.DeleteRule(id); err != nil {
+		logger.Errorf("[DeleteRule] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// TestChannel 通过指定通道发送测试消息
+func (h *NotifyHandler) TestChannel(c echo.Context) error {
+	channel := c.QueryParam("channel")
+	if channel == "" {
+		return BadRequest(c, "channel is required")
+	}
+
+	if err := h.notifyService.Test(c.Request().Context(), channel); err != nil {
+		logger.Errorf("[TestChannel] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// PreviewTemplate 使用历史执行记录渲染消息模板
+func (h *NotifyHandler) PreviewTemplate(c echo.Context) error {
+	var req service.NotifyPreviewRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	preview, err := h.notifyService.Preview(&req)
+	if err != nil {
+		logger.Errorf("[PreviewTemplate] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, preview)
+}
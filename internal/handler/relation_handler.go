@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 
 	"clock/internal/domain"
@@ -27,7 +29,16 @@ func (h *RelationHandler) GetRelations(c echo.Context) error {
 		return BadRequest(c, err.Error())
 	}
 
-	graph, err := h.relationService.GetGraph(cid)
+	opts := &service.GraphOptions{
+		Stats:  getQueryBool(c, "stats"),
+		Runs:   getQueryIntDefault(c, "runs", 0),
+		Window: getQueryIntDefault(c, "window", 0),
+
+		Collapse: getQueryIntList(c, "collapse"),
+		Expand:   getQueryIntList(c, "expand"),
+	}
+
+	graph, err := h.relationService.GetGraph(cid, opts)
 	if err != nil {
 		logger.Errorf("[GetRelations] failed: %v", err)
 		return HandleError(c, err)
@@ -36,6 +47,39 @@ func (h *RelationHandler) GetRelations(c echo.Context) error {
 	return OK(c, graph)
 }
 
+// ExportRelations 导出关系图（dot, mermaid, svg）
+func (h *RelationHandler) ExportRelations(c echo.Context) error {
+	cid, err := getQueryInt(c, "cid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	opts := &service.GraphExportOptions{
+		Format:       c.QueryParam("format"),
+		WithStatus:   c.QueryParam("status") == "true",
+		WithDuration: c.QueryParam("duration") == "true",
+	}
+	if opts.Format == "" {
+		opts.Format = domain.GraphFormatDOT
+	}
+
+	data, err := h.relationService.ExportGraph(cid, opts)
+	if err != nil {
+		logger.Errorf("[ExportRelations] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	switch opts.Format {
+	case domain.GraphFormatDOT:
+		contentType = "text/vnd.graphviz; charset=utf-8"
+	case domain.GraphFormatSVG:
+		contentType = "image/svg+xml"
+	}
+
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
 // AddRelation 添加关系
 func (h *RelationHandler) AddRelation(c echo.Context) error {
 	var relation domain.Relation
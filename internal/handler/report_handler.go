@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/logger"
+	"clock/internal/service"
+)
+
+// ReportHandler 运行汇总报表处理器
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+// NewReportHandler 创建运行汇总报表处理器
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// GetReport 生成日期范围内的报表，format 为 json（默认）、text 或 html
+func (h *ReportHandler) GetReport(c echo.Context) error {
+	report, err := h.generate(c)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	format := c.QueryParam("format")
+	if format == "" || format == service.ReportFormatJSON {
+		return OK(c, report)
+	}
+	content, err := h.reportService.Render(report, format)
+	if err != nil {
+		logger.Errorf("[GetReport] failed: %v", err)
+		return HandleError(c, err)
+	}
+	if format == service.ReportFormatHTML {
+		return c.HTML(http.StatusOK, content)
+	}
+	return c.String(http.StatusOK, content)
+}
+
+// SendReport 生成日期范围内的报表并通过通知通道发送，channel 为空时使用配置的通道
+func (h *ReportHandler) SendReport(c echo.Context) error {
+	report, err := h.generate(c)
+	if err != nil {
+		return HandleError(c, err)
+	}
+
+	var channels []string
+	if channel := c.QueryParam("channel"); channel != "" {
+		channels = strings.Split(channel, ",")
+	}
+	if err := h.reportService.Send(c.Request().Context(), report, channels); err != nil {
+		logger.Errorf("[SendReport] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// generate 按 from、to（含当天，格式 2006-01-02）生成报表
+func (h *ReportHandler) generate(c echo.Context) (*service.Report, error) {
+	from, to, err := service.ParseReportRange(c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return nil, err
+	}
+	report, err := h.reportService.Generate(from, to)
+	if err != nil {
+		logger.Errorf("[GenerateReport] failed: %v", err)
+		return nil, err
+	}
+	return report, nil
+}
@@ -50,6 +50,14 @@ func HandleError(c echo.Context, err error) error {
 		switch appErr.Code {
 		case apperrors.ErrNotFound:
 			return c.JSON(http.StatusNotFound, ErrorWithCode(int(appErr.Code), appErr.Message))
+		case apperrors.ErrInvalidParam:
+			return c.JSON(http.StatusBadRequest, ErrorWithCode(int(appErr.Code), appErr.Message))
+		case apperrors.ErrUnauthorized:
+			return c.JSON(http.StatusUnauthorized, ErrorWithCode(int(appErr.Code), appErr.Message))
+		case apperrors.ErrForbidden:
+			return c.JSON(http.StatusForbidden, ErrorWithCode(int(appErr.Code), appErr.Message))
+		case apperrors.ErrNotify:
+			return c.JSON(http.StatusBadGateway, ErrorWithCode(int(appErr.Code), appErr.Error()))
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorWithCode(int(appErr.Code), appErr.Error()))
 		}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/repository"
+	"clock/internal/service"
+)
+
+// RoleHandler 角色授权处理器
+type RoleHandler struct {
+	accessService service.AccessService
+}
+
+// NewRoleHandler 创建角色授权处理器
+func NewRoleHandler(accessService service.AccessService) *RoleHandler {
+	return &RoleHandler{
+		accessService: accessService,
+	}
+}
+
+// GetRoles 查询授权（user_id 指定用户，cid 指定容器，cid=0 为全局授权）
+func (h *RoleHandler) GetRoles(c echo.Context) error {
+	query := &repository.RoleBindingQuery{
+		UserID: getQueryIntDefault(c, "user_id", 0),
+	}
+	if c.QueryParam("cid") != "" {
+		cid, err := getQueryInt(c, "cid")
+		if err != nil {
+			return BadRequest(c, err.Error())
+		}
+		query.Cid = &cid
+	}
+
+	bindings, err := h.accessService.ListBindings(query)
+	if err != nil {
+		logger.Errorf("[GetRoles] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, bindings)
+}
+
+// PutRole 授予用户角色，用户在同一容器上已有授权时替换角色
+func (h *RoleHandler) PutRole(c echo.Context) error {
+	var binding domain.RoleBinding
+	if err := c.Bind(&binding); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	result, err := h.accessService.Grant(&binding)
+	if err != nil {
+		logger.Errorf("[PutRole] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, result)
+}
+
+// DeleteRole 撤销授权
+func (h *RoleHandler) DeleteRole(c echo.Context) error {
+	id, err := getPathInt(c, "id")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.accessService.Revoke(id); err != nil {
+		logger.Errorf("[DeleteRole] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
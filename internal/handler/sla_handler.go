@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/logger"
+	"clock/internal/middleware"
+	"clock/internal/repository"
+	"clock/internal/service"
+)
+
+// SlaHandler SLA 处理器
+type SlaHandler struct {
+	slaMonitor service.SlaMonitor
+}
+
+// NewSlaHandler 创建 SLA 处理器
+func NewSlaHandler(slaMonitor service.SlaMonitor) *SlaHandler {
+	return &SlaHandler{
+		slaMonitor: slaMonitor,
+	}
+}
+
+// GetMisses 查询 SLA 未达成记录
+func (h *SlaHandler) GetMisses(c echo.Context) error {
+	query := &repository.SlaMissQuery{
+		Page: repository.Page{
+			Count: getQueryIntDefault(c, "count", 10),
+			Index: getQueryIntDefault(c, "index", 1),
+		},
+		Cid:  getQueryIntDefault(c, "cid", 0),
+		Cids: middleware.VisibleCids(c),
+		Tid:  getQueryIntDefault(c, "tid", 0),
+	}
+
+	result, err := h.slaMonitor.Misses(query)
+	if err != nil {
+		logger.Errorf("[GetMisses] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, result)
+}
@@ -5,6 +5,7 @@ import (
 
 	"clock/internal/domain"
 	"clock/internal/logger"
+	"clock/internal/middleware"
 	"clock/internal/repository"
 	"clock/internal/service"
 )
@@ -29,8 +30,10 @@ func (h *TaskHandler) GetTasks(c echo.Context) error {
 			Index: getQueryIntDefault(c, "index", 1),
 			Order: c.QueryParam("order"),
 		},
-		Cid:  getQueryIntDefault(c, "cid", 0),
-		Name: c.QueryParam("name"),
+		Cid:     getQueryIntDefault(c, "cid", 0),
+		Cids:    middleware.VisibleCids(c),
+		Name:    c.QueryParam("name"),
+		Trashed: getQueryBool(c, "trashed"),
 	}
 
 	result, err := h.taskService.List(query)
@@ -80,7 +83,12 @@ func (h *TaskHandler) DeleteTask(c echo.Context) error {
 		return BadRequest(c, err.Error())
 	}
 
-	if err := h.taskService.Delete(tid); err != nil {
+	opts := &service.DeleteOptions{
+		Purge:       getQueryBool(c, "purge"),
+		CascadeLogs: getQueryBool(c, "logs"),
+	}
+
+	if err := h.taskService.Delete(tid, opts); err != nil {
 		logger.Errorf("[DeleteTask] failed: %v", err)
 		return HandleError(c, err)
 	}
@@ -88,6 +96,21 @@ func (h *TaskHandler) DeleteTask(c echo.Context) error {
 	return OK(c, nil)
 }
 
+// RestoreTask 从回收站恢复任务
+func (h *TaskHandler) RestoreTask(c echo.Context) error {
+	tid, err := getPathInt(c, "tid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.taskService.Restore(tid); err != nil {
+		logger.Errorf("[RestoreTask] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
 // RunTask 执行任务
 func (h *TaskHandler) RunTask(c echo.Context) error {
 	tid, err := getQueryInt(c, "tid")
@@ -160,8 +183,14 @@ func (h *TaskHandler) CancelRun(c echo.Context) error {
 	return OK(c, nil)
 }
 
-// GetRunningTasks 获取运行中的任务列表
+// GetRunningTasks 获取运行中的任务列表（只返回可查看的容器中的任务）
 func (h *TaskHandler) GetRunningTasks(c echo.Context) error {
-	tasks := h.taskService.GetRunningTasks()
+	perms := middleware.GetPermissions(c)
+	tasks := make([]service.RunningTaskInfo, 0)
+	for _, task := range h.taskService.GetRunningTasks() {
+		if perms != nil && perms.Can(task.Cid, domain.RoleViewer) {
+			tasks = append(tasks, task)
+		}
+	}
 	return OK(c, tasks)
 }
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/service"
+)
+
+// TaskGroupHandler 任务组处理器
+type TaskGroupHandler struct {
+	groupService service.TaskGroupService
+}
+
+// NewTaskGroupHandler 创建任务组处理器
+func NewTaskGroupHandler(groupService service.TaskGroupService) *TaskGroupHandler {
+	return &TaskGroupHandler{
+		groupService: groupService,
+	}
+}
+
+// GetGroups 获取容器内的任务组
+func (h *TaskGroupHandler) GetGroups(c echo.Context) error {
+	cid, err := getQueryInt(c, "cid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	groups, err := h.groupService.List(cid)
+	if err != nil {
+		logger.Errorf("[GetGroups] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, groups)
+}
+
+// PutGroup 创建或更新任务组
+func (h *TaskGroupHandler) PutGroup(c echo.Context) error {
+	var group domain.TaskGroup
+	if err := c.Bind(&group); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	if err := h.groupService.Save(&group); err != nil {
+		logger.Errorf("[PutGroup] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, group.Gid)
+}
+
+// DeleteGroup 删除任务组
+func (h *TaskGroupHandler) DeleteGroup(c echo.Context) error {
+	gid, err := getPathInt(c, "gid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.groupService.Delete(gid); err != nil {
+		logger.Errorf("[DeleteGroup] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// CancelGroup 取消任务组，正在运行和尚未执行的成员都不再执行
+func (h *TaskGroupHandler) CancelGroup(c echo.Context) error {
+	gid, err := getPathInt(c, "gid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.groupService.Cancel(gid); err != nil {
+		logger.Errorf("[CancelGroup] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// RetryGroup 重新执行任务组
+func (h *TaskGroupHandler) RetryGroup(c echo.Context) error {
+	gid, err := getPathInt(c, "gid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.groupService.Retry(gid); err != nil {
+		logger.Errorf("[RetryGroup] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// ClearGroup 重置任务组内任务状态
+func (h *TaskGroupHandler) ClearGroup(c echo.Context) error {
+	gid, err := getPathInt(c, "gid")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.groupService.Clear(gid); err != nil {
+		logger.Errorf("[ClearGroup] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
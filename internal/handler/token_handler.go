@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/middleware"
+	"clock/internal/service"
+)
+
+// TokenHandler API 令牌处理器
+type TokenHandler struct {
+	tokenService service.APITokenService
+}
+
+// NewTokenHandler 创建 API 令牌处理器
+func NewTokenHandler(tokenService service.APITokenService) *TokenHandler {
+	return &TokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// GetTokens 查询当前用户的令牌，全局 admin 可通过 user_id 查询其他用户的令牌
+func (h *TokenHandler) GetTokens(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return Unauthorized(c, "not logged in")
+	}
+	userID := getQueryIntDefault(c, "user_id", user.ID)
+	if userID != user.ID && !isGlobalAdmin(c) {
+		return HandleError(c, apperrors.Forbidden("admin role required"))
+	}
+
+	tokens, err := h.tokenService.List(userID)
+	if err != nil {
+		logger.Errorf("[GetTokens] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, tokens)
+}
+
+// PutToken 为当前用户创建令牌，令牌只在创建时返回一次
+func (h *TokenHandler) PutToken(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return Unauthorized(c, "not logged in")
+	}
+	var req service.APITokenRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	result, err := h.tokenService.Create(user.ID, &req)
+	if err != nil {
+		logger.Errorf("[PutToken] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, result)
+}
+
+// DeleteToken 撤销令牌，只能撤销自己的令牌，全局 admin 可撤销任意令牌
+func (h *TokenHandler) DeleteToken(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return Unauthorized(c, "not logged in")
+	}
+	id, err := getPathInt(c, "id")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	token, err := h.tokenService.Get(id)
+	if err != nil {
+		return HandleError(c, err)
+	}
+	if token.UserID != user.ID && !isGlobalAdmin(c) {
+		return HandleError(c, apperrors.Forbidden("admin role required"))
+	}
+
+	if err := h.tokenService.Revoke(id); err != nil {
+		logger.Errorf("[DeleteToken] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// isGlobalAdmin 判断当前用户是否为全局 admin
+func isGlobalAdmin(c echo.Context) bool {
+	perms := middleware.GetPermissions(c)
+	return perms != nil && perms.Can(0, domain.RoleAdmin)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/middleware"
+	"clock/internal/service"
+)
+
+// MeResponse 当前用户信息
+type MeResponse struct {
+	*domain.User
+	Permissions *domain.Permissions `json:"permissions"` // 生效的权限
+}
+
+// UserHandler 用户处理器
+type UserHandler struct {
+	userService service.UserService
+}
+
+// NewUserHandler 创建用户处理器
+func NewUserHandler(userService service.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// GetUsers 获取全部用户
+func (h *UserHandler) GetUsers(c echo.Context) error {
+	users, err := h.userService.List()
+	if err != nil {
+		logger.Errorf("[GetUsers] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, users)
+}
+
+// PutUser 创建用户
+func (h *UserHandler) PutUser(c echo.Context) error {
+	var req service.UserRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	user, err := h.userService.Create(&req)
+	if err != nil {
+		logger.Errorf("[PutUser] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, user)
+}
+
+// DisableUser 禁用用户
+func (h *UserHandler) DisableUser(c echo.Context) error {
+	return h.setDisable(c, true)
+}
+
+// EnableUser 启用用户
+func (h *UserHandler) EnableUser(c echo.Context) error {
+	return h.setDisable(c, false)
+}
+
+func (h *UserHandler) setDisable(c echo.Context, disable bool) error {
+	id, err := getPathInt(c, "id")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+	if current := middleware.GetUser(c); current != nil && current.ID == id && disable {
+		return BadRequest(c, "cannot disable yourself")
+	}
+
+	if err := h.userService.SetDisable(id, disable); err != nil {
+		logger.Errorf("[SetUserDisable] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// ResetPassword 重置用户密码
+func (h *UserHandler) ResetPassword(c echo.Context) error {
+	id, err := getPathInt(c, "id")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+	var req service.PasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	if err := h.userService.ResetPassword(id, req.Password); err != nil {
+		logger.Errorf("[ResetPassword] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// GetMe 获取当前用户及其权限
+func (h *UserHandler) GetMe(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return Unauthorized(c, "not logged in")
+	}
+
+	return OK(c, &MeResponse{User: user, Permissions: middleware.GetPermissions(c)})
+}
+
+// ChangePassword 修改当前用户的密码
+func (h *UserHandler) ChangePassword(c echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return Unauthorized(c, "not logged in")
+	}
+	var req service.PasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	if err := h.userService.ChangePassword(user.ID, &req); err != nil {
+		logger.Errorf("[ChangePassword] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/repository"
+	"clock/internal/service"
+)
+
+// WebhookHandler webhook 订阅处理器
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler 创建 webhook 订阅处理器
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// GetWebhooks 获取全部订阅
+func (h *WebhookHandler) GetWebhooks(c echo.Context) error {
+	webhooks, err := h.webhookService.List()
+	if err != nil {
+		logger.Errorf("[GetWebhooks] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, webhooks)
+}
+
+// PutWebhook 创建或更新订阅
+func (h *WebhookHandler) PutWebhook(c echo.Context) error {
+	var webhook domain.Webhook
+	if err := c.Bind(&webhook); err != nil {
+		return BadRequest(c, "invalid request body")
+	}
+
+	if err := h.webhookService.Save(&webhook); err != nil {
+		logger.Errorf("[PutWebhook] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, webhook.ID)
+}
+
+// DeleteWebhook 删除订阅
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id, err := getPathInt(c, "id")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	if err := h.webhookService.Delete(id); err != nil {
+		logger.Errorf("[DeleteWebhook] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, nil)
+}
+
+// PingWebhook 向订阅投递测试事件
+func (h *WebhookHandler) PingWebhook(c echo.Context) error {
+	id, err := getPathInt(c, "id")
+	if err != nil {
+		return BadRequest(c, err.Error())
+	}
+
+	delivery, err := h.webhookService.Ping(id)
+	if err != nil {
+		logger.Errorf("[PingWebhook] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, delivery)
+}
+
+// GetDeliveries 查询投递记录
+func (h *WebhookHandler) GetDeliveries(c echo.Context) error {
+	query := &repository.DeliveryQuery{
+		Page: repository.Page{
+			Count: getQueryIntDefault(c, "count", 10),
+			Index: getQueryIntDefault(c, "index", 1),
+		},
+		WebhookID: getQueryIntDefault(c, "webhook_id", 0),
+		Status:    c.QueryParam("status"),
+	}
+
+	result, err := h.webhookService.Deliveries(query)
+	if err != nil {
+		logger.Errorf("[GetDeliveries] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, result)
+}
+
+// GetDelivery 获取投递记录详情
+func (h *WebhookHandler) GetDelivery(c echo.Context) error {
+	delivery, err := h.webhookService.GetDelivery(c.Param("did"))
+	if err != nil {
+		logger.Errorf("[GetDelivery] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, delivery)
+}
+
+// Redeliver 重新投递
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	delivery, err := h.webhookService.Redeliver(c.Param("did"))
+	if err != nil {
+		logger.Errorf("[Redeliver] failed: %v", err)
+		return HandleError(c, err)
+	}
+
+	return OK(c, delivery)
+}
@@ -0,0 +1,404 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/service"
+)
+
+// permissionsContextKey 当前用户权限在请求上下文中的键
+const permissionsContextKey = "permissions"
+
+// logsContextKey 标记请求访问日志的键
+const logsContextKey = "access_logs"
+
+// Scope 解析请求操作涉及的容器，返回空列表时要求全局角色
+type Scope func(c echo.Context) ([]int, error)
+
+// Access 基于角色的权限检查
+//
+// Load 在 CurrentUser 之后加载用户权限，每个路由再通过 Global、Any 或
+// Container 声明所需的角色。使用 API 令牌时，角色还要对应令牌的权限范围：
+// viewer 对应 read，operator 对应 run，editor 对应 write，admin 对应 admin。
+type Access struct {
+	access service.AccessService
+}
+
+// NewAccess 创建权限检查
+func NewAccess(access service.AccessService) *Access {
+	return &Access{access: access}
+}
+
+// Load 加载当前用户的权限
+func (a *Access) Load() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := GetUser(c)
+			if user == nil {
+				// 无需认证的接口
+				return next(c)
+			}
+			perms, err := a.access.Permissions(user.ID)
+			if err != nil {
+				return err
+			}
+			c.Set(permissionsContextKey, perms)
+			return next(c)
+		}
+	}
+}
+
+// Logs 标记路由访问日志，使用 API 令牌时要求日志的权限范围
+func (a *Access) Logs() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(logsContextKey, true)
+			return next(c)
+		}
+	}
+}
+
+// Session 要求使用 JWT 登录，拒绝 API 令牌访问
+func (a *Access) Session() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if GetAPIToken(c) != nil {
+				return errorJSON(c, http.StatusForbidden, apperrors.ErrForbidden, "not allowed with api token")
+			}
+			return next(c)
+		}
+	}
+}
+
+// Global 要求全局角色
+func (a *Access) Global(role domain.Role) echo.MiddlewareFunc {
+	return a.Container(role, nil)
+}
+
+// Any 要求在全局或任一容器具有角色，列表接口再按可见容器过滤结果
+func (a *Access) Any(role domain.Role) echo.MiddlewareFunc {
+	return a.require(role, func(c echo.Context, perms *domain.Permissions) (bool, error) {
+		return perms.CanAny(role), nil
+	})
+}
+
+// Container 要求在 scope 解析出的全部容器上具有角色，scope 为 nil 或未解析出容器时要求全局角色
+func (a *Access) Container(role domain.Role, scope Scope) echo.MiddlewareFunc {
+	return a.require(role, func(c echo.Context, perms *domain.Permissions) (bool, error) {
+		var cids []int
+		if scope != nil {
+			var err error
+			if cids, err = scope(c); err != nil {
+				return false, err
+			}
+		}
+		if len(cids) == 0 {
+			return perms.Can(0, role), nil
+		}
+		for _, cid := range cids {
+			if !perms.Can(cid, role) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// require 检查当前用户的权限，使用 API 令牌时还要求令牌具有角色对应的权限范围
+func (a *Access) require(role domain.Role, check func(c echo.Context, perms *domain.Permissions) (bool, error)) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			perms := GetPermissions(c)
+			if perms == nil {
+				return errorJSON(c, http.StatusUnauthorized, apperrors.ErrUnauthorized, "not logged in")
+			}
+			if token := GetAPIToken(c); token != nil {
+				logs, _ := c.Get(logsContextKey).(bool)
+				scope := requiredScope(role, logs)
+				if !token.HasScope(scope) && !token.HasScope(domain.ScopeAdmin) {
+					return errorJSON(c, http.StatusForbidden, apperrors.ErrForbidden, "token scope "+scope+" required")
+				}
+			}
+			ok, err := check(c, perms)
+			if err != nil {
+				var appErr *apperrors.AppError
+				if errors.As(err, &appErr) {
+					switch appErr.Code {
+					case apperrors.ErrNotFound:
+						return errorJSON(c, http.StatusNotFound, appErr.Code, appErr.Message)
+					case apperrors.ErrInvalidParam:
+						return errorJSON(c, http.StatusBadRequest, appErr.Code, appErr.Message)
+					}
+				}
+				return err
+			}
+			if !ok {
+				return errorJSON(c, http.StatusForbidden, apperrors.ErrForbidden, string(role)+" role required")
+			}
+			return next(c)
+		}
+	}
+}
+
+// requiredScope 返回角色对应的 API 令牌权限范围
+func requiredScope(role domain.Role, logs bool) string {
+	switch role {
+	case domain.RoleViewer:
+		if logs {
+			return domain.ScopeReadLogs
+		}
+		return domain.ScopeReadContainers
+	case domain.RoleOperator:
+		return domain.ScopeRunContainer
+	case domain.RoleEditor:
+		if logs {
+			return domain.ScopeWriteLogs
+		}
+		return domain.ScopeWriteContainers
+	default:
+		return domain.ScopeAdmin
+	}
+}
+
+// GetPermissions 获取当前请求用户的权限，未认证时返回 nil
+func GetPermissions(c echo.Context) *domain.Permissions {
+	perms, _ := c.Get(permissionsContextKey).(*domain.Permissions)
+	return perms
+}
+
+// VisibleCids 返回当前用户可查看的容器ID，nil 表示不限
+func VisibleCids(c echo.Context) []int {
+	perms := GetPermissions(c)
+	if perms == nil {
+		return []int{}
+	}
+	return perms.VisibleCids()
+}
+
+// Cid 从路径参数或查询参数读取容器ID
+func (a *Access) Cid(name string) Scope {
+	return a.intScope(name, func(cid int) (int, error) { return cid, nil })
+}
+
+// Task 从路径参数或查询参数读取任务ID
+func (a *Access) Task(name string) Scope {
+	return a.intScope(name, a.access.TaskCid)
+}
+
+// Group 从路径参数读取任务组ID
+func (a *Access) Group(name string) Scope {
+	return a.intScope(name, a.access.GroupCid)
+}
+
+// Relation 从路径参数读取关系ID
+func (a *Access) Relation(name string) Scope {
+	return a.intScope(name, a.access.RelationCid)
+}
+
+// NotifyRule 从路径参数读取通知规则ID
+func (a *Access) NotifyRule(name string) Scope {
+	return a.intScope(name, a.access.NotifyRuleCid)
+}
+
+// RoleBinding 从路径参数读取授权ID
+func (a *Access) RoleBinding(name string) Scope {
+	return a.intScope(name, func(id int) (int, error) {
+		binding, err := a.access.GetBinding(id)
+		if err != nil {
+			return 0, err
+		}
+		return binding.Cid, nil
+	})
+}
+
+// Log 从路径参数读取日志ID
+func (a *Access) Log(name string) Scope {
+	return func(c echo.Context) ([]int, error) {
+		cid, err := a.access.LogCid(c.Param(name))
+		if err != nil {
+			return nil, err
+		}
+		return []int{cid}, nil
+	}
+}
+
+// Run 从路径参数读取批次ID
+func (a *Access) Run(name string) Scope {
+	return func(c echo.Context) ([]int, error) {
+		cid, err := a.access.RunCid(c.Param(name))
+		if err != nil {
+			return nil, err
+		}
+		return []int{cid}, nil
+	}
+}
+
+// Scopes 合并多个 Scope 解析出的容器
+func (a *Access) Scopes(scopes ...Scope) Scope {
+	return func(c echo.Context) ([]int, error) {
+		var cids []int
+		for _, scope := range scopes {
+			ids, err := scope(c)
+			if err != nil {
+				return nil, err
+			}
+			cids = append(cids, ids...)
+		}
+		return cids, nil
+	}
+}
+
+// intScope 读取整数参数（路径参数优先），未传或为 0 时不解析出容器
+func (a *Access) intScope(name string, resolve func(int) (int, error)) Scope {
+	return func(c echo.Context) ([]int, error) {
+		value := c.Param(name)
+		if value == "" {
+			value = c.QueryParam(name)
+		}
+		if value == "" {
+			return nil, nil
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, apperrors.InvalidParam("invalid " + name)
+		}
+		if id == 0 {
+			return nil, nil
+		}
+		cid, err := resolve(id)
+		if err != nil {
+			return nil, err
+		}
+		return []int{cid}, nil
+	}
+}
+
+// bodyRefs 请求体中引用的资源
+type bodyRefs struct {
+	ID        int    `json:"id"`
+	Cid       int    `json:"cid"`
+	Tid       int    `json:"tid"`
+	NextTid   int    `json:"next_tid"`
+	Gid       int    `json:"gid"`
+	NextGid   int    `json:"next_gid"`
+	ParentGid int    `json:"parent_gid"`
+	Rid       int    `json:"rid"`
+	RuleID    int    `json:"rule_id"`
+	RunID     string `json:"run_id"`
+	RunIDAlt  string `json:"runId"`
+}
+
+// Body 解析请求体中引用的容器、任务、任务组、关系、通知规则和批次
+//
+// 修改已有资源时同时检查资源原来所属的容器，避免将其他容器的资源移入自己的容器。
+func (a *Access) Body() Scope {
+	return a.body(nil)
+}
+
+// NotifyRuleBody 解析通知规则请求体，id 字段为通知规则ID
+func (a *Access) NotifyRuleBody() Scope {
+	return a.body(a.access.NotifyRuleCid)
+}
+
+// body 解析请求体引用的资源，idResolver 用于解析 id 字段指向的资源，为 nil 时忽略 id
+func (a *Access) body(idResolver func(int) (int, error)) Scope {
+	return func(c echo.Context) ([]int, error) {
+		var refs bodyRefs
+		if err := peekBody(c, &refs); err != nil {
+			return nil, err
+		}
+
+		var cids []int
+		if refs.Cid > 0 {
+			cids = append(cids, refs.Cid)
+		}
+		resolve := func(id int, resolver func(int) (int, error)) error {
+			if id <= 0 || resolver == nil {
+				return nil
+			}
+			cid, err := resolver(id)
+			if err != nil {
+				return err
+			}
+			cids = append(cids, cid)
+			return nil
+		}
+		for _, ref := range []struct {
+			id       int
+			resolver func(int) (int, error)
+		}{
+			{refs.ID, idResolver},
+			{refs.Tid, a.access.TaskCid},
+			{refs.NextTid, a.access.TaskCid},
+			{refs.Gid, a.access.GroupCid},
+			{refs.NextGid, a.access.GroupCid},
+			{refs.ParentGid, a.access.GroupCid},
+			{refs.Rid, a.access.RelationCid},
+			{refs.RuleID, a.access.NotifyRuleCid},
+		} {
+			if err := resolve(ref.id, ref.resolver); err != nil {
+				return nil, err
+			}
+		}
+		for _, runID := range []string{refs.RunID, refs.RunIDAlt} {
+			if runID == "" {
+				continue
+			}
+			cid, err := a.access.RunCid(runID)
+			if err != nil {
+				return nil, err
+			}
+			cids = append(cids, cid)
+		}
+		return cids, nil
+	}
+}
+
+// Nodes 解析请求体中节点列表（节点ID为任务ID）所属的容器
+func (a *Access) Nodes() Scope {
+	return func(c echo.Context) ([]int, error) {
+		var nodes []domain.Node
+		if err := peekBody(c, &nodes); err != nil {
+			return nil, err
+		}
+		var cids []int
+		for _, node := range nodes {
+			cid, err := a.access.TaskCid(node.ID)
+			if err != nil {
+				if apperrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			cids = append(cids, cid)
+		}
+		return cids, nil
+	}
+}
+
+// peekBody 按处理器相同的方式绑定请求体，并恢复请求体供处理器再次读取
+func peekBody(c echo.Context, v interface{}) error {
+	req := c.Request()
+	if req.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return apperrors.InvalidParam("invalid request body")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	err = (&echo.DefaultBinder{}).BindBody(c, v)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return apperrors.InvalidParam("invalid request body")
+	}
+	return nil
+}
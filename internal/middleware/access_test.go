@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/service"
+)
+
+// fakeAccess 按固定数据返回权限和资源所属容器
+type fakeAccess struct {
+	service.AccessService
+	perms *domain.Permissions
+	tasks map[int]int // 任务ID -> 容器ID
+}
+
+func (a *fakeAccess) Permissions(userID int) (*domain.Permissions, error) {
+	return a.perms, nil
+}
+
+func (a *fakeAccess) TaskCid(tid int) (int, error) {
+	cid, ok := a.tasks[tid]
+	if !ok {
+		return 0, apperrors.NotFound("task")
+	}
+	return cid, nil
+}
+
+// accessRequest 以指定权限和 API 令牌请求 route 声明的路由，返回状态码
+//
+// 处理器读取到的请求体与发送的不一致时返回 500，用于检查权限检查后请求体仍可读取。
+func accessRequest(t *testing.T, perms *domain.Permissions, token *domain.APIToken, method, target, body string, route func(a *Access) (string, []echo.MiddlewareFunc)) int {
+	t.Helper()
+	access := NewAccess(&fakeAccess{perms: perms, tasks: map[int]int{10: 1, 20: 2}})
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if perms != nil {
+				c.Set(userContextKey, &domain.User{ID: 1, Name: "u"})
+			}
+			if token != nil {
+				c.Set(apiTokenContextKey, token)
+			}
+			return next(c)
+		}
+	}, access.Load())
+	path, mws := route(access)
+	e.Add(method, path, func(c echo.Context) error {
+		if read, _ := io.ReadAll(c.Request().Body); string(read) != body {
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		return c.NoContent(http.StatusOK)
+	}, mws...)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestAccessRoles(t *testing.T) {
+	global := func(role domain.Role) *domain.Permissions {
+		return &domain.Permissions{Global: role, Containers: map[int]domain.Role{}}
+	}
+	container := func(cid int, role domain.Role) *domain.Permissions {
+		return &domain.Permissions{Containers: map[int]domain.Role{cid: role}}
+	}
+
+	globalRoute := func(role domain.Role) func(a *Access) (string, []echo.MiddlewareFunc) {
+		return func(a *Access) (string, []echo.MiddlewareFunc) {
+			return "/global", []echo.MiddlewareFunc{a.Global(role)}
+		}
+	}
+	anyRoute := func(a *Access) (string, []echo.MiddlewareFunc) {
+		return "/any", []echo.MiddlewareFunc{a.Any(domain.RoleViewer)}
+	}
+	cidRoute := func(a *Access) (string, []echo.MiddlewareFunc) {
+		return "/containers/:cid", []echo.MiddlewareFunc{a.Container(domain.RoleOperator, a.Cid("cid"))}
+	}
+	bodyRoute := func(a *Access) (string, []echo.MiddlewareFunc) {
+		return "/relations", []echo.MiddlewareFunc{a.Container(domain.RoleEditor, a.Body())}
+	}
+
+	tests := []struct {
+		name   string
+		perms  *domain.Permissions
+		method string
+		target string
+		body   string
+		route  func(a *Access) (string, []echo.MiddlewareFunc)
+		want   int
+	}{
+		{name: "not logged in", route: globalRoute(domain.RoleViewer), want: http.StatusUnauthorized},
+		{name: "global admin", perms: global(domain.RoleAdmin), route: globalRoute(domain.RoleAdmin), want: http.StatusOK},
+		{name: "global role too low", perms: global(domain.RoleEditor), route: globalRoute(domain.RoleAdmin), want: http.StatusForbidden},
+		{name: "container role is not global", perms: container(1, domain.RoleAdmin), route: globalRoute(domain.RoleViewer), want: http.StatusForbidden},
+		{name: "any with container role", perms: container(1, domain.RoleViewer), route: anyRoute, want: http.StatusOK},
+		{name: "any without roles", perms: container(1, ""), route: anyRoute, want: http.StatusForbidden},
+		{name: "container role", perms: container(1, domain.RoleOperator), target: "/containers/1", route: cidRoute, want: http.StatusOK},
+		{name: "other container", perms: container(1, domain.RoleOperator), target: "/containers/2", route: cidRoute, want: http.StatusForbidden},
+		{name: "higher container role", perms: container(1, domain.RoleEditor), target: "/containers/1", route: cidRoute, want: http.StatusOK},
+		{name: "lower container role", perms: container(1, domain.RoleViewer), target: "/containers/1", route: cidRoute, want: http.StatusForbidden},
+		{name: "global role covers containers", perms: global(domain.RoleOperator), target: "/containers/2", route: cidRoute, want: http.StatusOK},
+		{name: "invalid cid", perms: global(domain.RoleAdmin), target: "/containers/x", route: cidRoute, want: http.StatusBadRequest},
+		{
+			name: "body in own container", perms: container(1, domain.RoleEditor), method: http.MethodPost, target: "/relations",
+			body: `{"cid":1,"tid":10}`, route: bodyRoute, want: http.StatusOK,
+		},
+		{
+			name: "body moves task from other container", perms: container(1, domain.RoleEditor), method: http.MethodPost, target: "/relations",
+			body: `{"cid":1,"tid":10,"next_tid":20}`, route: bodyRoute, want: http.StatusForbidden,
+		},
+		{
+			name: "body references missing task", perms: container(1, domain.RoleEditor), method: http.MethodPost, target: "/relations",
+			body: `{"cid":1,"tid":99}`, route: bodyRoute, want: http.StatusNotFound,
+		},
+		{
+			name: "empty body requires global role", perms: container(1, domain.RoleEditor), method: http.MethodPost, target: "/relations",
+			body: `{}`, route: bodyRoute, want: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, target := tt.method, tt.target
+			if method == "" {
+				method = http.MethodGet
+			}
+			if target == "" {
+				target, _ = tt.route(NewAccess(&fakeAccess{}))
+			}
+			if got := accessRequest(t, tt.perms, nil, method, target, tt.body, tt.route); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessTokenScopes(t *testing.T) {
+	admin := &domain.Permissions{Global: domain.RoleAdmin, Containers: map[int]domain.Role{}}
+	viewer := &domain.Permissions{Global: domain.RoleViewer, Containers: map[int]domain.Role{}}
+	route := func(role domain.Role, logs bool) func(a *Access) (string, []echo.MiddlewareFunc) {
+		return func(a *Access) (string, []echo.MiddlewareFunc) {
+			if logs {
+				return "/r", []echo.MiddlewareFunc{a.Logs(), a.Global(role)}
+			}
+			return "/r", []echo.MiddlewareFunc{a.Global(role)}
+		}
+	}
+	session := func(a *Access) (string, []echo.MiddlewareFunc) {
+		return "/r", []echo.MiddlewareFunc{a.Session(), a.Global(domain.RoleViewer)}
+	}
+
+	tests := []struct {
+		name   string
+		perms  *domain.Permissions
+		scopes string // 为空表示使用 JWT 登录
+		route  func(a *Access) (string, []echo.MiddlewareFunc)
+		want   int
+	}{
+		{name: "jwt needs no scope", perms: admin, route: route(domain.RoleEditor, false), want: http.StatusOK},
+		{name: "read containers", perms: admin, scopes: domain.ScopeReadContainers, route: route(domain.RoleViewer, false), want: http.StatusOK},
+		{name: "read containers is not read logs", perms: admin, scopes: domain.ScopeReadContainers, route: route(domain.RoleViewer, true), want: http.StatusForbidden},
+		{name: "read logs", perms: admin, scopes: domain.ScopeReadLogs, route: route(domain.RoleViewer, true), want: http.StatusOK},
+		{name: "read logs is not read containers", perms: admin, scopes: domain.ScopeReadLogs, route: route(domain.RoleViewer, false), want: http.StatusForbidden},
+		{name: "run", perms: admin, scopes: domain.ScopeRunContainer, route: route(domain.RoleOperator, false), want: http.StatusOK},
+		{name: "read is not run", perms: admin, scopes: domain.ScopeReadContainers, route: route(domain.RoleOperator, false), want: http.StatusForbidden},
+		{name: "write containers", perms: admin, scopes: domain.ScopeWriteContainers, route: route(domain.RoleEditor, false), want: http.StatusOK},
+		{name: "write logs", perms: admin, scopes: domain.ScopeWriteLogs, route: route(domain.RoleEditor, true), want: http.StatusOK},
+		{name: "write containers is not write logs", perms: admin, scopes: domain.ScopeWriteContainers, route: route(domain.RoleEditor, true), want: http.StatusForbidden},
+		{name: "multiple scopes", perms: admin, scopes: "read:logs, write:containers", route: route(domain.RoleEditor, false), want: http.StatusOK},
+		{name: "admin scope covers all", perms: admin, scopes: domain.ScopeAdmin, route: route(domain.RoleEditor, true), want: http.StatusOK},
+		{name: "admin role needs admin scope", perms: admin, scopes: domain.ScopeWriteContainers, route: route(domain.RoleAdmin, false), want: http.StatusForbidden},
+		{name: "scope does not raise role", perms: viewer, scopes: domain.ScopeAdmin, route: route(domain.RoleEditor, false), want: http.StatusForbidden},
+		{name: "session rejects token", perms: admin, scopes: domain.ScopeAdmin, route: session, want: http.StatusForbidden},
+		{name: "session allows jwt", perms: admin, route: session, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token *domain.APIToken
+			if tt.scopes != "" {
+				token = &domain.APIToken{ID: 1, UserID: 1, Scopes: tt.scopes}
+			}
+			if got := accessRequest(t, tt.perms, token, http.MethodGet, "/r", "", tt.route); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
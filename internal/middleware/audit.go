@@ -0,0 +1,280 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/service"
+)
+
+// auditDetailLimit 审计记录中请求参数的最大长度
+const auditDetailLimit = 2048
+
+// 审计对象ID的来源
+const (
+	auditRefParam  = iota + 1 // 路径参数或查询参数
+	auditRefBody              // 请求体字段，为空时（新建）从响应中读取
+	auditRefResult            // 响应数据
+	auditRefSelf              // 当前用户
+)
+
+// AuditRef 审计对象ID的来源
+type AuditRef struct {
+	name   string
+	source int
+}
+
+// Audit 审计记录
+//
+// 每个新建、修改、删除、运行和取消的路由通过 Record 声明操作和对象，处理器成功返回后
+// 保存审计记录。新建、修改和删除操作在处理器前后读取对象快照，记录变化的字段；其他操作
+// 或无法读取对象时记录请求参数。
+type Audit struct {
+	audit service.AuditService
+}
+
+// NewAudit 创建审计记录
+func NewAudit(audit service.AuditService) *Audit {
+	return &Audit{audit: audit}
+}
+
+// Param 从路径参数或查询参数读取对象ID
+func (a *Audit) Param(name string) AuditRef {
+	return AuditRef{name: name, source: auditRefParam}
+}
+
+// Body 从请求体字段读取对象ID，字段为空时（新建）从响应数据读取
+func (a *Audit) Body(name string) AuditRef {
+	return AuditRef{name: name, source: auditRefBody}
+}
+
+// Result 从响应数据读取对象ID，响应数据为对象时读取 name 字段
+func (a *Audit) Result(name string) AuditRef {
+	return AuditRef{name: name, source: auditRefResult}
+}
+
+// Self 以当前用户为对象
+func (a *Audit) Self() AuditRef {
+	return AuditRef{source: auditRefSelf}
+}
+
+// Record 记录操作，refs 依次尝试读取对象ID
+func (a *Audit) Record(action string, targetType string, refs ...AuditRef) echo.MiddlewareFunc {
+	snapshot := action == domain.AuditCreate || action == domain.AuditUpdate ||
+		action == domain.AuditDelete || action == domain.AuditSave
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			body := readBody(c)
+
+			var id string
+			fromResult := ""
+			for _, ref := range refs {
+				switch ref.source {
+				case auditRefParam:
+					id = c.Param(ref.name)
+					if id == "" {
+						id = c.QueryParam(ref.name)
+					}
+				case auditRefBody:
+					id = bodyField(body, ref.name)
+				case auditRefSelf:
+					if user := GetUser(c); user != nil {
+						id = strconv.Itoa(user.ID)
+					}
+				}
+				if id != "" {
+					break
+				}
+				if (ref.source == auditRefBody || ref.source == auditRefResult) && fromResult == "" {
+					fromResult = ref.name
+				}
+			}
+
+			var before interface{}
+			if snapshot && id != "" {
+				var err error
+				if before, err = a.audit.Snapshot(targetType, id); err != nil {
+					logger.Warnf("[audit] failed to read %s %s: %v", targetType, id, err)
+				}
+			}
+
+			var capture *bytes.Buffer
+			if id == "" && fromResult != "" {
+				capture = &bytes.Buffer{}
+				res := c.Response()
+				res.Writer = &captureWriter{ResponseWriter: res.Writer, buf: capture}
+			}
+
+			if err := next(c); err != nil {
+				return err
+			}
+			if c.Response().Status >= http.StatusBadRequest {
+				return nil
+			}
+
+			if capture != nil {
+				id = resultID(capture.Bytes(), fromResult)
+			}
+			var after interface{}
+			if snapshot && id != "" {
+				var err error
+				if after, err = a.audit.Snapshot(targetType, id); err != nil {
+					logger.Warnf("[audit] failed to read %s %s: %v", targetType, id, err)
+				}
+			}
+
+			entry := &domain.AuditLog{
+				Actor:      auditActor(c),
+				Action:     action,
+				TargetType: targetType,
+				TargetID:   id,
+				Method:     c.Request().Method,
+				Path:       c.Request().URL.Path,
+				IP:         c.RealIP(),
+			}
+			if user := GetUser(c); user != nil {
+				entry.UserID = user.ID
+			}
+			if token := GetAPIToken(c); token != nil {
+				entry.TokenID = token.ID
+			}
+			if before == nil && after == nil {
+				entry.Detail = requestDetail(c.QueryParams(), body)
+			}
+			if err := a.audit.Record(entry, before, after); err != nil {
+				logger.Errorf("[audit] failed to record %s %s %s: %v", action, targetType, id, err)
+			}
+			return nil
+		}
+	}
+}
+
+// auditActor 返回 JWT 中的用户名
+func auditActor(c echo.Context) string {
+	if token, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if name, _ := claims["name"].(string); name != "" {
+				return name
+			}
+		}
+	}
+	if user := GetUser(c); user != nil {
+		return user.Name
+	}
+	return ""
+}
+
+// captureWriter 在写入响应的同时保存响应内容
+type captureWriter struct {
+	http.ResponseWriter
+	buf *bytes.Buffer
+}
+
+// Write 写入响应并保存
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// readBody 读取请求体，并恢复请求体供处理器再次读取
+func readBody(c echo.Context) []byte {
+	req := c.Request()
+	if req.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+// bodyField 读取 JSON 请求体中的字段
+func bodyField(body []byte, name string) string {
+	var fields map[string]interface{}
+	if len(body) == 0 || json.Unmarshal(body, &fields) != nil {
+		return ""
+	}
+	return formatID(fields[name])
+}
+
+// resultID 读取响应数据中的对象ID，响应数据为对象时读取 name 字段
+func resultID(body []byte, name string) string {
+	var res struct {
+		Data interface{} `json:"data"`
+	}
+	if json.Unmarshal(body, &res) != nil {
+		return ""
+	}
+	if fields, ok := res.Data.(map[string]interface{}); ok {
+		return formatID(fields[name])
+	}
+	return formatID(res.Data)
+}
+
+// formatID 将 JSON 中的ID转换为字符串，0 和空值返回空字符串
+func formatID(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatInt(int64(v), 10)
+	case string:
+		return v
+	}
+	return ""
+}
+
+// requestDetail 返回请求的查询参数和请求体，去掉查询参数中的认证令牌，JSON 请求体中的密码和密钥被替换
+func requestDetail(params url.Values, body []byte) string {
+	params = cloneValues(params)
+	params.Del("token")
+	query := params.Encode()
+
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &fields) == nil {
+		for key, value := range fields {
+			lower := strings.ToLower(key)
+			if s, ok := value.(string); ok && s != "" &&
+				(strings.Contains(lower, "password") || strings.Contains(lower, "secret") || strings.Contains(lower, "token")) {
+				fields[key] = "******"
+			}
+		}
+		body, _ = json.Marshal(fields)
+	}
+
+	parts := make([]string, 0, 2)
+	if query != "" {
+		parts = append(parts, query)
+	}
+	if text := strings.TrimSpace(string(body)); text != "" {
+		parts = append(parts, text)
+	}
+	detail := strings.Join(parts, "\n")
+	if len(detail) > auditDetailLimit {
+		detail = detail[:auditDetailLimit]
+	}
+	return detail
+}
+
+// cloneValues 复制查询参数
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, value := range values {
+		clone[key] = append([]string(nil), value...)
+	}
+	return clone
+}
@@ -1,14 +1,26 @@
 package middleware
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
 	"clock/internal/config"
+	"clock/internal/domain"
+	"clock/internal/service"
 )
 
+// apiTokenContextKey 当前请求使用的 API 令牌在请求上下文中的键
+const apiTokenContextKey = "api_token"
+
 // NewJWTConfig 创建JWT中间件配置
-func NewJWTConfig(cfg *config.AuthConfig) middleware.JWTConfig {
+//
+// 以 clk_ 开头的 token 按个人 API 令牌校验，校验通过后以令牌所属用户生成 JWT，
+// 后续中间件和 JWT 登录的处理方式相同。
+func NewJWTConfig(cfg *config.AuthConfig, tokens service.APITokenService) middleware.JWTConfig {
 	jwtConfig := middleware.DefaultJWTConfig
 	jwtConfig.SigningKey = []byte(cfg.JWTSecret)
 	jwtConfig.TokenLookup = "header:token:,cookie:token,query:token"
@@ -20,5 +32,39 @@ func NewJWTConfig(cfg *config.AuthConfig) middleware.JWTConfig {
 		return path == "/v1/login"
 	}
 
+	jwtConfig.ParseTokenFunc = func(auth string, c echo.Context) (interface{}, error) {
+		if strings.HasPrefix(auth, domain.APITokenPrefix) {
+			token, user, err := tokens.Authenticate(auth)
+			if err != nil {
+				return nil, err
+			}
+			c.Set(apiTokenContextKey, token)
+			return &jwt.Token{
+				Valid:  true,
+				Claims: jwt.MapClaims{"name": user.Name},
+			}, nil
+		}
+
+		token, err := jwt.Parse(auth, func(t *jwt.Token) (interface{}, error) {
+			if t.Method.Alg() != jwtConfig.SigningMethod {
+				return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+			}
+			return jwtConfig.SigningKey, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !token.Valid {
+			return nil, fmt.Errorf("invalid token")
+		}
+		return token, nil
+	}
+
 	return jwtConfig
 }
+
+// GetAPIToken 获取当前请求使用的 API 令牌，使用 JWT 登录时返回 nil
+func GetAPIToken(c echo.Context) *domain.APIToken {
+	token, _ := c.Get(apiTokenContextKey).(*domain.APIToken)
+	return token
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/service"
+)
+
+// userContextKey 当前用户在请求上下文中的键
+const userContextKey = "current_user"
+
+// passwordChangePaths 必须修改密码时仍允许访问的接口
+var passwordChangePaths = map[string]bool{
+	"/v1/user/me":          true,
+	"/v1/user/me/password": true,
+}
+
+// CurrentUser 根据 JWT 中的用户加载用户记录
+//
+// 用户不存在或已禁用时拒绝请求，签发过的 token 随之失效；用户必须修改密码时
+// 只允许访问查看和修改自己密码的接口。
+func CurrentUser(users service.UserService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				// 无需认证的接口
+				return next(c)
+			}
+			claims, _ := token.Claims.(jwt.MapClaims)
+			name, _ := claims["name"].(string)
+
+			user, err := users.GetByName(name)
+			if err != nil {
+				if apperrors.IsNotFound(err) {
+					return errorJSON(c, http.StatusUnauthorized, apperrors.ErrUnauthorized, "user not found")
+				}
+				return err
+			}
+			if user.Disable {
+				return errorJSON(c, http.StatusUnauthorized, apperrors.ErrUnauthorized, "user is disabled")
+			}
+			if user.MustChangePassword && !passwordChangePaths[c.Path()] {
+				return errorJSON(c, http.StatusForbidden, apperrors.ErrForbidden, "password change required")
+			}
+
+			c.Set(userContextKey, user)
+			return next(c)
+		}
+	}
+}
+
+// GetUser 获取当前请求的用户，未认证时返回 nil
+func GetUser(c echo.Context) *domain.User {
+	user, _ := c.Get(userContextKey).(*domain.User)
+	return user
+}
+
+// errorJSON 返回与 API 响应结构一致的错误
+func errorJSON(c echo.Context, status int, code apperrors.ErrorCode, msg string) error {
+	return c.JSON(status, map[string]interface{}{
+		"code": int(code),
+		"msg":  msg,
+		"data": nil,
+	})
+}
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"clock/internal/config"
+)
+
+// smtpsPort 使用隐式 TLS 连接的端口
+const smtpsPort = 465
+
+// email 通过 SMTP 发送邮件的通道
+type email struct {
+	cfg *config.NotifyChannelConfig
+}
+
+func newEmail(cfg *config.NotifyChannelConfig) *email {
+	return &email{cfg: cfg}
+}
+
+// Name 通道名称
+func (e *email) Name() string {
+	return e.cfg.Name
+}
+
+// Type 通道类型
+func (e *email) Type() string {
+	return TypeEmail
+}
+
+// Send 发送邮件（消息包含 HTML 时同时发送纯文本和 HTML），465 端口使用 TLS，其他端口在服务器支持时使用 STARTTLS
+func (e *email) Send(ctx context.Context, msg *Message) error {
+	port := e.cfg.SMTPPort
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(e.cfg.SMTPHost, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: e.cfg.SMTPHost}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(sendTimeout)
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var conn net.Conn
+	var err error
+	if port == smtpsPort {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, e.cfg.SMTPHost)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if port != smtpsPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if e.cfg.Username != "" {
+		auth := smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range e.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(e.compose(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose 组装邮件内容
+func (e *email) compose(msg *Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mimeHeader(msg.Title))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	if msg.HTML == "" {
+		writePart(&b, "text/plain", msg.Text)
+		return []byte(b.String())
+	}
+
+	boundary := fmt.Sprintf("clock-%d", time.Now().UnixNano())
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	writePart(&b, "text/plain", msg.Text)
+	fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+	writePart(&b, "text/html", msg.HTML)
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+	return []byte(b.String())
+}
+
+// writePart 写入内容类型头和正文
+func writePart(b *strings.Builder, contentType string, body string) {
+	fmt.Fprintf(b, "Content-Type: %s; charset=UTF-8\r\n", contentType)
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	// SMTP 要求 CRLF 换行
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+}
+
+// mimeHeader 对非 ASCII 的邮件头进行编码
+func mimeHeader(s string) string {
+	for _, r := range s {
+		if r > 127 {
+			return mime.BEncoding.Encode("UTF-8", s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+// Package notify 实现任务和批次结束通知的发送通道
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"clock/internal/config"
+)
+
+// 通道类型
+const (
+	TypeWebhook  = "webhook"  // 通用 webhook，发送完整的 JSON 消息
+	TypeEmail    = "email"    // SMTP 邮件
+	TypeSlack    = "slack"    // Slack 兼容的 incoming webhook
+	TypeDingTalk = "dingtalk" // 钉钉机器人
+	TypeFeishu   = "feishu"   // 飞书机器人
+)
+
+// sendTimeout 单次发送的超时时间
+const sendTimeout = 10 * time.Second
+
+// Message 通知消息
+type Message struct {
+	Event         string   `json:"event"`  // task_end, run_end, sla_miss, report
+	Status        string   `json:"status"` // success, failure, cancelled
+	Title         string   `json:"title"`
+	Text          string   `json:"text"`           // 渲染后的纯文本内容
+	HTML          string   `json:"html,omitempty"` // HTML 内容（报表），邮件通道同时发送纯文本和 HTML
+	Tid           int      `json:"tid,omitempty"`
+	Cid           int      `json:"cid,omitempty"`
+	TaskName      string   `json:"task_name,omitempty"`
+	ContainerName string   `json:"container_name,omitempty"`
+	RunID         string   `json:"run_id,omitempty"`
+	DurationMs    int64    `json:"duration_ms"`
+	ExitCode      *int     `json:"exit_code,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	Stderr        []string `json:"stderr,omitempty"` // 最近的错误输出
+	Failed        []string `json:"failed,omitempty"` // 批次中失败的任务名称
+	Time          int64    `json:"time"`             // 结束时间（秒）
+	URL           string   `json:"url,omitempty"`    // 界面中查看日志的链接（配置 base_url 后生成）
+}
+
+// Duration 返回可读的耗时，供模板使用
+func (m *Message) Duration() string {
+	return (time.Duration(m.DurationMs) * time.Millisecond).String()
+}
+
+// Excerpt 返回以换行连接的最近错误输出，供模板使用
+func (m *Message) Excerpt() string {
+	return strings.Join(m.Stderr, "\n")
+}
+
+// Channel 通知通道
+type Channel interface {
+	Name() string
+	Type() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New 根据配置创建通知通道
+func New(cfg *config.NotifyChannelConfig) (Channel, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("channel name is required")
+	}
+	switch cfg.Type {
+	case TypeWebhook, TypeSlack, TypeDingTalk, TypeFeishu:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("channel %s: url is required", cfg.Name)
+		}
+		return newWebhook(cfg), nil
+	case TypeEmail:
+		if cfg.SMTPHost == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("channel %s: smtp_host, from and to are required", cfg.Name)
+		}
+		return newEmail(cfg), nil
+	default:
+		return nil, fmt.Errorf("channel %s: unknown type %q", cfg.Name, cfg.Type)
+	}
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate 默认消息模板
+//
+// 模板使用 Go text/template 语法，上下文为 Message：
+//
+//	.Event          task_end, run_end 或 sla_miss
+//	.Status         success, failure, cancelled, sla_miss
+//	.Title          默认标题
+//	.TaskName .Tid  任务名称和ID（任务消息）
+//	.ContainerName .Cid
+//	.RunID          批次ID（单独运行的任务为空）
+//	.DurationMs     耗时（毫秒，SLA 消息为已用时间），.Duration 为可读格式
+//	.ExitCode       进程退出码（未正常退出时为 nil）
+//	.Error          错误信息（SLA 消息为未达成的说明）
+//	.Stderr         最近的错误输出行，.Excerpt 为换行连接的文本
+//	.Failed         批次中失败的任务名称（批次消息）
+//	.Time           结束时间（秒）
+//	.URL            界面中查看日志的链接
+//
+// 可用函数：join, upper, lower, time（格式化秒级时间戳）, tail（取列表最后 N 项）。
+const DefaultTemplate = `{{.Title}}
+
+{{if .Tid}}Task: {{.TaskName}} (#{{.Tid}})
+{{if .ContainerName}}Container: {{.ContainerName}}
+{{end}}{{else}}Container: {{.ContainerName}}
+{{end}}Status: {{.Status}}
+{{if .RunID}}Run: {{.RunID}}
+{{end}}Duration: {{.Duration}}
+{{if .ExitCode}}Exit code: {{.ExitCode}}
+{{end}}{{if .Error}}Error: {{.Error}}
+{{end}}{{if .Failed}}Failed tasks: {{join .Failed ", "}}
+{{end}}{{if .Stderr}}
+Last {{len .Stderr}} stderr lines:
+{{.Excerpt}}
+{{end}}{{if .URL}}
+{{.URL}}
+{{end}}`
+
+// templateFuncs 模板可用的函数
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"time": func(sec int64) string {
+		return time.Unix(sec, 0).Format("2006-01-02 15:04:05")
+	},
+	"tail": func(n int, items []string) []string {
+		if n >= 0 && len(items) > n {
+			return items[len(items)-n:]
+		}
+		return items
+	},
+}
+
+// defaultTemplate 解析后的默认模板
+var defaultTemplate = template.Must(ParseTemplate(DefaultTemplate))
+
+// ParseTemplate 解析消息模板
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("notify").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// Render 生成消息的标题，并使用模板渲染消息内容，tmpl 为 nil 时使用默认模板
+func Render(msg *Message, tmpl *template.Template) error {
+	switch msg.Event {
+	case "run_end":
+		msg.Title = fmt.Sprintf("[clock] run %s of %s: %s", msg.RunID, msg.ContainerName, msg.Status)
+	case "sla_miss":
+		name := msg.ContainerName
+		if msg.Tid > 0 {
+			name = msg.TaskName
+		}
+		msg.Title = fmt.Sprintf("[clock] SLA miss: %s", name)
+	default:
+		msg.Title = fmt.Sprintf("[clock] task %s: %s", msg.TaskName, msg.Status)
+	}
+
+	if tmpl == nil {
+		tmpl = defaultTemplate
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, msg); err != nil {
+		return err
+	}
+	msg.Text = b.String()
+	return nil
+}
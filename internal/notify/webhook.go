@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"clock/internal/config"
+)
+
+// webhook 通过 HTTP POST 发送 JSON 消息的通道
+type webhook struct {
+	cfg    *config.NotifyChannelConfig
+	client *http.Client
+}
+
+func newWebhook(cfg *config.NotifyChannelConfig) *webhook {
+	return &webhook{cfg: cfg, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Name 通道名称
+func (w *webhook) Name() string {
+	return w.cfg.Name
+}
+
+// Type 通道类型
+func (w *webhook) Type() string {
+	return w.cfg.Type
+}
+
+// Send 按通道类型组装请求体并发送
+func (w *webhook) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(w.payload(msg))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook %s returned %s: %s", w.cfg.Name, resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// payload 组装各平台的请求体，机器人类通道只发送渲染后的文本
+func (w *webhook) payload(msg *Message) interface{} {
+	text := msg.Text
+	switch w.cfg.Type {
+	case TypeSlack:
+		return map[string]interface{}{"text": text}
+	case TypeDingTalk:
+		return map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": text},
+		}
+	case TypeFeishu:
+		return map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": text},
+		}
+	default:
+		return msg
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// apiTokenRepository API 令牌仓储实现
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository 创建 API 令牌仓储
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+// List 获取用户的令牌，userID 为 0 时返回全部令牌
+func (r *apiTokenRepository) List(userID int) ([]*domain.APIToken, error) {
+	db := r.db.Model(&domain.APIToken{})
+	if userID > 0 {
+		db = db.Where("user_id = ?", userID)
+	}
+
+	var tokens []*domain.APIToken
+	if err := db.Order("id desc").Find(&tokens).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return tokens, nil
+}
+
+// GetByID 根据ID获取令牌
+func (r *apiTokenRepository) GetByID(id int) (*domain.APIToken, error) {
+	var token domain.APIToken
+	if err := r.db.Where("id = ?", id).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("token")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &token, nil
+}
+
+// GetByHash 根据哈希获取令牌
+func (r *apiTokenRepository) GetByHash(hash string) (*domain.APIToken, error) {
+	var token domain.APIToken
+	if err := r.db.Where("hash = ?", hash).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("token")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &token, nil
+}
+
+// Save 保存令牌
+func (r *apiTokenRepository) Save(token *domain.APIToken) error {
+	if token.CreateAt == 0 {
+		token.CreateAt = time.Now().Unix()
+	}
+	if err := r.db.Save(token).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// UpdateLastUsed 更新最近使用时间
+func (r *apiTokenRepository) UpdateLastUsed(id int, at int64) error {
+	if err := r.db.Model(&domain.APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Delete 删除令牌
+func (r *apiTokenRepository) Delete(id int) error {
+	if err := r.db.Where("id = ?", id).Delete(&domain.APIToken{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// auditLogRepository 审计记录仓储实现
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计记录仓储
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Save 保存审计记录
+func (r *auditLogRepository) Save(entry *domain.AuditLog) error {
+	if entry.CreateAt == 0 {
+		entry.CreateAt = time.Now().Unix()
+	}
+	if err := r.db.Create(entry).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// List 分页查询审计记录，按时间倒序
+func (r *auditLogRepository) List(query *AuditQuery) ([]*domain.AuditLog, error) {
+	var entries []*domain.AuditLog
+
+	// 设置默认值
+	if query.Count < 1 {
+		query.Count = 10
+	}
+	if query.Index < 1 {
+		query.Index = 1
+	}
+
+	db := r.db.Model(&domain.AuditLog{})
+
+	// 条件过滤
+	if query.Actor != "" {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.TargetType != "" {
+		db = db.Where("target_type = ?", query.TargetType)
+	}
+	if query.TargetID != "" {
+		db = db.Where("target_id = ?", query.TargetID)
+	}
+	if query.LeftTs > 0 {
+		db = db.Where("create_at >= ?", query.LeftTs)
+	}
+	if query.RightTs > 0 {
+		db = db.Where("create_at < ?", query.RightTs)
+	}
+
+	// 统计总数
+	if err := db.Count(&query.Total).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+
+	db = db.Offset((query.Index - 1) * query.Count).Limit(query.Count).Order("create_at desc, id desc")
+	if err := db.Find(&entries).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return entries, nil
+}
+
+// DeleteBefore 删除指定时间（秒）之前的审计记录
+func (r *auditLogRepository) DeleteBefore(ts int64) (int64, error) {
+	result := r.db.Where("create_at < ?", ts).Delete(&domain.AuditLog{})
+	if result.Error != nil {
+		return 0, apperrors.Database(result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -31,6 +31,18 @@ func (r *containerRepository) GetByID(cid int) (*domain.Container, error) {
 	return &container, nil
 }
 
+// GetByIDUnscoped 根据ID获取容器（包括回收站中的容器）
+func (r *containerRepository) GetByIDUnscoped(cid int) (*domain.Container, error) {
+	var container domain.Container
+	if err := r.db.Unscoped().Where("cid = ?", cid).First(&container).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("container")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &container, nil
+}
+
 // List 查询容器列表
 func (r *containerRepository) List(query *ContainerQuery) ([]*domain.Container, error) {
 	var containers []*domain.Container
@@ -46,6 +58,12 @@ func (r *containerRepository) List(query *ContainerQuery) ([]*domain.Container,
 	db := r.db.Model(&domain.Container{})
 
 	// 条件过滤
+	if query.Trashed {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if query.Cids != nil {
+		db = db.Where("cid IN ?", query.Cids)
+	}
 	if query.Name != "" {
 		db = db.Where("name LIKE ?", "%"+query.Name+"%")
 	}
@@ -86,9 +104,46 @@ func (r *containerRepository) Save(container *domain.Container) error {
 	return nil
 }
 
-// Delete 删除容器
+// UpdateStatus 仅更新容器状态（已删除的容器不受影响）
+func (r *containerRepository) UpdateStatus(container *domain.Container) error {
+	container.UpdateAt = time.Now().Unix()
+	if err := r.db.Model(&domain.Container{}).Where("cid = ?", container.Cid).
+		Updates(map[string]interface{}{
+			"status":    container.Status,
+			"update_at": container.UpdateAt,
+		}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Delete 彻底删除容器
 func (r *containerRepository) Delete(cid int) error {
-	if err := r.db.Where("cid = ?", cid).Delete(&domain.Container{}).Error; err != nil {
+	if err := r.db.Unscoped().Where("cid = ?", cid).Delete(&domain.Container{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// SoftDelete 将容器移入回收站
+func (r *containerRepository) SoftDelete(cid int, at time.Time) error {
+	if err := r.db.Model(&domain.Container{}).Where("cid = ?", cid).
+		Updates(map[string]interface{}{
+			"entry_id":   -1,
+			"deleted_at": at,
+		}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Restore 从回收站恢复容器
+func (r *containerRepository) Restore(cid int) error {
+	if err := r.db.Unscoped().Model(&domain.Container{}).Where("cid = ?", cid).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"update_at":  time.Now().Unix(),
+		}).Error; err != nil {
 		return apperrors.Database(err)
 	}
 	return nil
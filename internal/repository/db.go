@@ -32,15 +32,38 @@ func NewDB(cfg *config.StorageConfig) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	// 关系唯一索引已扩展为包含任务组端点，删除旧索引
+	if db.Migrator().HasIndex(&domain.Relation{}, "uidx_tid_next") {
+		if err := db.Migrator().DropIndex(&domain.Relation{}, "uidx_tid_next"); err != nil {
+			return nil, err
+		}
+	}
+
 	// 自动迁移
 	if err := db.AutoMigrate(
 		&domain.Task{},
 		&domain.Container{},
 		&domain.TaskLog{},
 		&domain.Relation{},
+		&domain.TaskRun{},
+		&domain.TaskGroup{},
+		&domain.RunEvent{},
+		&domain.NotifyRule{},
+		&domain.Webhook{},
+		&domain.WebhookDelivery{},
+		&domain.SlaMiss{},
+		&domain.User{},
+		&domain.RoleBinding{},
+		&domain.APIToken{},
+		&domain.AuditLog{},
 	); err != nil {
 		return nil, err
 	}
 
+	// 日志全文索引（各数据库语法不同，不使用 AutoMigrate）
+	if err := migrateLogIndex(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// logLinesFile 行记录在存储后端中的文件名
+const logLinesFile = "lines"
+
+// maxLogLineSize 读取行记录时允许的最大行长度
+const maxLogLineSize = 4 * 1024 * 1024
+
+// encodeLogLine 编码行记录：行号\t时间戳\t输出流\t内容\n
+func encodeLogLine(line *domain.LogLine) []byte {
+	text := strings.ReplaceAll(line.Text, "\n", " ")
+	return []byte(fmt.Sprintf("%d\t%d\t%s\t%s\n", line.No, line.Ts, line.Stream, text))
+}
+
+// decodeLogLine 解析行记录，格式错误时返回 false
+func decodeLogLine(s string) (*domain.LogLine, bool) {
+	parts := strings.SplitN(s, "\t", 4)
+	if len(parts) != 4 {
+		return nil, false
+	}
+	no, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &domain.LogLine{No: no, Ts: ts, Stream: parts[2], Text: parts[3]}, true
+}
+
+// ReadLines 按行号范围和输出流读取行记录，返回的第二个值表示是否还有更多匹配行
+func (r *taskLogRepository) ReadLines(lid string, query *LogLineQuery) ([]*domain.LogLine, bool, error) {
+	log, err := r.getWithOutput(lid)
+	if err != nil {
+		return nil, false, err
+	}
+
+	lines := make([]*domain.LogLine, 0)
+	hasMore := false
+	err = r.scanLines(log, func(line *domain.LogLine) bool {
+		if query.To > 0 && line.No > query.To {
+			return false
+		}
+		if line.No < query.From || (query.Stream != "" && line.Stream != query.Stream) {
+			return true
+		}
+		if len(lines) >= query.Limit {
+			hasMore = true
+			return false
+		}
+		if line.Ts > 0 && log.StartAt > 0 {
+			line.Offset = line.Ts - log.StartAt
+		}
+		lines = append(lines, line)
+		return true
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return lines, hasMore, nil
+}
+
+// scanLines 按顺序遍历日志的行记录，fn 返回 false 时停止
+//
+// 旧的 blob 格式没有时间信息，依次返回标准输出和标准错误的各行。
+func (r *taskLogRepository) scanLines(log *domain.TaskLog, fn func(line *domain.LogLine) bool) error {
+	if log.Format != domain.LogFormatLines {
+		no := int64(0)
+		for _, stream := range []string{domain.LogStreamStdout, domain.LogStreamStderr} {
+			rc, err := r.openBlob(log, stream)
+			if err != nil {
+				return err
+			}
+			stop := false
+			err = scanText(rc, func(text string) bool {
+				no++
+				stop = !fn(&domain.LogLine{No: no, Stream: stream, Text: text})
+				return !stop
+			})
+			_ = rc.Close()
+			if err != nil || stop {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rc, err := r.openRecords(log)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return scanText(rc, func(text string) bool {
+		line, ok := decodeLogLine(text)
+		if !ok {
+			return true
+		}
+		return fn(line)
+	})
+}
+
+// scanText 逐行读取文本
+func scanText(r io.Reader, fn func(text string) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		if !fn(scanner.Text()) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return apperrors.Storage(err)
+	}
+	return nil
+}
+
+// openRecords 打开 lines 格式的行记录
+func (r *taskLogRepository) openRecords(log *domain.TaskLog) (io.ReadCloser, error) {
+	if log.Storage == domain.LogStorageDB {
+		return io.NopCloser(strings.NewReader(log.Records)), nil
+	}
+	return r.openStored(log, logLinesFile)
+}
+
+// openBlob 打开 blob 格式的某个输出流
+func (r *taskLogRepository) openBlob(log *domain.TaskLog, stream string) (io.ReadCloser, error) {
+	// 旧数据 Storage 为空，输出保存在数据库中
+	if log.Storage == "" || log.Storage == domain.LogStorageDB {
+		output := log.StdOut
+		if stream == domain.LogStreamStderr {
+			output = log.StdErr
+		}
+		return io.NopCloser(strings.NewReader(output)), nil
+	}
+	return r.openStored(log, stream)
+}
+
+// openStored 从存储后端打开输出文件
+func (r *taskLogRepository) openStored(log *domain.TaskLog, name string) (io.ReadCloser, error) {
+	if r.store == nil || r.store.Name() != log.Storage {
+		return nil, apperrors.Storage(fmt.Errorf("unknown log storage: %s", log.Storage))
+	}
+	rc, err := r.store.Open(log, name)
+	if err != nil {
+		if _, ok := err.(*apperrors.AppError); ok {
+			return nil, err
+		}
+		return nil, apperrors.Storage(err)
+	}
+
+	// 运行中的输出只有已落盘的部分可读
+	if log.Running {
+		return &partialReader{ReadCloser: rc}, nil
+	}
+	return rc, nil
+}
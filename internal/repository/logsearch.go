@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// logLinesTable 日志行全文索引表
+const logLinesTable = "task_log_lines"
+
+// logSearchMatches 每条日志返回的匹配行数
+const logSearchMatches = 3
+
+// logSearchSnippetSize 片段附近保留的字符数（MySQL 在应用层生成片段）
+const logSearchSnippetSize = 60
+
+// 片段中的高亮标记
+const (
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+)
+
+// logLineIndex 日志行索引记录
+type logLineIndex struct {
+	Lid    string
+	LineNo int64
+	Text   string
+}
+
+// migrateLogIndex 创建日志行全文索引
+//
+// sqlite 使用 FTS5 虚拟表，mysql 使用 FULLTEXT 索引，postgres 使用 tsvector + GIN 索引。
+func migrateLogIndex(db *gorm.DB) error {
+	var stmts []string
+	switch db.Dialector.Name() {
+	case "sqlite":
+		stmts = []string{
+			"CREATE VIRTUAL TABLE IF NOT EXISTS task_log_lines USING fts5(text, lid UNINDEXED, line_no UNINDEXED)",
+		}
+	case "mysql":
+		stmts = []string{
+			"CREATE TABLE IF NOT EXISTS task_log_lines (" +
+				"id BIGINT AUTO_INCREMENT PRIMARY KEY, " +
+				"lid VARCHAR(64) NOT NULL, " +
+				"line_no BIGINT NOT NULL, " +
+				"text TEXT NOT NULL, " +
+				"INDEX idx_log_lines_lid (lid), " +
+				"FULLTEXT INDEX ft_log_lines_text (text)" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		}
+	case "postgres":
+		stmts = []string{
+			"CREATE TABLE IF NOT EXISTS task_log_lines (" +
+				"id BIGSERIAL PRIMARY KEY, " +
+				"lid VARCHAR(64) NOT NULL, " +
+				"line_no BIGINT NOT NULL, " +
+				"text TEXT NOT NULL, " +
+				"tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', text)) STORED" +
+				")",
+			"CREATE INDEX IF NOT EXISTS idx_log_lines_lid ON task_log_lines (lid)",
+			"CREATE INDEX IF NOT EXISTS idx_log_lines_tsv ON task_log_lines USING GIN (tsv)",
+		}
+	default:
+		return fmt.Errorf("unsupported database dialect: %s", db.Dialector.Name())
+	}
+
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexLogLines 将行记录写入全文索引（meta 行不索引）
+func indexLogLines(db *gorm.DB, lid string, lines []*domain.LogLine) error {
+	rows := make([]*logLineIndex, 0, len(lines))
+	for _, line := range lines {
+		if line.Stream == domain.LogStreamMeta || strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+		rows = append(rows, &logLineIndex{Lid: lid, LineNo: line.No, Text: line.Text})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := db.Table(logLinesTable).CreateInBatches(rows, 500).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// deleteLogIndex 删除日志的全文索引
+func deleteLogIndex(db *gorm.DB, lids []string) error {
+	for start := 0; start < len(lids); start += 500 {
+		end := start + 500
+		if end > len(lids) {
+			end = len(lids)
+		}
+		if err := db.Exec("DELETE FROM task_log_lines WHERE lid IN ?", lids[start:end]).Error; err != nil {
+			return apperrors.Database(err)
+		}
+	}
+	return nil
+}
+
+// searchTerm 搜索词：普通词、短语（"..."）或前缀（word*）
+type searchTerm struct {
+	words  []string
+	prefix bool
+}
+
+// searchTermPattern 匹配双引号短语或连续的非空白字符
+var searchTermPattern = regexp.MustCompile(`"[^"]*"|\S+`)
+
+// parseSearch 解析搜索表达式，多个搜索词之间为 AND 关系
+func parseSearch(search string) ([]searchTerm, error) {
+	var terms []searchTerm
+	for _, raw := range searchTermPattern.FindAllString(search, -1) {
+		quoted := strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) && len(raw) >= 2
+		prefix := !quoted && strings.HasSuffix(raw, "*")
+
+		// 只保留字母和数字，其余字符作为分隔符，避免注入各数据库的查询语法
+		words := strings.FieldsFunc(strings.Trim(raw, `"*`), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+		// 多个词（引号短语或 a.b 这类带分隔符的词）按短语匹配，前缀只作用于最后一个词
+		terms = append(terms, searchTerm{words: words, prefix: prefix})
+	}
+	if len(terms) == 0 {
+		return nil, apperrors.InvalidParam("search expression is empty")
+	}
+	return terms, nil
+}
+
+// buildMatch 生成各数据库的全文检索表达式
+func buildMatch(dialect string, terms []searchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		switch dialect {
+		case "sqlite":
+			part := `"` + strings.Join(term.words, " ") + `"`
+			if term.prefix {
+				part += "*"
+			}
+			parts = append(parts, part)
+		case "mysql":
+			// MySQL 布尔模式不支持短语前缀，短语按完整匹配处理
+			part := strings.Join(term.words, " ")
+			if len(term.words) > 1 {
+				part = `"` + part + `"`
+			} else if term.prefix {
+				part += "*"
+			}
+			parts = append(parts, "+"+part)
+		case "postgres":
+			words := make([]string, len(term.words))
+			for i, w := range term.words {
+				words[i] = "'" + strings.ToLower(w) + "'"
+			}
+			if term.prefix {
+				words[len(words)-1] += ":*"
+			}
+			parts = append(parts, "("+strings.Join(words, " <-> ")+")")
+		}
+	}
+
+	if dialect == "postgres" {
+		return strings.Join(parts, " & ")
+	}
+	return strings.Join(parts, " ")
+}
+
+// matchCondition 返回匹配日志行的 SQL 条件（针对 task_log_lines 表）
+func matchCondition(dialect string) string {
+	switch dialect {
+	case "sqlite":
+		return "task_log_lines MATCH ?"
+	case "mysql":
+		return "MATCH(text) AGAINST(? IN BOOLEAN MODE)"
+	default:
+		return "tsv @@ to_tsquery('simple', ?)"
+	}
+}
+
+// searchMatches 查询日志中的匹配行及高亮片段
+func searchMatches(db *gorm.DB, lid string, terms []searchTerm) ([]domain.LogMatch, error) {
+	dialect := db.Dialector.Name()
+	match := buildMatch(dialect, terms)
+
+	var selectSnippet string
+	switch dialect {
+	case "sqlite":
+		selectSnippet = fmt.Sprintf("snippet(task_log_lines, 0, '%s', '%s', '...', 16) AS snippet", markOpen, markClose)
+	case "postgres":
+		selectSnippet = fmt.Sprintf("ts_headline('simple', text, to_tsquery('simple', ?), 'StartSel=%s, StopSel=%s, MaxWords=24, MinWords=8') AS snippet", markOpen, markClose)
+	default:
+		selectSnippet = "text AS snippet"
+	}
+
+	query := db.Table(logLinesTable).
+		Where("lid = ?", lid).
+		Where(matchCondition(dialect), match).
+		Order("line_no").
+		Limit(logSearchMatches)
+	if dialect == "postgres" {
+		query = query.Select("line_no AS no, "+selectSnippet, match)
+	} else {
+		query = query.Select("line_no AS no, " + selectSnippet)
+	}
+
+	var matches []domain.LogMatch
+	if err := query.Scan(&matches).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+
+	// MySQL 没有内置的高亮函数，在应用层生成片段
+	if dialect == "mysql" {
+		for i := range matches {
+			matches[i].Snippet = highlight(matches[i].Snippet, terms)
+		}
+	}
+	return matches, nil
+}
+
+// highlight 为文本中的搜索词添加高亮标记，并截取第一个匹配附近的内容
+func highlight(text string, terms []searchTerm) string {
+	var patterns []string
+	for _, term := range terms {
+		quoted := make([]string, len(term.words))
+		for i, w := range term.words {
+			quoted[i] = regexp.QuoteMeta(w)
+		}
+		pattern := strings.Join(quoted, `[^\pL\pN]+`)
+		if term.prefix {
+			pattern += `[\pL\pN]*`
+		}
+		patterns = append(patterns, pattern)
+	}
+	re, err := regexp.Compile(`(?i)` + strings.Join(patterns, "|"))
+	if err != nil {
+		return text
+	}
+
+	runes := []rune(text)
+	if loc := re.FindStringIndex(text); loc != nil {
+		first := len([]rune(text[:loc[0]]))
+		start := first - logSearchSnippetSize
+		end := first + logSearchSnippetSize*2
+		prefix, suffix := "", ""
+		if start > 0 {
+			prefix = "..."
+		} else {
+			start = 0
+		}
+		if end < len(runes) {
+			suffix = "..."
+		} else {
+			end = len(runes)
+		}
+		text = prefix + string(runes[start:end]) + suffix
+	}
+	return re.ReplaceAllString(text, markOpen+"$0"+markClose)
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"clock/internal/domain"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		search  string
+		want    []searchTerm
+		wantErr bool
+	}{
+		{name: "words", search: "disk full", want: []searchTerm{{words: []string{"disk"}}, {words: []string{"full"}}}},
+		{name: "phrase", search: `"connection refused" retry`, want: []searchTerm{{words: []string{"connection", "refused"}}, {words: []string{"retry"}}}},
+		{name: "prefix", search: "time*", want: []searchTerm{{words: []string{"time"}, prefix: true}}},
+		{name: "quoted star is not prefix", search: `"time*"`, want: []searchTerm{{words: []string{"time"}}}},
+		{name: "separator makes phrase", search: "db.conn*", want: []searchTerm{{words: []string{"db", "conn"}, prefix: true}}},
+		{name: "unicode", search: "错误 café", want: []searchTerm{{words: []string{"错误"}}, {words: []string{"café"}}}},
+		{name: "sqlite syntax stripped", search: `a OR b NEAR(c) text:x ^d`, want: []searchTerm{
+			{words: []string{"a"}}, {words: []string{"OR"}}, {words: []string{"b"}}, {words: []string{"NEAR", "c"}},
+			{words: []string{"text", "x"}}, {words: []string{"d"}},
+		}},
+		{name: "mysql operators stripped", search: `+a -b ~c >d <e (f)`, want: []searchTerm{
+			{words: []string{"a"}}, {words: []string{"b"}}, {words: []string{"c"}}, {words: []string{"d"}},
+			{words: []string{"e"}}, {words: []string{"f"}},
+		}},
+		{name: "quotes and sql stripped", search: `x' OR '1'='1`, want: []searchTerm{
+			{words: []string{"x"}}, {words: []string{"OR"}}, {words: []string{"1", "1"}},
+		}},
+		{name: "unbalanced quote", search: `"abc`, want: []searchTerm{{words: []string{"abc"}}}},
+		{name: "empty", search: "   ", wantErr: true},
+		{name: "only symbols", search: `"" * -- ()`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSearch(tt.search)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSearch(%q) error = %v, wantErr %v", tt.search, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSearch(%q) = %+v, want %+v", tt.search, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildMatch(t *testing.T) {
+	terms := []searchTerm{
+		{words: []string{"Disk"}},
+		{words: []string{"connection", "refused"}},
+		{words: []string{"time"}, prefix: true},
+		{words: []string{"db", "conn"}, prefix: true},
+	}
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{dialect: "sqlite", want: `"Disk" "connection refused" "time"* "db conn"*`},
+		{dialect: "mysql", want: `+Disk +"connection refused" +time* +"db conn"`},
+		{dialect: "postgres", want: `('disk') & ('connection' <-> 'refused') & ('time':*) & ('db' <-> 'conn':*)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			if got := buildMatch(tt.dialect, terms); got != tt.want {
+				t.Errorf("buildMatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchLogs(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewTaskLogRepository(db, nil)
+	outputs := map[string][]string{
+		"a": {"connecting to db.primary", "connection refused, retry in 5s"},
+		"b": {"timeout after 30s", "disk full"},
+		"c": {"all good"},
+	}
+	for lid, lines := range outputs {
+		w, err := repo.Create(&domain.TaskLog{Lid: lid, Tid: 1, Cid: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, text := range lines {
+			if err := w.Write(&domain.LogLine{No: int64(i + 1), Stream: domain.LogStreamStdout, Text: text}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{search: "disk", want: []string{"b"}},
+		{search: "DISK FULL", want: []string{"b"}},
+		{search: `"connection refused"`, want: []string{"a"}},
+		{search: `"refused connection"`},
+		{search: "conn*", want: []string{"a"}},
+		{search: "db.prim*", want: []string{"a"}},
+		{search: "retry disk"},
+		{search: `x" OR "good`},
+		{search: `good" OR lid:*`},
+		{search: "NEAR(all good)"},
+		{search: "all AND good"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			logs, err := repo.List(&LogQuery{Search: tt.search})
+			if err != nil {
+				t.Fatalf("List(%q) error = %v", tt.search, err)
+			}
+			var got []string
+			for _, log := range logs {
+				got = append(got, log.Lid)
+				if len(log.Matches) == 0 {
+					t.Errorf("log %s has no matches", log.Lid)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List(%q) = %v, want %v", tt.search, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// LogStore 任务输出存储后端
+//
+// 日志元数据始终保存在数据库中，输出内容由 LogStore 负责读写。
+type LogStore interface {
+	// Name 后端名称，记录在日志元数据的 Storage 字段中
+	Name() string
+	// Create 创建某个输出文件（stdout, stderr, lines）的写入器
+	Create(log *domain.TaskLog, name string) (LogStreamWriter, error)
+	// Open 打开某个输出文件用于读取
+	Open(log *domain.TaskLog, name string) (io.ReadCloser, error)
+	// Delete 删除日志的全部输出
+	Delete(log *domain.TaskLog) error
+}
+
+// LogStreamWriter 单个输出流写入器
+type LogStreamWriter interface {
+	io.WriteCloser
+	// Flush 将已写入内容落盘，使运行中的输出可被读取
+	Flush() error
+}
+
+// NewLogStore 根据配置创建输出存储后端，db 后端返回 nil（输出保存在数据库中）
+func NewLogStore(cfg *config.TaskLogConfig) (LogStore, error) {
+	switch cfg.Backend {
+	case "", domain.LogStorageDB:
+		return nil, nil
+	case domain.LogStorageFile:
+		return NewFileLogStore(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("unsupported log storage backend: %s", cfg.Backend)
+	}
+}
+
+// fileLogStore 本地压缩文件存储：<dir>/<lid前两位>/<lid>.<name>.gz
+type fileLogStore struct {
+	dir string
+}
+
+// NewFileLogStore 创建本地压缩文件存储
+func NewFileLogStore(dir string) (LogStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileLogStore{dir: dir}, nil
+}
+
+// Name 后端名称
+func (s *fileLogStore) Name() string {
+	return domain.LogStorageFile
+}
+
+// Create 创建输出文件写入器（gzip 压缩）
+func (s *fileLogStore) Create(log *domain.TaskLog, name string) (LogStreamWriter, error) {
+	path := s.path(log.Lid, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &gzipFileWriter{Writer: gzip.NewWriter(f), file: f}
+
+	// 立即写入 gzip 头，保证文件创建后即可读取
+	if err := w.Flush(); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Open 打开输出文件（流式解压）
+func (s *fileLogStore) Open(log *domain.TaskLog, name string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(log.Lid, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, apperrors.NotFound("log output")
+		}
+		return nil, err
+	}
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &gzipFileReader{Reader: zr, file: f}, nil
+}
+
+// Delete 删除日志的全部输出文件
+func (s *fileLogStore) Delete(log *domain.TaskLog) error {
+	for _, name := range []string{domain.LogStreamStdout, domain.LogStreamStderr, logLinesFile} {
+		if err := os.Remove(s.path(log.Lid, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// path 输出文件路径
+func (s *fileLogStore) path(lid string, name string) string {
+	shard := lid
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+	return filepath.Join(s.dir, shard, lid+"."+name+".gz")
+}
+
+// gzipFileWriter 关闭时依次关闭 gzip 和文件
+type gzipFileWriter struct {
+	*gzip.Writer
+	file *os.File
+}
+
+// Close 关闭写入器
+func (w *gzipFileWriter) Close() error {
+	if err := w.Writer.Close(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// gzipFileReader 关闭时依次关闭 gzip 和文件
+type gzipFileReader struct {
+	*gzip.Reader
+	file *os.File
+}
+
+// Close 关闭读取器
+func (r *gzipFileReader) Close() error {
+	_ = r.Reader.Close()
+	return r.file.Close()
+}
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// logAbortedText 服务异常退出时中断的日志末尾追加的说明
+const logAbortedText = "... [aborted: the server stopped while the task was running] ..."
+
+// LogWriter 运行中任务的日志输出写入器
+//
+// 写入内容在 Flush 后即可通过 OpenOutput / ReadLines 读取，Close 后日志标记为已结束。
+type LogWriter interface {
+	// Write 按顺序追加一条行记录
+	Write(line *domain.LogLine) error
+	// Flush 持久化已写入的内容
+	Flush() error
+	// Close 结束写入并保存最终元数据
+	Close() error
+}
+
+// Create 创建运行中的日志并返回输出写入器
+func (r *taskLogRepository) Create(log *domain.TaskLog) (LogWriter, error) {
+	log.Format = domain.LogFormatLines
+	log.Running = true
+	log.StdOut = ""
+	log.StdErr = ""
+	log.Records = ""
+
+	if r.store == nil {
+		log.Storage = domain.LogStorageDB
+		if err := r.db.Create(log).Error; err != nil {
+			return nil, apperrors.Database(err)
+		}
+		return &dbLogWriter{db: r.db, log: log, index: lineIndexer{db: r.db, lid: log.Lid}}, nil
+	}
+
+	sw, err := r.store.Create(log, logLinesFile)
+	if err != nil {
+		return nil, apperrors.Storage(err)
+	}
+
+	log.Storage = r.store.Name()
+	if err := r.db.Create(log).Error; err != nil {
+		_ = sw.Close()
+		_ = r.store.Delete(log)
+		return nil, apperrors.Database(err)
+	}
+	return &storeLogWriter{db: r.db, log: log, w: sw, index: lineIndexer{db: r.db, lid: log.Lid}}, nil
+}
+
+// RecoverRunning 结束服务异常退出时仍标记为运行中的日志，返回处理条数
+//
+// 按已落盘的行记录重新统计大小，末尾追加中断说明后重写输出；日志标记为失败和已截断，
+// 全文索引由 Reindex 重建。应在启动执行器和日志清理之前调用。
+func (r *taskLogRepository) RecoverRunning() (int, error) {
+	var lids []string
+	if err := r.db.Model(&domain.TaskLog{}).Where("running = ?", true).Pluck("lid", &lids).Error; err != nil {
+		return 0, apperrors.Database(err)
+	}
+
+	recovered := 0
+	for _, lid := range lids {
+		if err := r.recoverLog(lid); err != nil {
+			if apperrors.IsNotFound(err) {
+				continue
+			}
+			return recovered, err
+		}
+		recovered++
+	}
+	return recovered, nil
+}
+
+// recoverLog 结束单条中断的日志
+func (r *taskLogRepository) recoverLog(lid string) error {
+	log, err := r.getWithOutput(lid)
+	if err != nil {
+		return err
+	}
+
+	// 输出文件可能尚未创建或已丢失，此时只保留中断说明
+	var lines []*domain.LogLine
+	err = r.scanLines(log, func(line *domain.LogLine) bool {
+		lines = append(lines, line)
+		return true
+	})
+	if err != nil && !apperrors.IsNotFound(err) {
+		return err
+	}
+
+	abort := &domain.LogLine{No: 1, Ts: log.StartAt, Stream: domain.LogStreamMeta, Text: logAbortedText}
+	if len(lines) > 0 {
+		last := lines[len(lines)-1]
+		abort.No, abort.Ts = last.No+1, last.Ts
+	}
+	lines = append(lines, abort)
+
+	log.LineCount, log.OutSize, log.ErrSize = 0, 0, 0
+	var records bytes.Buffer
+	for _, line := range lines {
+		records.Write(encodeLogLine(line))
+		countLine(log, line)
+	}
+
+	extra := map[string]interface{}{
+		"running":   false,
+		"truncated": true,
+		"indexed":   false,
+		"status":    domain.StatusFailure,
+	}
+	if log.Storage == domain.LogStorageDB {
+		extra["records"] = records.String()
+	} else {
+		w, err := r.store.Create(log, logLinesFile)
+		if err != nil {
+			return apperrors.Storage(err)
+		}
+		if _, err := w.Write(records.Bytes()); err != nil {
+			_ = w.Close()
+			return apperrors.Storage(err)
+		}
+		if err := w.Close(); err != nil {
+			return apperrors.Storage(err)
+		}
+	}
+	return updateLogMeta(r.db, log, extra)
+}
+
+// lineIndexer 缓存待索引的行记录，随 Flush 批量写入全文索引
+type lineIndexer struct {
+	db      *gorm.DB
+	lid     string
+	pending []*domain.LogLine
+}
+
+// add 追加待索引的行
+func (x *lineIndexer) add(line *domain.LogLine) {
+	x.pending = append(x.pending, line)
+}
+
+// flush 写入全文索引
+func (x *lineIndexer) flush() error {
+	if len(x.pending) == 0 {
+		return nil
+	}
+	if err := indexLogLines(x.db, x.lid, x.pending); err != nil {
+		return err
+	}
+	x.pending = x.pending[:0]
+	return nil
+}
+
+// countLine 累计行记录数和各输出流字节数
+func countLine(log *domain.TaskLog, line *domain.LogLine) {
+	log.LineCount++
+	switch line.Stream {
+	case domain.LogStreamStdout:
+		log.OutSize += int64(len(line.Text))
+	case domain.LogStreamStderr:
+		log.ErrSize += int64(len(line.Text))
+	}
+}
+
+// dbLogWriter 行记录保存在数据库字段中，Flush 时整体更新
+type dbLogWriter struct {
+	db      *gorm.DB
+	log     *domain.TaskLog
+	records bytes.Buffer
+	index   lineIndexer
+}
+
+// Write 追加一条行记录
+func (w *dbLogWriter) Write(line *domain.LogLine) error {
+	w.records.Write(encodeLogLine(line))
+	countLine(w.log, line)
+	w.index.add(line)
+	return nil
+}
+
+// Flush 更新数据库中的行记录
+func (w *dbLogWriter) Flush() error {
+	if err := w.index.flush(); err != nil {
+		return err
+	}
+	return updateLogMeta(w.db, w.log, map[string]interface{}{
+		"records": w.records.String(),
+	})
+}
+
+// Close 保存最终行记录并结束日志
+func (w *dbLogWriter) Close() error {
+	indexErr := w.index.flush()
+
+	w.log.Running = false
+	w.log.Indexed = indexErr == nil
+	if err := updateLogMeta(w.db, w.log, map[string]interface{}{
+		"records":   w.records.String(),
+		"running":   false,
+		"truncated": w.log.Truncated,
+		"indexed":   w.log.Indexed,
+		"status":    w.log.Status,
+	}); err != nil {
+		return err
+	}
+	return indexErr
+}
+
+// storeLogWriter 行记录写入存储后端，数据库只更新元数据
+type storeLogWriter struct {
+	db    *gorm.DB
+	log   *domain.TaskLog
+	w     LogStreamWriter
+	index lineIndexer
+}
+
+// Write 追加一条行记录
+func (w *storeLogWriter) Write(line *domain.LogLine) error {
+	if _, err := w.w.Write(encodeLogLine(line)); err != nil {
+		return apperrors.Storage(err)
+	}
+	countLine(w.log, line)
+	w.index.add(line)
+	return nil
+}
+
+// Flush 行记录落盘并更新元数据
+func (w *storeLogWriter) Flush() error {
+	if err := w.w.Flush(); err != nil {
+		return apperrors.Storage(err)
+	}
+	if err := w.index.flush(); err != nil {
+		return err
+	}
+	return updateLogMeta(w.db, w.log, nil)
+}
+
+// Close 关闭输出文件并结束日志
+func (w *storeLogWriter) Close() error {
+	closeErr := w.w.Close()
+	indexErr := w.index.flush()
+
+	w.log.Running = false
+	w.log.Indexed = indexErr == nil
+	if err := updateLogMeta(w.db, w.log, map[string]interface{}{
+		"running":   false,
+		"truncated": w.log.Truncated,
+		"indexed":   w.log.Indexed,
+		"status":    w.log.Status,
+	}); err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return apperrors.Storage(closeErr)
+	}
+	return indexErr
+}
+
+// updateLogMeta 更新日志大小、行数及附加字段
+func updateLogMeta(db *gorm.DB, log *domain.TaskLog, extra map[string]interface{}) error {
+	values := map[string]interface{}{
+		"out_size":   log.OutSize,
+		"err_size":   log.ErrSize,
+		"line_count": log.LineCount,
+	}
+	for k, v := range extra {
+		values[k] = v
+	}
+
+	if err := db.Model(&domain.TaskLog{}).Where("lid = ?", log.Lid).Updates(values).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// partialReader 读取运行中（或异常中断）的压缩输出，末尾未完成的数据块视为结束
+type partialReader struct {
+	io.ReadCloser
+}
+
+// Read 将 io.ErrUnexpectedEOF 转换为 io.EOF
+func (r *partialReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"clock/internal/domain"
+)
+
+func TestRecoverRunning(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		file    bool
+		written int // 异常退出前已落盘的行数
+	}{
+		{name: "db", written: 3},
+		{name: "db without output"},
+		{name: "file", file: true, written: 3},
+		{name: "file without output", file: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			db := newTestDB(t)
+			var store LogStore
+			if tt.file {
+				var err error
+				if store, err = NewFileLogStore(filepath.Join(dir, "logs")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			repo := NewTaskLogRepository(db, store)
+
+			w, err := repo.Create(&domain.TaskLog{Lid: "running", Tid: 1, Cid: 1, StartAt: 1000})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= tt.written; i++ {
+				if err := w.Write(&domain.LogLine{No: int64(i), Ts: int64(1000 + i), Stream: domain.LogStreamStdout, Text: "out"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Save(&domain.TaskLog{Lid: "finished", Tid: 1, Cid: 1, Status: domain.StatusSuccess}); err != nil {
+				t.Fatal(err)
+			}
+
+			recovered, err := repo.RecoverRunning()
+			if err != nil || recovered != 1 {
+				t.Fatalf("RecoverRunning() = %d, %v, want 1", recovered, err)
+			}
+
+			log, err := repo.GetByID("running")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if log.Running || !log.Truncated || log.Status != domain.StatusFailure {
+				t.Errorf("running=%v truncated=%v status=%d, want finished failure", log.Running, log.Truncated, log.Status)
+			}
+			if log.LineCount != int64(tt.written+1) || log.OutSize != int64(3*tt.written) {
+				t.Errorf("line_count=%d out_size=%d, want %d and %d", log.LineCount, log.OutSize, tt.written+1, 3*tt.written)
+			}
+
+			lines, _, err := repo.ReadLines("running", &LogLineQuery{Limit: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lines) != tt.written+1 {
+				t.Fatalf("got %d lines, want %d", len(lines), tt.written+1)
+			}
+			last := lines[len(lines)-1]
+			if last.Stream != domain.LogStreamMeta || last.Text != logAbortedText || last.No != int64(tt.written+1) {
+				t.Errorf("last line = %+v, want abort meta line %d", last, tt.written+1)
+			}
+
+			if recovered, err := repo.RecoverRunning(); err != nil || recovered != 0 {
+				t.Errorf("second RecoverRunning() = %d, %v, want 0", recovered, err)
+			}
+		})
+	}
+}
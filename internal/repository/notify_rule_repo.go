@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// notifyRuleRepository 通知规则仓储实现
+type notifyRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewNotifyRuleRepository 创建通知规则仓储
+func NewNotifyRuleRepository(db *gorm.DB) NotifyRuleRepository {
+	return &notifyRuleRepository{db: db}
+}
+
+// List 获取通知规则，cid 和 tid 为 0 时不过滤
+func (r *notifyRuleRepository) List(cid int, tid int) ([]*domain.NotifyRule, error) {
+	db := r.db.Model(&domain.NotifyRule{})
+	if cid > 0 {
+		db = db.Where("cid = ?", cid)
+	}
+	if tid > 0 {
+		db = db.Where("tid = ?", tid)
+	}
+
+	var rules []*domain.NotifyRule
+	if err := db.Order("id asc").Find(&rules).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return rules, nil
+}
+
+// GetByID 根据ID获取通知规则
+func (r *notifyRuleRepository) GetByID(id int) (*domain.NotifyRule, error) {
+	var rule domain.NotifyRule
+	if err := r.db.Where("id = ?", id).First(&rule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("notify rule")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &rule, nil
+}
+
+// Save 保存通知规则
+func (r *notifyRuleRepository) Save(rule *domain.NotifyRule) error {
+	rule.UpdateAt = time.Now().Unix()
+	if err := r.db.Save(rule).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Delete 删除通知规则
+func (r *notifyRuleRepository) Delete(id int) error {
+	if err := r.db.Where("id = ?", id).Delete(&domain.NotifyRule{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// FindForTask 获取任务结束时生效的规则：任务规则和范围为 task 的容器规则
+func (r *notifyRuleRepository) FindForTask(tid int, cid int) ([]*domain.NotifyRule, error) {
+	var rules []*domain.NotifyRule
+	if err := r.db.Where("disable = ?", false).
+		Where("tid = ? OR (tid = 0 AND cid = ? AND scope = ?)", tid, cid, domain.NotifyScopeTask).
+		Order("id asc").
+		Find(&rules).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return rules, nil
+}
+
+// FindForRun 获取批次结束时生效的容器规则
+func (r *notifyRuleRepository) FindForRun(cid int) ([]*domain.NotifyRule, error) {
+	var rules []*domain.NotifyRule
+	if err := r.db.Where("disable = ? AND tid = 0 AND cid = ? AND scope = ?", false, cid, domain.NotifyScopeRun).
+		Order("id asc").
+		Find(&rules).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return rules, nil
+}
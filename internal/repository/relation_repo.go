@@ -19,6 +19,18 @@ func NewRelationRepository(db *gorm.DB) RelationRepository {
 	return &relationRepository{db: db}
 }
 
+// GetByID 根据ID获取关系
+func (r *relationRepository) GetByID(rid int) (*domain.Relation, error) {
+	var relation domain.Relation
+	if err := r.db.Where("rid = ?", rid).First(&relation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("relation")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &relation, nil
+}
+
 // GetByCID 根据容器ID获取关系列表
 func (r *relationRepository) GetByCID(cid int) ([]*domain.Relation, error) {
 	var relations []*domain.Relation
@@ -39,7 +51,7 @@ func (r *relationRepository) Save(relation *domain.Relation) error {
 
 // Delete 删除关系
 func (r *relationRepository) Delete(rid int) error {
-	if err := r.db.Where("rid = ?", rid).Delete(&domain.Relation{}).Error; err != nil {
+	if err := r.db.Unscoped().Where("rid = ?", rid).Delete(&domain.Relation{}).Error; err != nil {
 		return apperrors.Database(err)
 	}
 	return nil
@@ -47,7 +59,7 @@ func (r *relationRepository) Delete(rid int) error {
 
 // DeleteByTID 根据任务ID删除关系
 func (r *relationRepository) DeleteByTID(tid int) error {
-	if err := r.db.Where("tid = ?", tid).Delete(&domain.Relation{}).Error; err != nil {
+	if err := r.db.Unscoped().Where("tid = ?", tid).Delete(&domain.Relation{}).Error; err != nil {
 		return apperrors.Database(err)
 	}
 	return nil
@@ -55,8 +67,69 @@ func (r *relationRepository) DeleteByTID(tid int) error {
 
 // DeleteByNextTID 根据后续任务ID删除关系
 func (r *relationRepository) DeleteByNextTID(nextTid int) error {
-	if err := r.db.Where("next_tid = ?", nextTid).Delete(&domain.Relation{}).Error; err != nil {
+	if err := r.db.Unscoped().Where("next_tid = ?", nextTid).Delete(&domain.Relation{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// DeleteByCID 根据容器ID删除关系
+func (r *relationRepository) DeleteByCID(cid int) error {
+	if err := r.db.Unscoped().Where("cid = ?", cid).Delete(&domain.Relation{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// DeleteByGID 删除以任务组为端点的关系
+func (r *relationRepository) DeleteByGID(gid int) error {
+	if err := r.db.Unscoped().Where("gid = ? OR next_gid = ?", gid, gid).Delete(&domain.Relation{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// SoftDeleteByTID 将任务相关的关系（作为前置或后续任务）移入回收站
+func (r *relationRepository) SoftDeleteByTID(tid int, at time.Time) error {
+	if err := r.db.Model(&domain.Relation{}).Where("tid = ? OR next_tid = ?", tid, tid).
+		Update("deleted_at", at).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// SoftDeleteByCID 将容器下所有关系移入回收站
+func (r *relationRepository) SoftDeleteByCID(cid int, at time.Time) error {
+	if err := r.db.Model(&domain.Relation{}).Where("cid = ?", cid).
+		Update("deleted_at", at).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// RestoreByTID 恢复与任务同时删除的关系（两端任务均存在时才恢复）
+func (r *relationRepository) RestoreByTID(tid int, at time.Time) error {
+	if err := r.restoreScope().
+		Where("(tid = ? OR next_tid = ?) AND deleted_at = ?", tid, tid, at).
+		Update("deleted_at", nil).Error; err != nil {
 		return apperrors.Database(err)
 	}
 	return nil
 }
+
+// RestoreByCID 恢复与容器同时删除的关系（两端任务均存在时才恢复）
+func (r *relationRepository) RestoreByCID(cid int, at time.Time) error {
+	if err := r.restoreScope().
+		Where("cid = ? AND deleted_at = ?", cid, at).
+		Update("deleted_at", nil).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// restoreScope 限定两端任务均未删除的关系（任务组端点不受限制）
+func (r *relationRepository) restoreScope() *gorm.DB {
+	live := r.db.Model(&domain.Task{}).Select("tid")
+	return r.db.Unscoped().Model(&domain.Relation{}).
+		Where("(gid <> 0 OR tid IN (?)) AND (next_gid <> 0 OR next_tid IN (?))", live, live)
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"io"
+	"time"
+
 	"clock/internal/domain"
 )
 
@@ -17,57 +20,256 @@ type Page struct {
 // TaskQuery 任务查询参数
 type TaskQuery struct {
 	Page
-	Cid  int    `json:"cid"`
-	Name string `json:"name"`
+	Cid     int    `json:"cid"`
+	Cids    []int  `json:"cids"` // 只查询这些容器的任务，nil 不限
+	Name    string `json:"name"`
+	Trashed bool   `json:"trashed"` // 仅查询回收站中的任务
 }
 
 // ContainerQuery 容器查询参数
 type ContainerQuery struct {
 	Page
-	Name string `json:"name"`
+	Cids    []int  `json:"cids"` // 只查询这些容器，nil 不限
+	Name    string `json:"name"`
+	Trashed bool   `json:"trashed"` // 仅查询回收站中的容器
+}
+
+// DeliveryQuery webhook 投递记录查询参数
+type DeliveryQuery struct {
+	Page
+	WebhookID int    `json:"webhook_id"`
+	Status    string `json:"status"`
+}
+
+// SlaMissQuery SLA 未达成记录查询参数
+type SlaMissQuery struct {
+	Page
+	Cid  int   `json:"cid"`
+	Cids []int `json:"cids"` // 只查询这些容器的记录，nil 不限
+	Tid  int   `json:"tid"`
+}
+
+// AuditQuery 审计记录查询参数（LeftTs、RightTs 为操作时间范围，单位秒）
+type AuditQuery struct {
+	Page
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
 }
 
 // LogQuery 日志查询参数
 type LogQuery struct {
 	Page
-	Tid int `json:"tid"`
-	Cid int `json:"cid"`
+	Tid    int    `json:"tid"`
+	Cid    int    `json:"cid"`
+	Cids   []int  `json:"cids"`   // 只查询这些容器的日志，nil 不限
+	Search string `json:"search"` // 全文搜索：空格分隔的词（AND），"短语"，前缀 word*
+}
+
+// LogCleanupQuery 日志清理候选查询（只返回已结束的日志）
+type LogCleanupQuery struct {
+	Tid    int   // 任务ID，0 不限
+	Cid    int   // 容器ID，0 不限
+	Before int64 // 创建时间早于该时间戳（秒），0 不限
+	Failed *bool // true 只查失败日志，false 只查非失败日志，nil 不限
+	Offset int   // 跳过的条数（按时间倒序时用于保留最新 N 条）
+	Limit  int   // 返回条数，0 不限
+	Oldest bool  // 按时间正序返回
+}
+
+// LogLineQuery 日志行查询参数
+type LogLineQuery struct {
+	Stream string // 只返回指定输出流，为空返回全部
+	From   int64  // 起始行号（含）
+	To     int64  // 结束行号（含），0 表示不限
+	Limit  int    // 最多返回行数
 }
 
 // TaskRepository 任务仓储接口
 type TaskRepository interface {
 	GetByID(tid int) (*domain.Task, error)
+	GetByIDUnscoped(tid int) (*domain.Task, error)
 	List(query *TaskQuery) ([]*domain.Task, error)
 	GetByCID(cid int) ([]*domain.Task, error)
 	Save(task *domain.Task) error
+	UpdateStatus(task *domain.Task) error
 	Delete(tid int) error
 	DeleteByCID(cid int) error
+	MoveGroup(gid int, newGid int) error
+	SoftDelete(tid int, at time.Time) error
+	SoftDeleteByCID(cid int, at time.Time) error
+	Restore(tid int) error
+	RestoreByCID(cid int, at time.Time) error
 	UpdateCoordinates(tid int, x, y int) error
 }
 
 // ContainerRepository 容器仓储接口
 type ContainerRepository interface {
 	GetByID(cid int) (*domain.Container, error)
+	GetByIDUnscoped(cid int) (*domain.Container, error)
 	List(query *ContainerQuery) ([]*domain.Container, error)
 	FindAll() ([]*domain.Container, error)
 	Save(container *domain.Container) error
+	UpdateStatus(container *domain.Container) error
 	Delete(cid int) error
+	SoftDelete(cid int, at time.Time) error
+	Restore(cid int) error
 }
 
 // RelationRepository 关系仓储接口
 type RelationRepository interface {
+	GetByID(rid int) (*domain.Relation, error)
 	GetByCID(cid int) ([]*domain.Relation, error)
 	Save(relation *domain.Relation) error
 	Delete(rid int) error
 	DeleteByTID(tid int) error
 	DeleteByNextTID(nextTid int) error
+	DeleteByCID(cid int) error
+	DeleteByGID(gid int) error
+	SoftDeleteByTID(tid int, at time.Time) error
+	SoftDeleteByCID(cid int, at time.Time) error
+	RestoreByTID(tid int, at time.Time) error
+	RestoreByCID(cid int, at time.Time) error
 }
 
 // TaskLogRepository 任务日志仓储接口
 type TaskLogRepository interface {
 	List(query *LogQuery) ([]*domain.TaskLog, error)
+	GetByID(lid string) (*domain.TaskLog, error)
 	Save(log *domain.TaskLog) error
+	Create(log *domain.TaskLog) (LogWriter, error)
+	OpenOutput(lid string, stream string) (io.ReadCloser, error)
+	FindByRunID(runID string) ([]*domain.TaskLog, error)
+	ReadLines(lid string, query *LogLineQuery) ([]*domain.LogLine, bool, error)
+	MigrateOutput(batchSize int) (int, error)
+	Reindex(batchSize int) (int, error)
+	RecoverRunning() (int, error)
+	Tids() ([]int, error)
+	TotalSize(query *LogCleanupQuery) (int64, error)
+	FindForCleanup(query *LogCleanupQuery) ([]*domain.TaskLog, error)
+	DeleteByIDs(lids []string) error
 	DeleteByID(lid string) error
 	DeleteByTimeRange(query *LogQuery) error
 	DeleteAll() error
 }
+
+// TaskRunRepository 任务执行记录仓储接口
+type TaskRunRepository interface {
+	Save(run *domain.TaskRun) error
+	ListRecentByTID(tid int, limit int) ([]*domain.TaskRun, error)
+	ListRecentByTIDs(tids []int, limit int) (map[int][]*domain.TaskRun, error)
+	ListByRunID(runID string) ([]*domain.TaskRun, error)
+	ListBetween(from int64, to int64) ([]*domain.TaskRun, error)
+}
+
+// RunEventRepository 批次事件日志仓储接口
+type RunEventRepository interface {
+	SaveBatch(events []*domain.RunEvent) error
+	ListByRunID(runID string, afterID int64) ([]*domain.RunEvent, error)
+	DeleteBefore(ts int64) (int64, error)
+}
+
+// NotifyRuleRepository 通知规则仓储接口
+type NotifyRuleRepository interface {
+	List(cid int, tid int) ([]*domain.NotifyRule, error)
+	GetByID(id int) (*domain.NotifyRule, error)
+	Save(rule *domain.NotifyRule) error
+	Delete(id int) error
+	FindForTask(tid int, cid int) ([]*domain.NotifyRule, error)
+	FindForRun(cid int) ([]*domain.NotifyRule, error)
+}
+
+// WebhookRepository webhook 订阅仓储接口
+type WebhookRepository interface {
+	List() ([]*domain.Webhook, error)
+	GetByID(id int) (*domain.Webhook, error)
+	Save(webhook *domain.Webhook) error
+	Delete(id int) error
+}
+
+// WebhookDeliveryRepository webhook 投递记录仓储接口
+type WebhookDeliveryRepository interface {
+	List(query *DeliveryQuery) ([]*domain.WebhookDelivery, error)
+	GetByID(id string) (*domain.WebhookDelivery, error)
+	SaveBatch(deliveries []*domain.WebhookDelivery) error
+	Save(delivery *domain.WebhookDelivery) error
+	FindDue(webhookID int, now int64, limit int) ([]*domain.WebhookDelivery, error)
+	DeleteByWebhookID(webhookID int) error
+	DeleteBefore(ts int64) (int64, error)
+}
+
+// SlaMissRepository SLA 未达成记录仓储接口
+type SlaMissRepository interface {
+	Save(miss *domain.SlaMiss) error
+	List(query *SlaMissQuery) ([]*domain.SlaMiss, error)
+	ListBetween(from int64, to int64) ([]*domain.SlaMiss, error)
+}
+
+// TaskGroupRepository 任务组仓储接口
+type TaskGroupRepository interface {
+	GetByID(gid int) (*domain.TaskGroup, error)
+	GetByCID(cid int) ([]*domain.TaskGroup, error)
+	Save(group *domain.TaskGroup) error
+	Delete(gid int) error
+	DeleteByCID(cid int) error
+	Reparent(parentGid int, newParentGid int) error
+}
+
+// Repositories 仓储集合（用于事务内访问）
+type Repositories struct {
+	Task      TaskRepository
+	Container ContainerRepository
+	Relation  RelationRepository
+	TaskLog   TaskLogRepository
+	TaskGroup TaskGroupRepository
+}
+
+// Transactor 事务管理接口
+type Transactor interface {
+	// Transaction 在同一事务中执行 fn，fn 返回错误时回滚
+	Transaction(fn func(repos *Repositories) error) error
+}
+
+// UserRepository 用户仓储接口
+type UserRepository interface {
+	List() ([]*domain.User, error)
+	GetByID(id int) (*domain.User, error)
+	GetByName(name string) (*domain.User, error)
+	Save(user *domain.User) error
+	Count(enabledOnly bool) (int64, error)
+}
+
+// RoleBindingQuery 角色授权查询参数
+type RoleBindingQuery struct {
+	UserID int         // 用户ID，0 不限
+	Cid    *int        // 容器ID（0 表示全局授权），nil 不限
+	Role   domain.Role // 角色，为空不限
+}
+
+// RoleBindingRepository 角色授权仓储接口
+type RoleBindingRepository interface {
+	List(query *RoleBindingQuery) ([]*domain.RoleBinding, error)
+	GetByID(id int) (*domain.RoleBinding, error)
+	Find(userID int, cid int) (*domain.RoleBinding, error)
+	Save(binding *domain.RoleBinding) error
+	Delete(id int) error
+	Count() (int64, error)
+}
+
+// APITokenRepository API 令牌仓储接口
+type APITokenRepository interface {
+	List(userID int) ([]*domain.APIToken, error)
+	GetByID(id int) (*domain.APIToken, error)
+	GetByHash(hash string) (*domain.APIToken, error)
+	Save(token *domain.APIToken) error
+	UpdateLastUsed(id int, at int64) error
+	Delete(id int) error
+}
+
+// AuditLogRepository 审计记录仓储接口
+type AuditLogRepository interface {
+	Save(entry *domain.AuditLog) error
+	List(query *AuditQuery) ([]*domain.AuditLog, error)
+	DeleteBefore(ts int64) (int64, error)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// roleBindingRepository 角色授权仓储实现
+type roleBindingRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleBindingRepository 创建角色授权仓储
+func NewRoleBindingRepository(db *gorm.DB) RoleBindingRepository {
+	return &roleBindingRepository{db: db}
+}
+
+// List 查询授权，按用户和容器排序
+func (r *roleBindingRepository) List(query *RoleBindingQuery) ([]*domain.RoleBinding, error) {
+	db := r.db.Model(&domain.RoleBinding{})
+	if query.UserID > 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.Cid != nil {
+		db = db.Where("cid = ?", *query.Cid)
+	}
+	if query.Role != "" {
+		db = db.Where("role = ?", query.Role)
+	}
+
+	var bindings []*domain.RoleBinding
+	if err := db.Order("user_id asc, cid asc").Find(&bindings).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return bindings, nil
+}
+
+// GetByID 根据ID获取授权
+func (r *roleBindingRepository) GetByID(id int) (*domain.RoleBinding, error) {
+	var binding domain.RoleBinding
+	if err := r.db.Where("id = ?", id).First(&binding).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("role binding")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &binding, nil
+}
+
+// Find 获取用户在容器上的授权
+func (r *roleBindingRepository) Find(userID int, cid int) (*domain.RoleBinding, error) {
+	var binding domain.RoleBinding
+	if err := r.db.Where("user_id = ? AND cid = ?", userID, cid).First(&binding).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("role binding")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &binding, nil
+}
+
+// Save 保存授权
+func (r *roleBindingRepository) Save(binding *domain.RoleBinding) error {
+	if binding.CreateAt == 0 {
+		binding.CreateAt = time.Now().Unix()
+	}
+	if err := r.db.Save(binding).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Delete 删除授权
+func (r *roleBindingRepository) Delete(id int) error {
+	if err := r.db.Where("id = ?", id).Delete(&domain.RoleBinding{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Count 统计授权总数
+func (r *roleBindingRepository) Count() (int64, error) {
+	var count int64
+	if err := r.db.Model(&domain.RoleBinding{}).Count(&count).Error; err != nil {
+		return 0, apperrors.Database(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// runEventRepository 批次事件日志仓储实现
+type runEventRepository struct {
+	db *gorm.DB
+}
+
+// NewRunEventRepository 创建批次事件日志仓储
+func NewRunEventRepository(db *gorm.DB) RunEventRepository {
+	return &runEventRepository{db: db}
+}
+
+// SaveBatch 批量保存事件
+func (r *runEventRepository) SaveBatch(events []*domain.RunEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := r.db.CreateInBatches(events, 500).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// ListByRunID 按发生顺序获取批次的事件，afterID 之前（含）的事件不返回
+func (r *runEventRepository) ListByRunID(runID string, afterID int64) ([]*domain.RunEvent, error) {
+	var events []*domain.RunEvent
+	if err := r.db.Where("run_id = ? AND id > ?", runID, afterID).Order("id asc").Find(&events).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return events, nil
+}
+
+// DeleteBefore 删除指定时间（毫秒）之前的事件
+func (r *runEventRepository) DeleteBefore(ts int64) (int64, error) {
+	result := r.db.Where("ts < ?", ts).Delete(&domain.RunEvent{})
+	if result.Error != nil {
+		return 0, apperrors.Database(result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// slaMissRepository SLA 未达成记录仓储实现
+type slaMissRepository struct {
+	db *gorm.DB
+}
+
+// NewSlaMissRepository 创建 SLA 未达成记录仓储
+func NewSlaMissRepository(db *gorm.DB) SlaMissRepository {
+	return &slaMissRepository{db: db}
+}
+
+// Save 保存记录
+func (r *slaMissRepository) Save(miss *domain.SlaMiss) error {
+	if miss.CreateAt == 0 {
+		miss.CreateAt = time.Now().Unix()
+	}
+	if err := r.db.Save(miss).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// List 按发现时间倒序查询记录
+func (r *slaMissRepository) List(query *SlaMissQuery) ([]*domain.SlaMiss, error) {
+	var misses []*domain.SlaMiss
+
+	// 设置默认值
+	if query.Count < 1 {
+		query.Count = 10
+	}
+	if query.Index < 1 {
+		query.Index = 1
+	}
+
+	db := r.db.Model(&domain.SlaMiss{})
+
+	// 条件过滤
+	if query.Cid > 0 {
+		db = db.Where("cid = ?", query.Cid)
+	}
+	if query.Cids != nil {
+		db = db.Where("cid IN ?", query.Cids)
+	}
+	if query.Tid > 0 {
+		db = db.Where("tid = ?", query.Tid)
+	}
+
+	// 统计总数
+	if err := db.Count(&query.Total).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+
+	db = db.Offset((query.Index - 1) * query.Count).Limit(query.Count).Order("create_at desc, id desc")
+	if err := db.Find(&misses).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return misses, nil
+}
+
+// ListBetween 获取在 [from, to) 内发现的记录（秒，按发现时间正序）
+func (r *slaMissRepository) ListBetween(from int64, to int64) ([]*domain.SlaMiss, error) {
+	var misses []*domain.SlaMiss
+	if err := r.db.Where("create_at >= ? AND create_at < ?", from, to).Order("create_at asc, id asc").Find(&misses).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return misses, nil
+}
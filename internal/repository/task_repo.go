@@ -31,6 +31,18 @@ func (r *taskRepository) GetByID(tid int) (*domain.Task, error) {
 	return &task, nil
 }
 
+// GetByIDUnscoped 根据ID获取任务（包括回收站中的任务）
+func (r *taskRepository) GetByIDUnscoped(tid int) (*domain.Task, error) {
+	var task domain.Task
+	if err := r.db.Unscoped().Where("tid = ?", tid).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("task")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &task, nil
+}
+
 // List 查询任务列表
 func (r *taskRepository) List(query *TaskQuery) ([]*domain.Task, error) {
 	var tasks []*domain.Task
@@ -46,9 +58,15 @@ func (r *taskRepository) List(query *TaskQuery) ([]*domain.Task, error) {
 	db := r.db.Model(&domain.Task{})
 
 	// 条件过滤
+	if query.Trashed {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
 	if query.Cid > 0 {
 		db = db.Where("cid = ?", query.Cid)
 	}
+	if query.Cids != nil {
+		db = db.Where("cid IN ?", query.Cids)
+	}
 	if query.Name != "" {
 		db = db.Where("name LIKE ?", "%"+query.Name+"%")
 	}
@@ -89,17 +107,79 @@ func (r *taskRepository) Save(task *domain.Task) error {
 	return nil
 }
 
-// Delete 删除任务
+// UpdateStatus 仅更新任务状态和耗时（已删除的任务不受影响）
+func (r *taskRepository) UpdateStatus(task *domain.Task) error {
+	task.UpdateAt = time.Now().Unix()
+	if err := r.db.Model(&domain.Task{}).Where("tid = ?", task.Tid).
+		Updates(map[string]interface{}{
+			"status":    task.Status,
+			"duration":  task.Duration,
+			"update_at": task.UpdateAt,
+		}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Delete 彻底删除任务
 func (r *taskRepository) Delete(tid int) error {
-	if err := r.db.Where("tid = ?", tid).Delete(&domain.Task{}).Error; err != nil {
+	if err := r.db.Unscoped().Where("tid = ?", tid).Delete(&domain.Task{}).Error; err != nil {
 		return apperrors.Database(err)
 	}
 	return nil
 }
 
-// DeleteByCID 根据容器ID删除任务
+// DeleteByCID 根据容器ID彻底删除任务
 func (r *taskRepository) DeleteByCID(cid int) error {
-	if err := r.db.Where("cid = ?", cid).Delete(&domain.Task{}).Error; err != nil {
+	if err := r.db.Unscoped().Where("cid = ?", cid).Delete(&domain.Task{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// MoveGroup 将任务组内的任务移动到另一个任务组
+func (r *taskRepository) MoveGroup(gid int, newGid int) error {
+	if err := r.db.Unscoped().Model(&domain.Task{}).Where("gid = ?", gid).
+		Updates(map[string]interface{}{
+			"gid":       newGid,
+			"update_at": time.Now().Unix(),
+		}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// SoftDelete 将任务移入回收站
+func (r *taskRepository) SoftDelete(tid int, at time.Time) error {
+	if err := r.db.Model(&domain.Task{}).Where("tid = ?", tid).
+		Update("deleted_at", at).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// SoftDeleteByCID 将容器下所有任务移入回收站
+func (r *taskRepository) SoftDeleteByCID(cid int, at time.Time) error {
+	if err := r.db.Model(&domain.Task{}).Where("cid = ?", cid).
+		Update("deleted_at", at).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Restore 从回收站恢复任务
+func (r *taskRepository) Restore(tid int) error {
+	if err := r.db.Unscoped().Model(&domain.Task{}).Where("tid = ?", tid).
+		Update("deleted_at", nil).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// RestoreByCID 恢复容器下与容器同时删除的任务
+func (r *taskRepository) RestoreByCID(cid int, at time.Time) error {
+	if err := r.db.Unscoped().Model(&domain.Task{}).Where("cid = ? AND deleted_at = ?", cid, at).
+		Update("deleted_at", nil).Error; err != nil {
 		return apperrors.Database(err)
 	}
 	return nil
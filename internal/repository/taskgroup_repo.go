@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// taskGroupRepository 任务组仓储实现
+type taskGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskGroupRepository 创建任务组仓储
+func NewTaskGroupRepository(db *gorm.DB) TaskGroupRepository {
+	return &taskGroupRepository{db: db}
+}
+
+// GetByID 根据ID获取任务组
+func (r *taskGroupRepository) GetByID(gid int) (*domain.TaskGroup, error) {
+	var group domain.TaskGroup
+	if err := r.db.Where("gid = ?", gid).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("task group")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &group, nil
+}
+
+// GetByCID 根据容器ID获取任务组列表
+func (r *taskGroupRepository) GetByCID(cid int) ([]*domain.TaskGroup, error) {
+	var groups []*domain.TaskGroup
+	if err := r.db.Where("cid = ?", cid).Find(&groups).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return groups, nil
+}
+
+// Save 保存任务组
+func (r *taskGroupRepository) Save(group *domain.TaskGroup) error {
+	group.UpdateAt = time.Now().Unix()
+	if err := r.db.Save(group).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Delete 删除任务组
+func (r *taskGroupRepository) Delete(gid int) error {
+	if err := r.db.Where("gid = ?", gid).Delete(&domain.TaskGroup{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// DeleteByCID 根据容器ID删除任务组
+func (r *taskGroupRepository) DeleteByCID(cid int) error {
+	if err := r.db.Where("cid = ?", cid).Delete(&domain.TaskGroup{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Reparent 将子任务组移动到新的父任务组
+func (r *taskGroupRepository) Reparent(parentGid int, newParentGid int) error {
+	if err := r.db.Model(&domain.TaskGroup{}).Where("parent_gid = ?", parentGid).
+		Updates(map[string]interface{}{
+			"parent_gid": newParentGid,
+			"update_at":  time.Now().Unix(),
+		}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
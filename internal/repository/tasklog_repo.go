@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"io"
+	"math"
+
 	"gorm.io/gorm"
 
 	"clock/internal/domain"
@@ -9,12 +12,16 @@ import (
 
 // taskLogRepository 任务日志仓储实现
 type taskLogRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	store LogStore // 输出存储后端，为 nil 时输出保存在数据库中
+
+	// removed 事务中删除的日志，输出在事务提交后清理；为 nil 时删除元数据后立即清理
+	removed *[]*domain.TaskLog
 }
 
 // NewTaskLogRepository 创建任务日志仓储
-func NewTaskLogRepository(db *gorm.DB) TaskLogRepository {
-	return &taskLogRepository{db: db}
+func NewTaskLogRepository(db *gorm.DB, store LogStore) TaskLogRepository {
+	return &taskLogRepository{db: db, store: store}
 }
 
 // List 查询日志列表
@@ -38,6 +45,9 @@ func (r *taskLogRepository) List(query *LogQuery) ([]*domain.TaskLog, error) {
 	if query.Cid > 0 {
 		db = db.Where("cid = ?", query.Cid)
 	}
+	if query.Cids != nil {
+		db = db.Where("cid IN ?", query.Cids)
+	}
 	if query.LeftTs > 0 {
 		db = db.Where("update_at > ?", query.LeftTs)
 	}
@@ -45,6 +55,18 @@ func (r *taskLogRepository) List(query *LogQuery) ([]*domain.TaskLog, error) {
 		db = db.Where("update_at < ?", query.RightTs)
 	}
 
+	// 全文搜索：先在行索引中匹配，再按日志过滤
+	var terms []searchTerm
+	if query.Search != "" {
+		var err error
+		if terms, err = parseSearch(query.Search); err != nil {
+			return nil, err
+		}
+		dialect := r.db.Dialector.Name()
+		matched := r.db.Table(logLinesTable).Select("lid").Where(matchCondition(dialect), buildMatch(dialect, terms))
+		db = db.Where("lid IN (?)", matched)
+	}
+
 	// 统计总数
 	if err := db.Count(&query.Total).Error; err != nil {
 		return nil, apperrors.Database(err)
@@ -53,24 +75,187 @@ func (r *taskLogRepository) List(query *LogQuery) ([]*domain.TaskLog, error) {
 	// 分页和排序（默认按时间倒序）
 	db = db.Offset((query.Index - 1) * query.Count).Limit(query.Count).Order("update_at desc")
 
-	if err := db.Find(&logs).Error; err != nil {
+	// 列表不返回输出内容，输出通过 OpenOutput 流式读取
+	if err := db.Omit("std_out", "std_err", "records").Find(&logs).Error; err != nil {
 		return nil, apperrors.Database(err)
 	}
 
+	if len(terms) > 0 {
+		for _, log := range logs {
+			matches, err := searchMatches(r.db, log.Lid, terms)
+			if err != nil {
+				return nil, err
+			}
+			log.Matches = matches
+		}
+	}
+
 	return logs, nil
 }
 
-// Save 保存日志
+// GetByID 根据日志ID获取日志元数据
+func (r *taskLogRepository) GetByID(lid string) (*domain.TaskLog, error) {
+	var log domain.TaskLog
+	if err := r.db.Omit("std_out", "std_err", "records").First(&log, "lid = ?", lid).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("log")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &log, nil
+}
+
+// Save 保存日志，配置了存储后端时输出写入后端，数据库只保存元数据
 func (r *taskLogRepository) Save(log *domain.TaskLog) error {
+	log.Format = domain.LogFormatBlob
+	log.OutSize = int64(len(log.StdOut))
+	log.ErrSize = int64(len(log.StdErr))
+
+	if r.store == nil {
+		log.Storage = domain.LogStorageDB
+	} else {
+		if err := r.writeOutput(log); err != nil {
+			return err
+		}
+	}
+
 	if err := r.db.Save(log).Error; err != nil {
+		if r.store != nil {
+			_ = r.store.Delete(log)
+		}
 		return apperrors.Database(err)
 	}
 	return nil
 }
 
+// writeOutput 将输出写入存储后端并清空输出字段
+func (r *taskLogRepository) writeOutput(log *domain.TaskLog) error {
+	outputs := map[string]string{
+		domain.LogStreamStdout: log.StdOut,
+		domain.LogStreamStderr: log.StdErr,
+	}
+	for stream, output := range outputs {
+		w, err := r.store.Create(log, stream)
+		if err != nil {
+			return apperrors.Storage(err)
+		}
+		if _, err := io.WriteString(w, output); err != nil {
+			_ = w.Close()
+			_ = r.store.Delete(log)
+			return apperrors.Storage(err)
+		}
+		if err := w.Close(); err != nil {
+			_ = r.store.Delete(log)
+			return apperrors.Storage(err)
+		}
+	}
+
+	log.Storage = r.store.Name()
+	log.StdOut = ""
+	log.StdErr = ""
+	return nil
+}
+
+// OpenOutput 打开日志的某个输出流（stdout, stderr, all）
+func (r *taskLogRepository) OpenOutput(lid string, stream string) (io.ReadCloser, error) {
+	if stream != domain.LogStreamStdout && stream != domain.LogStreamStderr && stream != domain.LogStreamAll {
+		return nil, apperrors.InvalidParam("invalid stream: " + stream)
+	}
+
+	log, err := r.getWithOutput(lid)
+	if err != nil {
+		return nil, err
+	}
+	if log.Format != domain.LogFormatLines && stream != domain.LogStreamAll {
+		return r.openBlob(log, stream)
+	}
+
+	// lines 格式按输出流过滤行记录，all 返回全部行（blob 格式依次为标准输出和标准错误）
+	pr, pw := io.Pipe()
+	go func() {
+		var writeErr error
+		scanErr := r.scanLines(log, func(line *domain.LogLine) bool {
+			if stream != domain.LogStreamAll && line.Stream != stream {
+				return true
+			}
+			_, writeErr = io.WriteString(pw, line.Text+"\n")
+			return writeErr == nil
+		})
+		if scanErr != nil {
+			writeErr = scanErr
+		}
+		_ = pw.CloseWithError(writeErr)
+	}()
+	return pr, nil
+}
+
+// FindByRunID 查询批次内的所有日志元数据（按开始时间排序）
+func (r *taskLogRepository) FindByRunID(runID string) ([]*domain.TaskLog, error) {
+	var logs []*domain.TaskLog
+	if err := r.db.Omit("std_out", "std_err", "records").
+		Where("run_id = ?", runID).
+		Order("update_at asc, start_at asc").
+		Find(&logs).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return logs, nil
+}
+
+// getWithOutput 获取包含输出字段的完整日志
+func (r *taskLogRepository) getWithOutput(lid string) (*domain.TaskLog, error) {
+	var log domain.TaskLog
+	if err := r.db.First(&log, "lid = ?", lid).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("log")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &log, nil
+}
+
+// MigrateOutput 将保存在数据库中的输出迁移到存储后端，返回迁移条数
+func (r *taskLogRepository) MigrateOutput(batchSize int) (int, error) {
+	if r.store == nil {
+		return 0, apperrors.InvalidParam("log storage backend is db, nothing to migrate")
+	}
+
+	var logs []*domain.TaskLog
+	migrated := 0
+	result := r.db.Where("storage = ? OR storage = ? OR storage IS NULL", "", domain.LogStorageDB).
+		FindInBatches(&logs, batchSize, func(tx *gorm.DB, batch int) error {
+			for _, log := range logs {
+				log.OutSize = int64(len(log.StdOut))
+				log.ErrSize = int64(len(log.StdErr))
+				if err := r.writeOutput(log); err != nil {
+					return err
+				}
+				if err := r.db.Model(&domain.TaskLog{}).Where("lid = ?", log.Lid).Updates(map[string]interface{}{
+					"storage":  log.Storage,
+					"format":   domain.LogFormatBlob,
+					"out_size": log.OutSize,
+					"err_size": log.ErrSize,
+					"std_out":  "",
+					"std_err":  "",
+				}).Error; err != nil {
+					_ = r.store.Delete(log)
+					return err
+				}
+				migrated++
+			}
+			return nil
+		})
+	if result.Error != nil {
+		if _, ok := result.Error.(*apperrors.AppError); ok {
+			return migrated, result.Error
+		}
+		return migrated, apperrors.Database(result.Error)
+	}
+	return migrated, nil
+}
+
 // DeleteByTimeRange 根据时间范围删除日志
 func (r *taskLogRepository) DeleteByTimeRange(query *LogQuery) error {
-	db := r.db
+	db := r.db.Model(&domain.TaskLog{})
 
 	if query.Tid > 0 {
 		db = db.Where("tid = ?", query.Tid)
@@ -85,24 +270,200 @@ func (r *taskLogRepository) DeleteByTimeRange(query *LogQuery) error {
 		db = db.Where("update_at < ?", query.RightTs)
 	}
 
-	if err := db.Delete(&domain.TaskLog{}).Error; err != nil {
-		return apperrors.Database(err)
-	}
-	return nil
+	return r.deleteWhere(db)
 }
 
 // DeleteByID 根据日志ID删除单条日志
 func (r *taskLogRepository) DeleteByID(lid string) error {
-	if err := r.db.Delete(&domain.TaskLog{}, "lid = ?", lid).Error; err != nil {
-		return apperrors.Database(err)
-	}
-	return nil
+	return r.deleteWhere(r.db.Model(&domain.TaskLog{}).Where("lid = ?", lid))
 }
 
 // DeleteAll 删除所有日志
 func (r *taskLogRepository) DeleteAll() error {
-	if err := r.db.Where("1 = 1").Delete(&domain.TaskLog{}).Error; err != nil {
+	return r.deleteWhere(r.db.Model(&domain.TaskLog{}).Where("1 = 1"))
+}
+
+// DeleteByIDs 批量删除日志
+func (r *taskLogRepository) DeleteByIDs(lids []string) error {
+	for start := 0; start < len(lids); start += 500 {
+		end := start + 500
+		if end > len(lids) {
+			end = len(lids)
+		}
+		if err := r.deleteWhere(r.db.Model(&domain.TaskLog{}).Where("lid IN ?", lids[start:end])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tids 返回存在日志的任务ID
+func (r *taskLogRepository) Tids() ([]int, error) {
+	var tids []int
+	if err := r.db.Model(&domain.TaskLog{}).Distinct().Pluck("tid", &tids).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return tids, nil
+}
+
+// TotalSize 返回匹配查询的日志（包括运行中的日志）的输出总字节数，只使用查询中的过滤条件
+func (r *taskLogRepository) TotalSize(query *LogCleanupQuery) (int64, error) {
+	var total int64
+	db := cleanupConditions(r.db.Model(&domain.TaskLog{}), query)
+	if err := db.Select("COALESCE(SUM(out_size + err_size), 0)").Scan(&total).Error; err != nil {
+		return 0, apperrors.Database(err)
+	}
+	return total, nil
+}
+
+// FindForCleanup 查询清理候选日志（不含输出内容）
+func (r *taskLogRepository) FindForCleanup(query *LogCleanupQuery) ([]*domain.TaskLog, error) {
+	db := r.db.Model(&domain.TaskLog{}).
+		Select("lid", "tid", "cid", "status", "storage", "out_size", "err_size", "update_at").
+		Where("running = ?", false)
+	db = cleanupConditions(db, query)
+
+	if query.Oldest {
+		db = db.Order("update_at asc")
+	} else {
+		db = db.Order("update_at desc")
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	} else if query.Offset > 0 {
+		// 部分数据库要求 OFFSET 与 LIMIT 同时使用
+		db = db.Limit(math.MaxInt32)
+	}
+
+	var logs []*domain.TaskLog
+	if err := db.Find(&logs).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return logs, nil
+}
+
+// cleanupConditions 添加清理查询的过滤条件（任务、容器、时间和是否失败）
+func cleanupConditions(db *gorm.DB, query *LogCleanupQuery) *gorm.DB {
+	if query.Tid > 0 {
+		db = db.Where("tid = ?", query.Tid)
+	}
+	if query.Cid > 0 {
+		db = db.Where("cid = ?", query.Cid)
+	}
+	if query.Before > 0 {
+		db = db.Where("update_at < ?", query.Before)
+	}
+	if query.Failed != nil {
+		if *query.Failed {
+			db = db.Where("status = ?", domain.StatusFailure)
+		} else {
+			db = db.Where("status <> ?", domain.StatusFailure)
+		}
+	}
+	return db
+}
+
+// deleteWhere 删除匹配的日志及其全文索引和存储后端中的输出
+func (r *taskLogRepository) deleteWhere(db *gorm.DB) error {
+	var logs []*domain.TaskLog
+	if err := db.Session(&gorm.Session{}).Select("lid", "storage").Find(&logs).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	lids := make([]string, 0, len(logs))
+	for _, log := range logs {
+		lids = append(lids, log.Lid)
+	}
+	if err := deleteLogIndex(r.db, lids); err != nil {
+		return err
+	}
+
+	if err := db.Delete(&domain.TaskLog{}).Error; err != nil {
 		return apperrors.Database(err)
 	}
+
+	// 元数据删除后再清理输出，文件残留不影响查询；事务中推迟到提交后清理，回滚时输出保持完整
+	if r.removed != nil {
+		*r.removed = append(*r.removed, logs...)
+		return nil
+	}
+	return deleteOutput(r.store, logs)
+}
+
+// deleteOutput 删除存储后端中的日志输出
+func deleteOutput(store LogStore, logs []*domain.TaskLog) error {
+	if store == nil {
+		return nil
+	}
+	for _, log := range logs {
+		if log.Storage != store.Name() {
+			continue
+		}
+		if err := store.Delete(log); err != nil {
+			return apperrors.Storage(err)
+		}
+	}
 	return nil
 }
+
+// Reindex 为尚未建立全文索引的已结束日志建立索引，返回处理条数
+func (r *taskLogRepository) Reindex(batchSize int) (int, error) {
+	var lids []string
+	if err := r.db.Model(&domain.TaskLog{}).
+		Where("indexed = ? AND running = ?", false, false).
+		Pluck("lid", &lids).Error; err != nil {
+		return 0, apperrors.Database(err)
+	}
+
+	indexed := 0
+	for _, lid := range lids {
+		log, err := r.getWithOutput(lid)
+		if err != nil {
+			if apperrors.IsNotFound(err) {
+				continue
+			}
+			return indexed, err
+		}
+
+		// 清理可能残留的部分索引后重建
+		if err := deleteLogIndex(r.db, []string{lid}); err != nil {
+			return indexed, err
+		}
+
+		var batch []*domain.LogLine
+		var indexErr error
+		err = r.scanLines(log, func(line *domain.LogLine) bool {
+			batch = append(batch, line)
+			if len(batch) >= batchSize {
+				indexErr = indexLogLines(r.db, lid, batch)
+				batch = batch[:0]
+			}
+			return indexErr == nil
+		})
+		if err == nil {
+			err = indexErr
+		}
+		if err == nil {
+			err = indexLogLines(r.db, lid, batch)
+		}
+		if err != nil {
+			// 输出文件缺失的日志无法建立索引，跳过
+			if apperrors.IsNotFound(err) {
+				continue
+			}
+			return indexed, err
+		}
+
+		if err := r.db.Model(&domain.TaskLog{}).Where("lid = ?", lid).Update("indexed", true).Error; err != nil {
+			return indexed, apperrors.Database(err)
+		}
+		indexed++
+	}
+	return indexed, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// taskRunRepository 任务执行记录仓储实现
+type taskRunRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskRunRepository 创建任务执行记录仓储
+func NewTaskRunRepository(db *gorm.DB) TaskRunRepository {
+	return &taskRunRepository{db: db}
+}
+
+// Save 保存执行记录
+func (r *taskRunRepository) Save(run *domain.TaskRun) error {
+	if err := r.db.Save(run).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// ListRecentByTID 获取任务最近的执行记录（按结束时间倒序）
+func (r *taskRunRepository) ListRecentByTID(tid int, limit int) ([]*domain.TaskRun, error) {
+	var runs []*domain.TaskRun
+	if err := r.db.Where("tid = ?", tid).Order("end_at desc").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return runs, nil
+}
+
+// ListRecentByTIDs 一次查询获取多个任务各自最近的执行记录（按结束时间倒序）
+//
+// 按结束时间倒序逐行读取，每个任务只保留前 limit 条，不在内存中保存多余的记录。
+func (r *taskRunRepository) ListRecentByTIDs(tids []int, limit int) (map[int][]*domain.TaskRun, error) {
+	result := make(map[int][]*domain.TaskRun, len(tids))
+	for start := 0; start < len(tids); start += 500 {
+		end := min(start+500, len(tids))
+		rows, err := r.db.Model(&domain.TaskRun{}).Where("tid IN ?", tids[start:end]).Order("end_at desc").Rows()
+		if err != nil {
+			return nil, apperrors.Database(err)
+		}
+		for rows.Next() {
+			var run domain.TaskRun
+			if err := r.db.ScanRows(rows, &run); err != nil {
+				rows.Close()
+				return nil, apperrors.Database(err)
+			}
+			if len(result[run.Tid]) < limit {
+				result[run.Tid] = append(result[run.Tid], &run)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, apperrors.Database(err)
+		}
+	}
+	return result, nil
+}
+
+// ListByRunID 获取批次内的执行记录（按开始时间正序）
+func (r *taskRunRepository) ListByRunID(runID string) ([]*domain.TaskRun, error) {
+	var runs []*domain.TaskRun
+	if err := r.db.Where("run_id = ?", runID).Order("start_at asc").Find(&runs).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return runs, nil
+}
+
+// ListBetween 获取在 [from, to) 内结束的执行记录（毫秒，按结束时间正序）
+func (r *taskRunRepository) ListBetween(from int64, to int64) ([]*domain.TaskRun, error) {
+	var runs []*domain.TaskRun
+	if err := r.db.Where("end_at >= ? AND end_at < ?", from, to).Order("end_at asc").Find(&runs).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"clock/internal/domain"
+)
+
+func TestListRecentByTIDs(t *testing.T) {
+	repo := NewTaskRunRepository(newTestDB(t))
+	// 任务 1 有 5 条记录，任务 2 有 2 条，任务 3 没有记录；结束时间交错
+	for i, tid := range []int{1, 2, 1, 1, 2, 1, 1} {
+		if err := repo.Save(&domain.TaskRun{Tid: tid, EndAt: int64(100 + i), Duration: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		tids  []int
+		limit int
+		want  map[int][]int64 // 任务ID -> 结束时间（倒序）
+	}{
+		{name: "all", tids: []int{1, 2, 3}, limit: 10, want: map[int][]int64{1: {106, 105, 103, 102, 100}, 2: {104, 101}}},
+		{name: "limit per task", tids: []int{1, 2}, limit: 2, want: map[int][]int64{1: {106, 105}, 2: {104, 101}}},
+		{name: "subset", tids: []int{2}, limit: 1, want: map[int][]int64{2: {104}}},
+		{name: "none", tids: nil, limit: 10, want: map[int][]int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.ListRecentByTIDs(tt.tids, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got runs for %d tasks, want %d", len(got), len(tt.want))
+			}
+			for tid, want := range tt.want {
+				var ends []int64
+				for _, run := range got[tid] {
+					ends = append(ends, run.EndAt)
+				}
+				if !slices.Equal(ends, want) {
+					t.Errorf("task %d end_at = %v, want %v", tid, ends, want)
+				}
+			}
+		})
+	}
+}
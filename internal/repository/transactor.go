@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+)
+
+// transactor 事务管理实现
+type transactor struct {
+	db       *gorm.DB
+	logStore LogStore
+}
+
+// NewTransactor 创建事务管理器
+func NewTransactor(db *gorm.DB, logStore LogStore) Transactor {
+	return &transactor{db: db, logStore: logStore}
+}
+
+// Transaction 在同一事务中执行 fn，fn 返回错误时回滚
+//
+// 事务中删除的日志输出在提交后清理，清理失败只记录警告：元数据已删除，残留文件不影响查询。
+func (t *transactor) Transaction(fn func(repos *Repositories) error) error {
+	var removed []*domain.TaskLog
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Repositories{
+			Task:      NewTaskRepository(tx),
+			Container: NewContainerRepository(tx),
+			Relation:  NewRelationRepository(tx),
+			TaskLog:   &taskLogRepository{db: tx, store: t.logStore, removed: &removed},
+			TaskGroup: NewTaskGroupRepository(tx),
+		})
+	})
+	if err == nil {
+		if err := deleteOutput(t.logStore, removed); err != nil {
+			logger.Warnf("[repository] failed to delete log output after commit: %v", err)
+		}
+		return nil
+	}
+
+	// 业务错误原样返回，其余视为数据库错误
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return apperrors.Database(err)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"clock/internal/domain"
+)
+
+func TestTransactionDeletesLogOutputAfterCommit(t *testing.T) {
+	dir := t.TempDir()
+	db := newTestDB(t)
+	store, err := NewFileLogStore(filepath.Join(dir, "logs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := NewTaskLogRepository(db, store)
+	tx := NewTransactor(db, store)
+
+	tests := []struct {
+		name     string
+		fail     error
+		wantGone bool
+	}{
+		{name: "rollback keeps output", fail: errors.New("abort")},
+		{name: "commit removes output", wantGone: true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &domain.TaskLog{Lid: "lid" + string(rune('a'+i)), Tid: 1, Cid: 1, StdOut: "hello"}
+			if err := logs.Save(log); err != nil {
+				t.Fatal(err)
+			}
+			file := store.(*fileLogStore).path(log.Lid, domain.LogStreamStdout)
+
+			err := tx.Transaction(func(repos *Repositories) error {
+				if err := repos.TaskLog.DeleteByID(log.Lid); err != nil {
+					return err
+				}
+				if _, err := os.Stat(file); err != nil {
+					t.Errorf("output removed before commit: %v", err)
+				}
+				return tt.fail
+			})
+			if (err != nil) != (tt.fail != nil) {
+				t.Fatalf("Transaction() error = %v", err)
+			}
+
+			_, statErr := os.Stat(file)
+			if gone := os.IsNotExist(statErr); gone != tt.wantGone {
+				t.Errorf("output removed = %v, want %v", gone, tt.wantGone)
+			}
+			_, getErr := logs.GetByID(log.Lid)
+			if found := getErr == nil; found == tt.wantGone {
+				t.Errorf("log found = %v after transaction", found)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// userRepository 用户仓储实现
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建用户仓储
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// List 获取全部用户
+func (r *userRepository) List() ([]*domain.User, error) {
+	var users []*domain.User
+	if err := r.db.Order("id asc").Find(&users).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return users, nil
+}
+
+// GetByID 根据ID获取用户
+func (r *userRepository) GetByID(id int) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.Where("id = ?", id).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("user")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &user, nil
+}
+
+// GetByName 根据用户名获取用户
+func (r *userRepository) GetByName(name string) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.Where("name = ?", name).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("user")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &user, nil
+}
+
+// Save 保存用户
+func (r *userRepository) Save(user *domain.User) error {
+	now := time.Now().Unix()
+	if user.CreateAt == 0 {
+		user.CreateAt = now
+	}
+	user.UpdateAt = now
+	if err := r.db.Save(user).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Count 统计用户数，enabledOnly 为 true 时只统计未禁用的用户
+func (r *userRepository) Count(enabledOnly bool) (int64, error) {
+	var count int64
+	db := r.db.Model(&domain.User{})
+	if enabledOnly {
+		db = db.Where("disable = ?", false)
+	}
+	if err := db.Count(&count).Error; err != nil {
+		return 0, apperrors.Database(err)
+	}
+	return count, nil
+}
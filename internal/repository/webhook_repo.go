@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// webhookRepository webhook 订阅仓储实现
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository 创建 webhook 订阅仓储
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// List 获取全部订阅
+func (r *webhookRepository) List() ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+	if err := r.db.Order("id asc").Find(&webhooks).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return webhooks, nil
+}
+
+// GetByID 根据ID获取订阅
+func (r *webhookRepository) GetByID(id int) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	if err := r.db.Where("id = ?", id).First(&webhook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("webhook")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &webhook, nil
+}
+
+// Save 保存订阅
+func (r *webhookRepository) Save(webhook *domain.Webhook) error {
+	webhook.UpdateAt = time.Now().Unix()
+	if err := r.db.Save(webhook).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Delete 删除订阅
+func (r *webhookRepository) Delete(id int) error {
+	if err := r.db.Where("id = ?", id).Delete(&domain.Webhook{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// webhookDeliveryRepository webhook 投递记录仓储实现
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository 创建 webhook 投递记录仓储
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+// List 按创建时间倒序查询投递记录，列表不返回请求体
+func (r *webhookDeliveryRepository) List(query *DeliveryQuery) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+
+	// 设置默认值
+	if query.Count < 1 {
+		query.Count = 10
+	}
+	if query.Index < 1 {
+		query.Index = 1
+	}
+
+	db := r.db.Model(&domain.WebhookDelivery{})
+
+	// 条件过滤
+	if query.WebhookID > 0 {
+		db = db.Where("webhook_id = ?", query.WebhookID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	// 统计总数
+	if err := db.Count(&query.Total).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+
+	db = db.Offset((query.Index - 1) * query.Count).Limit(query.Count).Order("create_at desc")
+	if err := db.Omit("payload").Find(&deliveries).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return deliveries, nil
+}
+
+// GetByID 根据ID获取投递记录
+func (r *webhookDeliveryRepository) GetByID(id string) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	if err := r.db.Where("id = ?", id).First(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NotFound("webhook delivery")
+		}
+		return nil, apperrors.Database(err)
+	}
+	return &delivery, nil
+}
+
+// SaveBatch 批量创建投递记录
+func (r *webhookDeliveryRepository) SaveBatch(deliveries []*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := r.db.CreateInBatches(deliveries, 500).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// Save 保存投递记录
+func (r *webhookDeliveryRepository) Save(delivery *domain.WebhookDelivery) error {
+	delivery.UpdateAt = time.Now().UnixMilli()
+	if err := r.db.Save(delivery).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// FindDue 获取订阅到期待投递的记录（按到期时间正序）
+func (r *webhookDeliveryRepository) FindDue(webhookID int, now int64, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	if err := r.db.Where("webhook_id = ? AND status = ? AND next_at <= ?", webhookID, domain.DeliveryPending, now).
+		Order("next_at asc, create_at asc").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, apperrors.Database(err)
+	}
+	return deliveries, nil
+}
+
+// DeleteByWebhookID 删除订阅的全部投递记录
+func (r *webhookDeliveryRepository) DeleteByWebhookID(webhookID int) error {
+	if err := r.db.Where("webhook_id = ?", webhookID).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+		return apperrors.Database(err)
+	}
+	return nil
+}
+
+// DeleteBefore 删除指定时间（毫秒）之前创建的已结束投递记录
+func (r *webhookDeliveryRepository) DeleteBefore(ts int64) (int64, error) {
+	result := r.db.Where("create_at < ? AND status <> ?", ts, domain.DeliveryPending).Delete(&domain.WebhookDelivery{})
+	if result.Error != nil {
+		return 0, apperrors.Database(result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -10,9 +10,11 @@ import (
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
 
 	"clock/internal/config"
+	"clock/internal/domain"
 	"clock/internal/handler"
 	"clock/internal/logger"
 	"clock/internal/middleware"
+	"clock/internal/service"
 )
 
 // Handlers 所有处理器
@@ -24,6 +26,16 @@ type Handlers struct {
 	Auth      *handler.AuthHandler
 	System    *handler.SystemHandler
 	Message   *handler.MessageHandler
+	Group     *handler.TaskGroupHandler
+	Notify    *handler.NotifyHandler
+	Webhook   *handler.WebhookHandler
+	Sla       *handler.SlaHandler
+	Anomaly   *handler.AnomalyHandler
+	Report    *handler.ReportHandler
+	User      *handler.UserHandler
+	Role      *handler.RoleHandler
+	Token     *handler.TokenHandler
+	Audit     *handler.AuditHandler
 }
 
 // Router 路由器
@@ -31,15 +43,32 @@ type Router struct {
 	engine   *echo.Echo
 	cfg      *config.Config
 	handlers *Handlers
+	users    service.UserService
+	tokens   service.APITokenService
+	access   *middleware.Access
+	audit    *middleware.Audit
 	webapp   embed.FS
 }
 
-// NewRouter 创建路由器
-func NewRouter(cfg *config.Config, handlers *Handlers, webapp embed.FS) *Router {
+// NewRouter 创建路由器，users 用于加载 JWT 对应的用户，tokens 用于校验 API 令牌，
+// access 用于检查用户的角色，audit 用于记录修改操作
+func NewRouter(
+	cfg *config.Config,
+	handlers *Handlers,
+	users service.UserService,
+	tokens service.APITokenService,
+	access service.AccessService,
+	audit service.AuditService,
+	webapp embed.FS,
+) *Router {
 	return &Router{
 		engine:   echo.New(),
 		cfg:      cfg,
 		handlers: handlers,
+		users:    users,
+		tokens:   tokens,
+		access:   middleware.NewAccess(access),
+		audit:    middleware.NewAudit(audit),
 		webapp:   webapp,
 	}
 }
@@ -61,78 +90,225 @@ func (r *Router) Setup() *echo.Echo {
 }
 
 // registerAPIRoutes 注册API路由
+//
+// 除登录和当前用户接口外，每个路由都声明所需的角色：viewer 查看，operator 运行和取消，
+// editor 修改，admin 管理用户和授权。容器相关的路由按资源所属的容器检查授权。
+// 日志相关的路由通过 Logs 标记，API 令牌访问时要求日志的权限范围。
+// 新建、修改、删除、运行和取消的路由通过 Record 记录审计，放在权限检查之后。
 func (r *Router) registerAPIRoutes() {
 	v1 := r.engine.Group("/v1")
 
 	// JWT中间件
-	v1.Use(echoMiddleware.JWTWithConfig(middleware.NewJWTConfig(&r.cfg.Auth)))
+	v1.Use(echoMiddleware.JWTWithConfig(middleware.NewJWTConfig(&r.cfg.Auth, r.tokens)))
+	v1.Use(middleware.CurrentUser(r.users))
+	v1.Use(r.access.Load())
+
+	a, au := r.access, r.audit
+	viewer, operator, editor, admin := domain.RoleViewer, domain.RoleOperator, domain.RoleEditor, domain.RoleAdmin
+	create, update, remove, save := domain.AuditCreate, domain.AuditUpdate, domain.AuditDelete, domain.AuditSave
+	execute, cancel := domain.AuditRun, domain.AuditCancel
 
 	// 任务路由
 	task := v1.Group("/task")
 	{
-		task.GET("", r.handlers.Task.GetTasks)
-		task.GET("/:tid", r.handlers.Task.GetTask)
-		task.PUT("", r.handlers.Task.PutTask)
-		task.GET("/run", r.handlers.Task.RunTask)
-		task.DELETE("/:tid", r.handlers.Task.DeleteTask)
-		task.GET("/status", r.handlers.Message.GetTaskStatus)
-		task.POST("/cancel", r.handlers.Task.CancelTask)
-		task.GET("/running", r.handlers.Task.GetRunningTasks)
+		task.GET("", r.handlers.Task.GetTasks, a.Any(viewer))
+		task.GET("/:tid", r.handlers.Task.GetTask, a.Container(viewer, a.Task("tid")))
+		task.PUT("", r.handlers.Task.PutTask, a.Container(editor, a.Body()),
+			au.Record(save, domain.AuditTargetTask, au.Body("tid")))
+		task.GET("/run", r.handlers.Task.RunTask, a.Container(operator, a.Task("tid")),
+			au.Record(execute, domain.AuditTargetTask, au.Param("tid")))
+		task.DELETE("/:tid", r.handlers.Task.DeleteTask, a.Container(editor, a.Task("tid")),
+			au.Record(remove, domain.AuditTargetTask, au.Param("tid")))
+		task.POST("/:tid/restore", r.handlers.Task.RestoreTask, a.Container(editor, a.Task("tid")),
+			au.Record(update, domain.AuditTargetTask, au.Param("tid")))
+		task.GET("/:tid/duration", r.handlers.Anomaly.GetDurationStats, a.Container(viewer, a.Task("tid")))
+		task.GET("/status", r.handlers.Message.GetTaskStatus, a.Any(viewer))
+		task.GET("/follow", r.handlers.Message.FollowRun, a.Any(viewer))
+		task.POST("/cancel", r.handlers.Task.CancelTask, a.Container(operator, a.Body()),
+			au.Record(cancel, domain.AuditTargetTask, au.Body("tid")))
+		task.GET("/running", r.handlers.Task.GetRunningTasks, a.Any(viewer))
 	}
 
 	// run 路由（用于取消整个 run）
 	run := v1.Group("/run")
 	{
-		run.POST("/cancel", r.handlers.Task.CancelRun)
+		run.POST("/cancel", r.handlers.Task.CancelRun, a.Container(operator, a.Body()),
+			au.Record(cancel, domain.AuditTargetRun, au.Body("runId")))
+		run.GET("/:runid/events", r.handlers.Message.GetRunEvents, a.Logs(), a.Container(viewer, a.Run("runid")))
+		run.GET("/:runid/replay", r.handlers.Message.ReplayRun, a.Logs(), a.Container(viewer, a.Run("runid")))
 	}
 
-	// 容器路由
+	// 容器路由（新建和克隆容器需要全局 editor）
 	container := v1.Group("/container")
 	{
-		container.GET("", r.handlers.Container.GetContainers)
-		container.GET("/:cid", r.handlers.Container.GetContainer)
-		container.PUT("", r.handlers.Container.PutContainer)
-		container.GET("/run", r.handlers.Container.RunContainer)
-		container.DELETE("/:cid", r.handlers.Container.DeleteContainer)
+		container.GET("", r.handlers.Container.GetContainers, a.Any(viewer))
+		container.GET("/:cid", r.handlers.Container.GetContainer, a.Container(viewer, a.Cid("cid")))
+		container.PUT("", r.handlers.Container.PutContainer, a.Container(editor, a.Body()),
+			au.Record(save, domain.AuditTargetContainer, au.Body("cid")))
+		container.GET("/run", r.handlers.Container.RunContainer, a.Container(operator, a.Cid("cid")),
+			au.Record(execute, domain.AuditTargetContainer, au.Param("cid")))
+		container.DELETE("/:cid", r.handlers.Container.DeleteContainer, a.Container(editor, a.Cid("cid")),
+			au.Record(remove, domain.AuditTargetContainer, au.Param("cid")))
+		container.POST("/:cid/restore", r.handlers.Container.RestoreContainer, a.Container(editor, a.Cid("cid")),
+			au.Record(update, domain.AuditTargetContainer, au.Param("cid")))
+		container.POST("/:cid/clone", r.handlers.Container.CloneContainer, a.Global(editor),
+			au.Record(create, domain.AuditTargetContainer, au.Result("cid")))
+	}
+
+	// 任务组路由
+	group := v1.Group("/group")
+	{
+		group.GET("", r.handlers.Group.GetGroups, a.Container(viewer, a.Cid("cid")))
+		group.PUT("", r.handlers.Group.PutGroup, a.Container(editor, a.Body()),
+			au.Record(save, domain.AuditTargetGroup, au.Body("gid")))
+		group.DELETE("/:gid", r.handlers.Group.DeleteGroup, a.Container(editor, a.Group("gid")),
+			au.Record(remove, domain.AuditTargetGroup, au.Param("gid")))
+		group.POST("/:gid/cancel", r.handlers.Group.CancelGroup, a.Container(operator, a.Group("gid")),
+			au.Record(cancel, domain.AuditTargetGroup, au.Param("gid")))
+		group.POST("/:gid/retry", r.handlers.Group.RetryGroup, a.Container(operator, a.Group("gid")),
+			au.Record(execute, domain.AuditTargetGroup, au.Param("gid")))
+		group.POST("/:gid/clear", r.handlers.Group.ClearGroup, a.Container(operator, a.Group("gid")),
+			au.Record(update, domain.AuditTargetGroup, au.Param("gid")))
 	}
 
 	// 日志路由
-	logGroup := v1.Group("/log")
+	logGroup := v1.Group("/log", a.Logs())
 	{
-		logGroup.GET("", r.handlers.Log.GetLogs)
-		logGroup.DELETE("", r.handlers.Log.DeleteLogs)
-		logGroup.DELETE("/all", r.handlers.Log.DeleteAllLogs)
-		logGroup.DELETE("/:lid", r.handlers.Log.DeleteLogByID)
+		logGroup.GET("", r.handlers.Log.GetLogs, a.Any(viewer))
+		logGroup.DELETE("", r.handlers.Log.DeleteLogs, a.Container(editor, a.Scopes(a.Cid("cid"), a.Task("tid"))),
+			au.Record(remove, domain.AuditTargetLog))
+		logGroup.DELETE("/all", r.handlers.Log.DeleteAllLogs, a.Global(admin),
+			au.Record(remove, domain.AuditTargetLog))
+		logGroup.POST("/migrate", r.handlers.Log.MigrateLogs, a.Global(admin),
+			au.Record(execute, domain.AuditTargetLog))
+		logGroup.POST("/reindex", r.handlers.Log.ReindexLogs, a.Global(admin),
+			au.Record(execute, domain.AuditTargetLog))
+		logGroup.GET("/run/:runid/download", r.handlers.Log.DownloadRunLog, a.Container(viewer, a.Run("runid")))
+		logGroup.GET("/:lid/output", r.handlers.Log.GetLogOutput, a.Container(viewer, a.Log("lid")))
+		logGroup.GET("/:lid/download", r.handlers.Log.DownloadLog, a.Container(viewer, a.Log("lid")))
+		logGroup.GET("/:lid/lines", r.handlers.Log.GetLogLines, a.Container(viewer, a.Log("lid")))
+		logGroup.DELETE("/:lid", r.handlers.Log.DeleteLogByID, a.Container(editor, a.Log("lid")),
+			au.Record(remove, domain.AuditTargetLog, au.Param("lid")))
 	}
 
-	// 登录路由
+	// 登录路由（无需认证）
 	login := v1.Group("/login")
 	{
 		login.POST("", r.handlers.Auth.Login)
 	}
 
+	// 用户路由（查看和修改自己的信息只需登录）
+	user := v1.Group("/user")
+	{
+		user.GET("", r.handlers.User.GetUsers, a.Global(admin))
+		user.PUT("", r.handlers.User.PutUser, a.Global(admin),
+			au.Record(create, domain.AuditTargetUser, au.Result("id")))
+		user.GET("/me", r.handlers.User.GetMe)
+		user.PUT("/me/password", r.handlers.User.ChangePassword, a.Session(),
+			au.Record(update, domain.AuditTargetUser, au.Self()))
+		user.POST("/:id/disable", r.handlers.User.DisableUser, a.Global(admin),
+			au.Record(update, domain.AuditTargetUser, au.Param("id")))
+		user.POST("/:id/enable", r.handlers.User.EnableUser, a.Global(admin),
+			au.Record(update, domain.AuditTargetUser, au.Param("id")))
+		user.POST("/:id/password", r.handlers.User.ResetPassword, a.Global(admin),
+			au.Record(update, domain.AuditTargetUser, au.Param("id")))
+	}
+
+	// API 令牌路由（只能使用 JWT 登录管理令牌）
+	token := v1.Group("/token", a.Session())
+	{
+		token.GET("", r.handlers.Token.GetTokens)
+		token.PUT("", r.handlers.Token.PutToken,
+			au.Record(create, domain.AuditTargetToken, au.Result("id")))
+		token.DELETE("/:id", r.handlers.Token.DeleteToken,
+			au.Record(remove, domain.AuditTargetToken, au.Param("id")))
+	}
+
+	// 授权路由（容器 admin 可管理该容器的授权）
+	role := v1.Group("/role")
+	{
+		role.GET("", r.handlers.Role.GetRoles, a.Container(admin, a.Cid("cid")))
+		role.PUT("", r.handlers.Role.PutRole, a.Container(admin, a.Body()),
+			au.Record(save, domain.AuditTargetRole, au.Body("id")))
+		role.DELETE("/:id", r.handlers.Role.DeleteRole, a.Container(admin, a.RoleBinding("id")),
+			au.Record(remove, domain.AuditTargetRole, au.Param("id")))
+	}
+
 	// 关系路由
 	relation := v1.Group("/relation")
 	{
-		relation.GET("", r.handlers.Relation.GetRelations)
-		relation.POST("", r.handlers.Relation.AddRelation)
-		relation.DELETE("/:rid", r.handlers.Relation.DeleteRelation)
+		relation.GET("", r.handlers.Relation.GetRelations, a.Container(viewer, a.Cid("cid")))
+		relation.GET("/export", r.handlers.Relation.ExportRelations, a.Container(viewer, a.Cid("cid")))
+		relation.POST("", r.handlers.Relation.AddRelation, a.Container(editor, a.Body()),
+			au.Record(create, domain.AuditTargetRelation, au.Result("rid")))
+		relation.DELETE("/:rid", r.handlers.Relation.DeleteRelation, a.Container(editor, a.Relation("rid")),
+			au.Record(remove, domain.AuditTargetRelation, au.Param("rid")))
 	}
 
 	// 节点路由
 	node := v1.Group("/node")
 	{
-		node.PUT("", r.handlers.Task.PutNodes)
+		node.PUT("", r.handlers.Task.PutNodes, a.Container(editor, a.Nodes()),
+			au.Record(update, domain.AuditTargetNode))
 	}
 
 	// 消息路由
 	message := v1.Group("/message")
 	{
-		message.GET("", r.handlers.Message.GetMessages)
+		message.GET("", r.handlers.Message.GetMessages, a.Any(viewer))
+	}
+
+	// 通知路由
+	notify := v1.Group("/notify")
+	{
+		notify.GET("/channel", r.handlers.Notify.GetChannels, a.Any(viewer))
+		notify.GET("/rule", r.handlers.Notify.GetRules, a.Container(viewer, a.Scopes(a.Cid("cid"), a.Task("tid"))))
+		notify.PUT("/rule", r.handlers.Notify.PutRule, a.Container(editor, a.NotifyRuleBody()),
+			au.Record(save, domain.AuditTargetNotifyRule, au.Body("id")))
+		notify.DELETE("/rule/:id", r.handlers.Notify.DeleteRule, a.Container(editor, a.NotifyRule("id")),
+			au.Record(remove, domain.AuditTargetNotifyRule, au.Param("id")))
+		notify.POST("/test", r.handlers.Notify.TestChannel, a.Global(admin))
+		notify.POST("/preview", r.handlers.Notify.PreviewTemplate, a.Container(viewer, a.Body()))
+	}
+
+	// webhook 订阅路由（包含密钥和全部容器的事件，只允许全局 admin）
+	webhook := v1.Group("/webhook", a.Global(admin))
+	{
+		webhook.GET("", r.handlers.Webhook.GetWebhooks)
+		webhook.PUT("", r.handlers.Webhook.PutWebhook,
+			au.Record(save, domain.AuditTargetWebhook, au.Body("id")))
+		webhook.GET("/delivery", r.handlers.Webhook.GetDeliveries)
+		webhook.GET("/delivery/:did", r.handlers.Webhook.GetDelivery)
+		webhook.POST("/delivery/:did/redeliver", r.handlers.Webhook.Redeliver,
+			au.Record(execute, domain.AuditTargetDelivery, au.Param("did")))
+		webhook.DELETE("/:id", r.handlers.Webhook.DeleteWebhook,
+			au.Record(remove, domain.AuditTargetWebhook, au.Param("id")))
+		webhook.POST("/:id/ping", r.handlers.Webhook.PingWebhook,
+			au.Record(execute, domain.AuditTargetWebhook, au.Param("id")))
+	}
+
+	// SLA 路由
+	sla := v1.Group("/sla")
+	{
+		sla.GET("/miss", r.handlers.Sla.GetMisses, a.Any(viewer))
+	}
+
+	// 报表路由（汇总全部容器，需要全局角色）
+	report := v1.Group("/report")
+	{
+		report.GET("", r.handlers.Report.GetReport, a.Global(viewer))
+		report.POST("/send", r.handlers.Report.SendReport, a.Global(operator),
+			au.Record(execute, domain.AuditTargetReport))
+	}
+
+	// 审计记录路由
+	audit := v1.Group("/audit")
+	{
+		audit.GET("", r.handlers.Audit.GetAudits, a.Global(admin))
 	}
 
 	// 系统监控路由
-	system := v1.Group("/system")
+	system := v1.Group("/system", a.Any(viewer))
 	{
 		system.GET("/load", r.handlers.System.GetLoadAverage)
 		system.GET("/mem", r.handlers.System.GetMemoryUsage)
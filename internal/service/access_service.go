@@ -0,0 +1,219 @@
+package service
+
+import (
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/repository"
+)
+
+// accessService 权限服务实现
+type accessService struct {
+	bindingRepo   repository.RoleBindingRepository
+	userRepo      repository.UserRepository
+	containerRepo repository.ContainerRepository
+	taskRepo      repository.TaskRepository
+	groupRepo     repository.TaskGroupRepository
+	relationRepo  repository.RelationRepository
+	taskLogRepo   repository.TaskLogRepository
+	taskRunRepo   repository.TaskRunRepository
+	ruleRepo      repository.NotifyRuleRepository
+	executor      *Executor
+}
+
+// NewAccessService 创建权限服务
+func NewAccessService(
+	bindingRepo repository.RoleBindingRepository,
+	userRepo repository.UserRepository,
+	containerRepo repository.ContainerRepository,
+	taskRepo repository.TaskRepository,
+	groupRepo repository.TaskGroupRepository,
+	relationRepo repository.RelationRepository,
+	taskLogRepo repository.TaskLogRepository,
+	taskRunRepo repository.TaskRunRepository,
+	ruleRepo repository.NotifyRuleRepository,
+	executor *Executor,
+) AccessService {
+	return &accessService{
+		bindingRepo:   bindingRepo,
+		userRepo:      userRepo,
+		containerRepo: containerRepo,
+		taskRepo:      taskRepo,
+		groupRepo:     groupRepo,
+		relationRepo:  relationRepo,
+		taskLogRepo:   taskLogRepo,
+		taskRunRepo:   taskRunRepo,
+		ruleRepo:      ruleRepo,
+		executor:      executor,
+	}
+}
+
+// Permissions 计算用户生效的权限
+func (s *accessService) Permissions(userID int) (*domain.Permissions, error) {
+	bindings, err := s.bindingRepo.List(&repository.RoleBindingQuery{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPermissions(bindings), nil
+}
+
+// ListBindings 查询授权
+func (s *accessService) ListBindings(query *repository.RoleBindingQuery) ([]*domain.RoleBinding, error) {
+	return s.bindingRepo.List(query)
+}
+
+// GetBinding 获取授权
+func (s *accessService) GetBinding(id int) (*domain.RoleBinding, error) {
+	return s.bindingRepo.GetByID(id)
+}
+
+// Grant 授予用户角色，用户在同一容器上已有授权时替换角色
+func (s *accessService) Grant(binding *domain.RoleBinding) (*domain.RoleBinding, error) {
+	if !binding.Role.Valid() {
+		return nil, apperrors.InvalidParam("invalid role: " + string(binding.Role))
+	}
+	if _, err := s.userRepo.GetByID(binding.UserID); err != nil {
+		return nil, err
+	}
+	if binding.Cid > 0 {
+		if _, err := s.containerRepo.GetByID(binding.Cid); err != nil {
+			return nil, err
+		}
+	} else if binding.Cid < 0 {
+		return nil, apperrors.InvalidParam("invalid cid")
+	}
+
+	existing, err := s.bindingRepo.Find(binding.UserID, binding.Cid)
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			return nil, err
+		}
+		existing = &domain.RoleBinding{UserID: binding.UserID, Cid: binding.Cid}
+	} else if err := s.checkLastAdmin(existing, binding.Role); err != nil {
+		return nil, err
+	}
+
+	existing.Role = binding.Role
+	if err := s.bindingRepo.Save(existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// Revoke 撤销授权
+func (s *accessService) Revoke(id int) error {
+	binding, err := s.bindingRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkLastAdmin(binding, ""); err != nil {
+		return err
+	}
+	return s.bindingRepo.Delete(id)
+}
+
+// checkLastAdmin 全局管理员授权被降级或撤销时，确保还有其他启用的全局管理员
+func (s *accessService) checkLastAdmin(binding *domain.RoleBinding, role domain.Role) error {
+	if binding.Cid != 0 || binding.Role != domain.RoleAdmin || role == domain.RoleAdmin {
+		return nil
+	}
+	ok, err := hasOtherAdmin(s.userRepo, s.bindingRepo, binding.UserID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperrors.InvalidParam("cannot remove the last administrator")
+	}
+	return nil
+}
+
+// hasOtherAdmin 判断除 userID 外是否还有启用的全局管理员
+func hasOtherAdmin(userRepo repository.UserRepository, bindingRepo repository.RoleBindingRepository, userID int) (bool, error) {
+	global := 0
+	admins, err := bindingRepo.List(&repository.RoleBindingQuery{Cid: &global, Role: domain.RoleAdmin})
+	if err != nil {
+		return false, err
+	}
+	for _, admin := range admins {
+		if admin.UserID == userID {
+			continue
+		}
+		user, err := userRepo.GetByID(admin.UserID)
+		if err != nil {
+			if apperrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if !user.Disable {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// TaskCid 返回任务所属的容器（包括回收站中的任务）
+func (s *accessService) TaskCid(tid int) (int, error) {
+	task, err := s.taskRepo.GetByIDUnscoped(tid)
+	if err != nil {
+		return 0, err
+	}
+	return task.Cid, nil
+}
+
+// GroupCid 返回任务组所属的容器
+func (s *accessService) GroupCid(gid int) (int, error) {
+	group, err := s.groupRepo.GetByID(gid)
+	if err != nil {
+		return 0, err
+	}
+	return group.Cid, nil
+}
+
+// RelationCid 返回关系所属的容器
+func (s *accessService) RelationCid(rid int) (int, error) {
+	relation, err := s.relationRepo.GetByID(rid)
+	if err != nil {
+		return 0, err
+	}
+	return relation.Cid, nil
+}
+
+// LogCid 返回日志所属的容器
+func (s *accessService) LogCid(lid string) (int, error) {
+	log, err := s.taskLogRepo.GetByID(lid)
+	if err != nil {
+		return 0, err
+	}
+	return log.Cid, nil
+}
+
+// RunCid 返回批次所属的容器，依次查找正在执行的批次、执行记录和日志
+func (s *accessService) RunCid(runID string) (int, error) {
+	if cid, ok := s.executor.RunCid(runID); ok {
+		return cid, nil
+	}
+	runs, err := s.taskRunRepo.ListByRunID(runID)
+	if err != nil {
+		return 0, err
+	}
+	if len(runs) > 0 {
+		return runs[0].Cid, nil
+	}
+	logs, err := s.taskLogRepo.FindByRunID(runID)
+	if err != nil {
+		return 0, err
+	}
+	if len(logs) > 0 {
+		return logs[0].Cid, nil
+	}
+	return 0, apperrors.NotFound("run")
+}
+
+// NotifyRuleCid 返回通知规则所属的容器
+func (s *accessService) NotifyRuleCid(id int) (int, error) {
+	rule, err := s.ruleRepo.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+	return rule.Cid, nil
+}
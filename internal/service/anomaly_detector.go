@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// anomalyCheckInterval 检查运行中任务的间隔
+const anomalyCheckInterval = 5 * time.Second
+
+// anomalyFilter 异常检测订阅的事件
+var anomalyFilter = &StreamFilter{Kinds: []string{"task_start", "task_end"}}
+
+// anomalyTask 运行中的任务
+type anomalyTask struct {
+	runID     string
+	cid       int
+	name      string
+	startAt   time.Time
+	threshold time.Duration
+	stats     *domain.DurationStats
+	flagged   bool // 已发布异常事件
+}
+
+// anomalyDetector 耗时异常检测服务实现
+//
+// 任务开始时根据最近的成功执行计算耗时阈值，运行中超过阈值时立即发布
+// anomaly 事件，执行结束时再检查一次，每次执行最多发布一次。
+type anomalyDetector struct {
+	cfg         *config.AnomalyConfig
+	taskRepo    repository.TaskRepository
+	taskRunRepo repository.TaskRunRepository
+	hub         *StreamHub
+
+	tasks map[int]*anomalyTask // 只在检测协程中访问
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAnomalyDetector 创建耗时异常检测服务
+func NewAnomalyDetector(
+	cfg *config.AnomalyConfig,
+	taskRepo repository.TaskRepository,
+	taskRunRepo repository.TaskRunRepository,
+	hub *StreamHub,
+) AnomalyDetector {
+	return &anomalyDetector{
+		cfg:         cfg,
+		taskRepo:    taskRepo,
+		taskRunRepo: taskRunRepo,
+		hub:         hub,
+	}
+}
+
+// Start 开始检测，未启用时不启动
+func (d *anomalyDetector) Start() {
+	if !d.cfg.Enable || d.cancel != nil {
+		return
+	}
+	d.tasks = make(map[int]*anomalyTask)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	events := d.hub.Subscribe(ctx, anomalyFilter, 0)
+
+	logger.Infof("[anomaly] anomaly detector started, method=%s", d.cfg.Method)
+	go d.loop(ctx, events)
+}
+
+// Stop 停止检测
+func (d *anomalyDetector) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	<-d.done
+	d.cancel = nil
+}
+
+// loop 处理事件并定期检查运行中的任务
+func (d *anomalyDetector) loop(ctx context.Context, events <-chan StreamEvent) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(anomalyCheckInterval)
+	defer ticker.Stop()
+
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Warnf("[anomaly] subscription dropped, resuming from event %d", lastID)
+				events = d.hub.Subscribe(ctx, anomalyFilter, lastID)
+				continue
+			}
+			lastID = ev.ID
+			d.handle(&ev)
+		case now := <-ticker.C:
+			for tid, task := range d.tasks {
+				d.check(tid, task, now.Sub(task.startAt), "running")
+			}
+		}
+	}
+}
+
+// handle 任务开始时计算阈值，结束时检查本次耗时
+func (d *anomalyDetector) handle(ev *StreamEvent) {
+	switch ev.Kind {
+	case "task_start":
+		delete(d.tasks, ev.Tid)
+		history, err := d.taskRunRepo.ListRecentByTID(ev.Tid, d.cfg.Window)
+		if err != nil {
+			logger.Errorf("[anomaly] failed to load run history of task %d: %v", ev.Tid, err)
+			return
+		}
+		stats := buildDurationStats(history, d.cfg)
+		if stats.Threshold <= 0 {
+			return
+		}
+		d.tasks[ev.Tid] = &anomalyTask{
+			runID:     ev.RunID,
+			cid:       ev.Cid,
+			name:      ev.TaskName,
+			startAt:   time.UnixMilli(ev.TS),
+			threshold: time.Duration(stats.Threshold) * time.Millisecond,
+			stats:     stats,
+		}
+	case "task_end":
+		task, ok := d.tasks[ev.Tid]
+		if !ok {
+			return
+		}
+		delete(d.tasks, ev.Tid)
+		d.check(ev.Tid, task, time.Duration(ev.DurationMs)*time.Millisecond, ev.Status)
+	}
+}
+
+// check 耗时超过阈值时发布 anomaly 事件
+func (d *anomalyDetector) check(tid int, task *anomalyTask, elapsed time.Duration, status string) {
+	if task.flagged || elapsed <= task.threshold {
+		return
+	}
+	task.flagged = true
+
+	verb := "running for"
+	if status != "running" {
+		verb = "took"
+	}
+	msg := fmt.Sprintf("task %s %s %s, beyond %s threshold %s (mean %s over %d runs)",
+		task.name, verb, elapsed.Round(time.Second), d.describe(),
+		task.threshold.Round(time.Second), (time.Duration(task.stats.Mean) * time.Millisecond).Round(time.Second), task.stats.Runs)
+	logger.Warnf("[anomaly] %s", msg)
+	d.hub.Publish(StreamEvent{
+		Kind:       "anomaly",
+		RunID:      task.runID,
+		Tid:        tid,
+		Cid:        task.cid,
+		TaskName:   task.name,
+		Status:     status,
+		DurationMs: elapsed.Milliseconds(),
+		Msg:        msg,
+	})
+}
+
+// describe 返回阈值的计算方式
+func (d *anomalyDetector) describe() string {
+	if d.cfg.Method == "stddev" {
+		return fmt.Sprintf("mean+%gσ", d.cfg.Sigma)
+	}
+	return fmt.Sprintf("p%g", d.cfg.Percentile)
+}
+
+// Stats 计算任务的耗时统计和建议超时时间
+func (d *anomalyDetector) Stats(tid int) (*domain.DurationStats, error) {
+	task, err := d.taskRepo.GetByID(tid)
+	if err != nil {
+		return nil, err
+	}
+	history, err := d.taskRunRepo.ListRecentByTID(tid, d.cfg.Window)
+	if err != nil {
+		return nil, err
+	}
+	stats := buildDurationStats(history, d.cfg)
+	stats.Tid = tid
+	stats.Timeout = task.Timeout
+	return stats, nil
+}
+
+// buildDurationStats 根据成功执行的耗时计算统计，样本不足时不计算阈值
+func buildDurationStats(runs []*domain.TaskRun, cfg *config.AnomalyConfig) *domain.DurationStats {
+	var durations []int64
+	for _, run := range runs {
+		if run.Status == domain.StatusSuccess {
+			durations = append(durations, run.Duration)
+		}
+	}
+
+	stats := &domain.DurationStats{Runs: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+
+	var total int64
+	for _, d := range durations {
+		total += d
+		stats.Max = max(stats.Max, d)
+	}
+	mean := float64(total) / float64(len(durations))
+	var variance float64
+	for _, d := range durations {
+		variance += (float64(d) - mean) * (float64(d) - mean)
+	}
+	std := math.Sqrt(variance / float64(len(durations)))
+
+	stats.Mean = int64(mean)
+	stats.Stddev = int64(std)
+	stats.P50 = percentile(durations, 0.5)
+	stats.P95 = percentile(durations, 0.95)
+	stats.P99 = percentile(durations, 0.99)
+
+	if len(durations) < cfg.MinRuns {
+		return stats
+	}
+	if cfg.Method == "stddev" {
+		stats.Threshold = int64(mean + cfg.Sigma*std)
+	} else {
+		stats.Threshold = percentile(durations, cfg.Percentile/100)
+	}
+	stats.Threshold = max(stats.Threshold, int64(cfg.MinDuration)*1000)
+	stats.SuggestedTimeout = int(math.Ceil(float64(stats.Threshold) * cfg.TimeoutFactor / 1000))
+	return stats
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+)
+
+func TestBuildDurationStats(t *testing.T) {
+	// 10 次成功执行，耗时 10s..100s，另有一次失败不参与统计
+	var runs []*domain.TaskRun
+	for i := 1; i <= 10; i++ {
+		runs = append(runs, &domain.TaskRun{Status: domain.StatusSuccess, Duration: int64(i) * 10000})
+	}
+	runs = append(runs, &domain.TaskRun{Status: domain.StatusFailure, Duration: 999999})
+	base := domain.DurationStats{Runs: 10, Mean: 55000, Stddev: 28722, P50: 50000, P95: 100000, P99: 100000, Max: 100000}
+
+	tests := []struct {
+		name          string
+		runs          []*domain.TaskRun
+		cfg           *config.AnomalyConfig
+		threshold     int64
+		suggested     int
+		wantEmptyRuns bool
+	}{
+		{
+			name:      "percentile",
+			runs:      runs,
+			cfg:       &config.AnomalyConfig{Method: "percentile", Percentile: 90, MinRuns: 10, TimeoutFactor: 1.5},
+			threshold: 90000,
+			suggested: 135,
+		},
+		{
+			name:      "stddev",
+			runs:      runs,
+			cfg:       &config.AnomalyConfig{Method: "stddev", Sigma: 2, MinRuns: 10, TimeoutFactor: 1.5},
+			threshold: 112445,
+			suggested: 169,
+		},
+		{
+			name:      "min duration floor",
+			runs:      runs,
+			cfg:       &config.AnomalyConfig{Method: "percentile", Percentile: 99, MinRuns: 10, MinDuration: 200, TimeoutFactor: 1.5},
+			threshold: 200000,
+			suggested: 300,
+		},
+		{
+			name: "too few runs",
+			runs: runs,
+			cfg:  &config.AnomalyConfig{Method: "percentile", Percentile: 99, MinRuns: 11, TimeoutFactor: 1.5},
+		},
+		{
+			name:          "failures only",
+			runs:          []*domain.TaskRun{{Status: domain.StatusFailure, Duration: 1000}},
+			cfg:           &config.AnomalyConfig{Method: "percentile", Percentile: 99, TimeoutFactor: 1.5},
+			wantEmptyRuns: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildDurationStats(tt.runs, tt.cfg)
+			want := base
+			want.Threshold, want.SuggestedTimeout = tt.threshold, tt.suggested
+			if tt.wantEmptyRuns {
+				want = domain.DurationStats{}
+			}
+			if *got != want {
+				t.Errorf("buildDurationStats() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
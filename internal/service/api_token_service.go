@@ -0,0 +1,158 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// API 令牌参数
+const (
+	apiTokenBytes      = 20               // 令牌随机部分的字节数
+	apiTokenPrefixLen  = 12               // 保存用于识别的令牌开头字符数（含 clk_）
+	apiTokenUsedWindow = 60 * time.Second // 最近使用时间的更新间隔，避免每次请求都写数据库
+)
+
+// APITokenRequest 创建 API 令牌参数
+type APITokenRequest struct {
+	Name     string `json:"name"`
+	Scopes   string `json:"scopes"`    // 权限范围，逗号分隔
+	ExpireAt int64  `json:"expire_at"` // 过期时间（秒），0 表示永不过期
+}
+
+// APITokenCreated 创建的令牌，Token 只在创建时返回一次
+type APITokenCreated struct {
+	*domain.APIToken
+	Token string `json:"token"`
+}
+
+// apiTokenService API 令牌服务实现
+type apiTokenService struct {
+	repo     repository.APITokenRepository
+	userRepo repository.UserRepository
+}
+
+// NewAPITokenService 创建 API 令牌服务
+func NewAPITokenService(repo repository.APITokenRepository, userRepo repository.UserRepository) APITokenService {
+	return &apiTokenService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// List 获取用户的令牌，userID 为 0 时返回全部令牌
+func (s *apiTokenService) List(userID int) ([]*domain.APIToken, error) {
+	return s.repo.List(userID)
+}
+
+// Get 获取令牌
+func (s *apiTokenService) Get(id int) (*domain.APIToken, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create 为用户创建令牌，只保存令牌的哈希
+func (s *apiTokenService) Create(userID int, req *APITokenRequest) (*APITokenCreated, error) {
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxUserNameLen {
+		return nil, apperrors.InvalidParam("name is required and must not exceed 64 characters")
+	}
+	scopes, err := parseScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpireAt < 0 || (req.ExpireAt > 0 && req.ExpireAt <= time.Now().Unix()) {
+		return nil, apperrors.InvalidParam("expire_at must be in the future")
+	}
+
+	buf := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	raw := domain.APITokenPrefix + hex.EncodeToString(buf)
+
+	token := &domain.APIToken{
+		UserID:   userID,
+		Name:     req.Name,
+		Prefix:   raw[:apiTokenPrefixLen],
+		Hash:     hashAPIToken(raw),
+		Scopes:   strings.Join(scopes, ","),
+		ExpireAt: req.ExpireAt,
+	}
+	if err := s.repo.Save(token); err != nil {
+		return nil, err
+	}
+	logger.Infof("[token] user %d created token %s (%s)", userID, token.Name, token.Prefix)
+	return &APITokenCreated{APIToken: token, Token: raw}, nil
+}
+
+// Revoke 撤销令牌
+func (s *apiTokenService) Revoke(id int) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Authenticate 校验令牌，返回令牌和所属用户，并记录最近使用时间
+func (s *apiTokenService) Authenticate(raw string) (*domain.APIToken, *domain.User, error) {
+	token, err := s.repo.GetByHash(hashAPIToken(raw))
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return nil, nil, apperrors.Unauthorized("invalid token")
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if token.ExpireAt > 0 && token.ExpireAt <= now.Unix() {
+		return nil, nil, apperrors.Unauthorized("token expired")
+	}
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return nil, nil, apperrors.Unauthorized("invalid token")
+		}
+		return nil, nil, err
+	}
+
+	if now.Sub(time.Unix(token.LastUsedAt, 0)) >= apiTokenUsedWindow {
+		token.LastUsedAt = now.Unix()
+		if err := s.repo.UpdateLastUsed(token.ID, token.LastUsedAt); err != nil {
+			logger.Warnf("[token] failed to update last used time of token %d: %v", token.ID, err)
+		}
+	}
+	return token, user, nil
+}
+
+// parseScopes 校验并去重权限范围
+func parseScopes(value string) ([]string, error) {
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || slices.Contains(scopes, scope) {
+			continue
+		}
+		if !slices.Contains(domain.Scopes, scope) {
+			return nil, apperrors.InvalidParam("invalid scope: " + scope)
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, apperrors.InvalidParam("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// hashAPIToken 计算令牌的 SHA-256 哈希（令牌为高熵随机值，无需加盐）
+func hashAPIToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
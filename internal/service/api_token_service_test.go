@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/repository"
+)
+
+func newAPITokenFixture(t *testing.T) (APITokenService, repository.APITokenRepository, *domain.User) {
+	t.Helper()
+	db := newTestDB(t)
+	tokens := repository.NewAPITokenRepository(db)
+	users := repository.NewUserRepository(db)
+	user := &domain.User{Name: "u"}
+	if err := users.Save(user); err != nil {
+		t.Fatal(err)
+	}
+	return NewAPITokenService(tokens, users), tokens, user
+}
+
+func TestAPITokenCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *APITokenRequest
+		wantScopes string
+		wantErr    bool
+	}{
+		{name: "single scope", req: &APITokenRequest{Name: "ci", Scopes: "read:logs"}, wantScopes: "read:logs"},
+		{name: "trimmed and deduplicated", req: &APITokenRequest{Name: "ci", Scopes: " read:logs, run:container,read:logs,"}, wantScopes: "read:logs,run:container"},
+		{name: "future expiry", req: &APITokenRequest{Name: "ci", Scopes: "admin", ExpireAt: time.Now().Add(time.Hour).Unix()}, wantScopes: "admin"},
+		{name: "unknown scope", req: &APITokenRequest{Name: "ci", Scopes: "read:logs,delete:all"}, wantErr: true},
+		{name: "no scope", req: &APITokenRequest{Name: "ci", Scopes: " , "}, wantErr: true},
+		{name: "no name", req: &APITokenRequest{Scopes: "read:logs"}, wantErr: true},
+		{name: "name too long", req: &APITokenRequest{Name: strings.Repeat("n", 65), Scopes: "read:logs"}, wantErr: true},
+		{name: "past expiry", req: &APITokenRequest{Name: "ci", Scopes: "read:logs", ExpireAt: time.Now().Add(-time.Minute).Unix()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, user := newAPITokenFixture(t)
+			created, err := svc.Create(user.ID, tt.req)
+			if tt.wantErr {
+				var appErr *apperrors.AppError
+				if !errors.As(err, &appErr) || appErr.Code != apperrors.ErrInvalidParam {
+					t.Fatalf("Create() error = %v, want invalid param", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.Scopes != tt.wantScopes {
+				t.Errorf("scopes = %q, want %q", created.Scopes, tt.wantScopes)
+			}
+			if !strings.HasPrefix(created.Token, domain.APITokenPrefix) || !strings.HasPrefix(created.Token, created.Prefix) {
+				t.Errorf("token %q does not start with prefix %q", created.Token, created.Prefix)
+			}
+			saved, err := repo.GetByID(created.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.Hash == "" || strings.Contains(saved.Hash, created.Token) {
+				t.Errorf("stored hash %q must not contain the raw token", saved.Hash)
+			}
+		})
+	}
+}
+
+func TestAPITokenAuthenticate(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, svc APITokenService, repo repository.APITokenRepository, token *APITokenCreated) string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			setup: func(t *testing.T, svc APITokenService, repo repository.APITokenRepository, token *APITokenCreated) string {
+				return token.Token
+			},
+		},
+		{
+			name: "unknown",
+			setup: func(t *testing.T, svc APITokenService, repo repository.APITokenRepository, token *APITokenCreated) string {
+				return token.Token + "0"
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			setup: func(t *testing.T, svc APITokenService, repo repository.APITokenRepository, token *APITokenCreated) string {
+				token.ExpireAt = time.Now().Add(-time.Second).Unix()
+				if err := repo.Save(token.APIToken); err != nil {
+					t.Fatal(err)
+				}
+				return token.Token
+			},
+			wantErr: true,
+		},
+		{
+			name: "revoked",
+			setup: func(t *testing.T, svc APITokenService, repo repository.APITokenRepository, token *APITokenCreated) string {
+				if err := svc.Revoke(token.ID); err != nil {
+					t.Fatal(err)
+				}
+				return token.Token
+			},
+			wantErr: true,
+		},
+		{
+			name: "owner deleted",
+			setup: func(t *testing.T, svc APITokenService, repo repository.APITokenRepository, token *APITokenCreated) string {
+				token.UserID = 999
+				if err := repo.Save(token.APIToken); err != nil {
+					t.Fatal(err)
+				}
+				return token.Token
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, user := newAPITokenFixture(t)
+			created, err := svc.Create(user.ID, &APITokenRequest{Name: "ci", Scopes: "read:logs"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw := tt.setup(t, svc, repo, created)
+
+			token, owner, err := svc.Authenticate(raw)
+			if tt.wantErr {
+				var appErr *apperrors.AppError
+				if !errors.As(err, &appErr) || appErr.Code != apperrors.ErrUnauthorized {
+					t.Fatalf("Authenticate() error = %v, want unauthorized", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if owner.ID != user.ID || !token.HasScope(domain.ScopeReadLogs) || token.HasScope(domain.ScopeReadContainers) {
+				t.Errorf("Authenticate() = %+v, %+v", token, owner)
+			}
+			saved, _ := repo.GetByID(created.ID)
+			if saved.LastUsedAt == 0 {
+				t.Error("last used time not recorded")
+			}
+		})
+	}
+}
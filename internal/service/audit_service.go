@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// auditCleanInterval 审计记录的清理间隔
+const auditCleanInterval = time.Hour
+
+// auditMask 敏感字段在审计记录中的替代值
+const auditMask = "******"
+
+// auditIgnoredFields 不计入差异的字段
+var auditIgnoredFields = map[string]bool{
+	"update_at": true,
+}
+
+// auditService 审计服务实现
+type auditService struct {
+	cfg           *config.AuditConfig
+	repo          repository.AuditLogRepository
+	taskRepo      repository.TaskRepository
+	containerRepo repository.ContainerRepository
+	groupRepo     repository.TaskGroupRepository
+	relationRepo  repository.RelationRepository
+	ruleRepo      repository.NotifyRuleRepository
+	webhookRepo   repository.WebhookRepository
+	userRepo      repository.UserRepository
+	bindingRepo   repository.RoleBindingRepository
+	tokenRepo     repository.APITokenRepository
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAuditService 创建审计服务
+func NewAuditService(
+	cfg *config.AuditConfig,
+	repo repository.AuditLogRepository,
+	taskRepo repository.TaskRepository,
+	containerRepo repository.ContainerRepository,
+	groupRepo repository.TaskGroupRepository,
+	relationRepo repository.RelationRepository,
+	ruleRepo repository.NotifyRuleRepository,
+	webhookRepo repository.WebhookRepository,
+	userRepo repository.UserRepository,
+	bindingRepo repository.RoleBindingRepository,
+	tokenRepo repository.APITokenRepository,
+) AuditService {
+	return &auditService{
+		cfg:           cfg,
+		repo:          repo,
+		taskRepo:      taskRepo,
+		containerRepo: containerRepo,
+		groupRepo:     groupRepo,
+		relationRepo:  relationRepo,
+		ruleRepo:      ruleRepo,
+		webhookRepo:   webhookRepo,
+		userRepo:      userRepo,
+		bindingRepo:   bindingRepo,
+		tokenRepo:     tokenRepo,
+	}
+}
+
+// Start 开始定期清理超过保留天数的审计记录
+func (s *auditService) Start() {
+	if s.cfg.MaxAge <= 0 || s.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(auditCleanInterval)
+		defer ticker.Stop()
+		s.clean()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.clean()
+			}
+		}
+	}()
+}
+
+// Stop 停止清理
+func (s *auditService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+}
+
+// clean 删除超过保留天数的审计记录
+func (s *auditService) clean() {
+	before := time.Now().AddDate(0, 0, -s.cfg.MaxAge).Unix()
+	deleted, err := s.repo.DeleteBefore(before)
+	if err != nil {
+		logger.Errorf("[audit] failed to clean audit logs: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Infof("[audit] cleaned %d audit logs older than %d days", deleted, s.cfg.MaxAge)
+	}
+}
+
+// List 分页查询审计记录
+func (s *auditService) List(query *repository.AuditQuery) (*ListResult[*domain.AuditLog], error) {
+	entries, err := s.repo.List(query)
+	if err != nil {
+		return nil, err
+	}
+	return &ListResult[*domain.AuditLog]{
+		Items: entries,
+		Page:  &query.Page,
+	}, nil
+}
+
+// Snapshot 读取对象当前的状态，对象不存在或类型不支持快照时返回 nil
+func (s *auditService) Snapshot(targetType string, id string) (interface{}, error) {
+	if id == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var snapshot interface{}
+	switch targetType {
+	case domain.AuditTargetTask:
+		snapshot, err = s.taskRepo.GetByIDUnscoped(n)
+	case domain.AuditTargetContainer:
+		snapshot, err = s.containerRepo.GetByIDUnscoped(n)
+	case domain.AuditTargetGroup:
+		snapshot, err = s.groupRepo.GetByID(n)
+	case domain.AuditTargetRelation:
+		snapshot, err = s.relationRepo.GetByID(n)
+	case domain.AuditTargetNotifyRule:
+		snapshot, err = s.ruleRepo.GetByID(n)
+	case domain.AuditTargetWebhook:
+		snapshot, err = s.webhookRepo.GetByID(n)
+	case domain.AuditTargetUser:
+		snapshot, err = s.userRepo.GetByID(n)
+	case domain.AuditTargetRole:
+		snapshot, err = s.bindingRepo.GetByID(n)
+	case domain.AuditTargetToken:
+		snapshot, err = s.tokenRepo.GetByID(n)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Record 保存审计记录，before 和 after 为对象修改前后的快照
+//
+// Action 为 save 时根据对象原来是否存在记为 create 或 update。
+func (s *auditService) Record(entry *domain.AuditLog, before interface{}, after interface{}) error {
+	if entry.Action == domain.AuditSave {
+		entry.Action = domain.AuditUpdate
+		if isNilSnapshot(before) {
+			entry.Action = domain.AuditCreate
+		}
+	}
+
+	diff, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+	if len(diff) > 0 {
+		data, err := json.Marshal(diff)
+		if err != nil {
+			return err
+		}
+		entry.Diff = string(data)
+	}
+	return s.repo.Save(entry)
+}
+
+// auditDiff 比较对象修改前后的 JSON 字段，返回有变化的字段，敏感字段的值被替换
+func auditDiff(before interface{}, after interface{}) (map[string]*domain.AuditChange, error) {
+	beforeFields, err := snapshotFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := snapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]*domain.AuditChange)
+	collect := func(key string) {
+		if auditIgnoredFields[key] || diff[key] != nil {
+			return
+		}
+		oldValue, oldOk := beforeFields[key]
+		newValue, newOk := afterFields[key]
+		if oldOk && newOk && reflect.DeepEqual(oldValue, newValue) {
+			return
+		}
+		if isSensitiveField(key) {
+			oldValue, newValue = maskValue(oldValue), maskValue(newValue)
+		}
+		diff[key] = &domain.AuditChange{Before: oldValue, After: newValue}
+	}
+	for key := range beforeFields {
+		collect(key)
+	}
+	for key := range afterFields {
+		collect(key)
+	}
+	return diff, nil
+}
+
+// snapshotFields 将快照转换为 JSON 字段
+func snapshotFields(snapshot interface{}) (map[string]interface{}, error) {
+	if isNilSnapshot(snapshot) {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// isNilSnapshot 判断快照是否为空（包括值为 nil 的指针）
+func isNilSnapshot(snapshot interface{}) bool {
+	if snapshot == nil {
+		return true
+	}
+	v := reflect.ValueOf(snapshot)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// isSensitiveField 判断字段是否包含密钥或密码
+func isSensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "secret") || strings.Contains(key, "password") || strings.Contains(key, "token")
+}
+
+// maskValue 替换敏感字段的非空字符串值
+func maskValue(value interface{}) interface{} {
+	if s, ok := value.(string); ok && s != "" {
+		return auditMask
+	}
+	return value
+}
@@ -1,7 +1,13 @@
 package service
 
 import (
+	"strings"
+	"time"
+
+	"clock/internal/config"
 	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
 	"clock/internal/repository"
 )
 
@@ -10,8 +16,11 @@ type containerService struct {
 	containerRepo repository.ContainerRepository
 	taskRepo      repository.TaskRepository
 	relationRepo  repository.RelationRepository
+	groupRepo     repository.TaskGroupRepository
+	tx            repository.Transactor
 	scheduler     SchedulerService
 	executor      *Executor
+	deleteCfg     *config.DeleteConfig
 }
 
 // NewContainerService 创建容器服务
@@ -19,15 +28,21 @@ func NewContainerService(
 	containerRepo repository.ContainerRepository,
 	taskRepo repository.TaskRepository,
 	relationRepo repository.RelationRepository,
+	groupRepo repository.TaskGroupRepository,
+	tx repository.Transactor,
 	scheduler SchedulerService,
 	executor *Executor,
+	deleteCfg *config.DeleteConfig,
 ) ContainerService {
 	return &containerService{
 		containerRepo: containerRepo,
 		taskRepo:      taskRepo,
 		relationRepo:  relationRepo,
+		groupRepo:     groupRepo,
+		tx:            tx,
 		scheduler:     scheduler,
 		executor:      executor,
+		deleteCfg:     deleteCfg,
 	}
 }
 
@@ -51,6 +66,10 @@ func (s *containerService) List(query *repository.ContainerQuery) (*ListResult[*
 
 // Save 保存容器
 func (s *containerService) Save(container *domain.Container) error {
+	if err := ValidateSla(container); err != nil {
+		return err
+	}
+
 	// 移除旧的调度任务
 	if container.EntryID > 0 {
 		s.scheduler.RemoveJob(container.EntryID)
@@ -68,29 +87,244 @@ func (s *containerService) Save(container *domain.Container) error {
 	return s.containerRepo.Save(container)
 }
 
-// Delete 删除容器（同时删除关联任务）
-func (s *containerService) Delete(cid int) error {
-	container, err := s.containerRepo.GetByID(cid)
+// Delete 删除容器（同时删除关联任务和关系）
+//
+// 启用回收站时容器、任务和关系一并移入回收站；彻底删除或删除回收站中的容器时，
+// 在同一事务中删除关系、任务，并按配置删除执行日志。
+func (s *containerService) Delete(cid int, opts *DeleteOptions) error {
+	container, err := s.containerRepo.GetByIDUnscoped(cid)
 	if err != nil {
 		return err
 	}
 
-	// 移除调度任务
-	s.scheduler.RemoveJob(container.EntryID)
+	purge := opts.Purge || !s.deleteCfg.Trash || container.DeletedAt.Valid
+	cascadeLogs := opts.CascadeLogs || s.deleteCfg.CascadeLogs
 
-	// 删除容器
-	if err := s.containerRepo.Delete(cid); err != nil {
+	err = s.tx.Transaction(func(repos *repository.Repositories) error {
+		if !purge {
+			at := time.Now().UTC().Truncate(time.Millisecond)
+			if err := repos.Container.SoftDelete(cid, at); err != nil {
+				return err
+			}
+			if err := repos.Task.SoftDeleteByCID(cid, at); err != nil {
+				return err
+			}
+			return repos.Relation.SoftDeleteByCID(cid, at)
+		}
+
+		if err := repos.Relation.DeleteByCID(cid); err != nil {
+			return err
+		}
+		if err := repos.Task.DeleteByCID(cid); err != nil {
+			return err
+		}
+		if err := repos.TaskGroup.DeleteByCID(cid); err != nil {
+			return err
+		}
+		if cascadeLogs {
+			if err := repos.TaskLog.DeleteByTimeRange(&repository.LogQuery{Cid: cid}); err != nil {
+				return err
+			}
+		}
+		return repos.Container.Delete(cid)
+	})
+	if err != nil {
 		return err
 	}
 
-	// 删除关联任务
-	if err := s.taskRepo.DeleteByCID(cid); err != nil {
+	// 移除调度任务并取消正在运行的批次
+	s.scheduler.RemoveJob(container.EntryID)
+	s.executor.CancelContainer(cid)
+
+	logger.Infof("[container] container %s (cid=%d) deleted, purge=%v", container.Name, cid, purge)
+	return nil
+}
+
+// Restore 从回收站恢复容器及与其同时删除的任务和关系
+//
+// 调度任务在恢复事务中注册，注册失败时事务回滚，容器仍留在回收站；事务提交失败时移除已注册的调度任务。
+func (s *containerService) Restore(cid int) error {
+	container, err := s.containerRepo.GetByIDUnscoped(cid)
+	if err != nil {
+		return err
+	}
+	if !container.DeletedAt.Valid {
+		return apperrors.InvalidParam("container is not in trash")
+	}
+	if err := ValidateSla(container); err != nil {
 		return err
 	}
 
+	at := container.DeletedAt.Time
+	container.DeletedAt.Valid = false
+	container.EntryID = -1
+	err = s.tx.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Container.Restore(cid); err != nil {
+			return err
+		}
+		if err := repos.Task.RestoreByCID(cid, at); err != nil {
+			return err
+		}
+		if err := repos.Relation.RestoreByCID(cid, at); err != nil {
+			return err
+		}
+
+		// 重新注册调度任务
+		if !container.Disable {
+			if err := s.scheduler.AddJob(container); err != nil {
+				return err
+			}
+		}
+		return repos.Container.Save(container)
+	})
+	if err != nil {
+		s.scheduler.RemoveJob(container.EntryID)
+		return err
+	}
 	return nil
 }
 
+// Clone 深拷贝容器及其任务和关系，新容器默认禁用
+func (s *containerService) Clone(cid int, opts *CloneOptions) (*domain.Container, error) {
+	src, err := s.containerRepo.GetByID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.taskRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	relations, err := s.relationRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.groupRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = src.Name + " (copy)"
+	}
+
+	container := &domain.Container{
+		EntryID:    -1,
+		Name:       name,
+		Expression: src.Expression,
+		Status:     domain.StatusPending,
+		Disable:    true,
+		Blocking:   src.Blocking,
+
+		LogMaxAge:        src.LogMaxAge,
+		LogMaxCount:      src.LogMaxCount,
+		LogFailureMaxAge: src.LogFailureMaxAge,
+		LogMaxSize:       src.LogMaxSize,
+
+		SlaDeadline:    src.SlaDeadline,
+		SlaMaxDuration: src.SlaMaxDuration,
+	}
+	commandReplacer := newReplacer(opts.Command)
+	directoryReplacer := newReplacer(opts.Directory)
+
+	err = s.tx.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Container.Save(container); err != nil {
+			return err
+		}
+
+		// 旧任务组ID -> 新任务组ID，先创建再更新父子关系
+		gidMap := make(map[int]int, len(groups))
+		clonedGroups := make([]*domain.TaskGroup, 0, len(groups))
+		for _, group := range groups {
+			clone := *group
+			clone.Gid = 0
+			clone.Cid = container.Cid
+			clone.ParentGid = 0
+			if err := repos.TaskGroup.Save(&clone); err != nil {
+				return err
+			}
+			gidMap[group.Gid] = clone.Gid
+			clonedGroups = append(clonedGroups, &clone)
+		}
+		for i, group := range groups {
+			if group.ParentGid == 0 {
+				continue
+			}
+			clonedGroups[i].ParentGid = gidMap[group.ParentGid]
+			if err := repos.TaskGroup.Save(clonedGroups[i]); err != nil {
+				return err
+			}
+		}
+
+		// 旧任务ID -> 新任务ID
+		tidMap := make(map[int]int, len(tasks))
+		for _, task := range tasks {
+			clone := *task
+			clone.Tid = 0
+			clone.Cid = container.Cid
+			clone.Gid = gidMap[task.Gid]
+			clone.Status = domain.StatusPending
+			clone.Duration = 0
+			clone.Command = commandReplacer.Replace(task.Command)
+			clone.Directory = directoryReplacer.Replace(task.Directory)
+
+			if err := repos.Task.Save(&clone); err != nil {
+				return err
+			}
+			tidMap[task.Tid] = clone.Tid
+		}
+
+		// remap 映射关系端点，端点为任务组时使用任务组ID映射
+		remap := func(tid, gid int) (int, int, bool) {
+			if gid != 0 {
+				newGid, ok := gidMap[gid]
+				return 0, newGid, ok
+			}
+			newTid, ok := tidMap[tid]
+			return newTid, 0, ok
+		}
+
+		for _, rel := range relations {
+			tid, gid, ok1 := remap(rel.Tid, rel.Gid)
+			nextTid, nextGid, ok2 := remap(rel.NextTid, rel.NextGid)
+			if !ok1 || !ok2 {
+				continue
+			}
+
+			if err := repos.Relation.Save(&domain.Relation{
+				Cid:     container.Cid,
+				Tid:     tid,
+				NextTid: nextTid,
+				Gid:     gid,
+				NextGid: nextGid,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return container, nil
+}
+
+// newReplacer 根据查找/替换规则创建替换器
+func newReplacer(replacements []Replacement) *strings.Replacer {
+	pairs := make([]string, 0, len(replacements)*2)
+	for _, r := range replacements {
+		if r.Find == "" {
+			continue
+		}
+		pairs = append(pairs, r.Find, r.Replace)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
 // Run 执行容器内所有任务
 func (s *containerService) Run(cid int) error {
 	container, err := s.containerRepo.GetByID(cid)
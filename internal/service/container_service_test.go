@@ -0,0 +1,248 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	"clock/internal/repository"
+)
+
+// fakeScheduler 记录注册和移除的调度任务，fail 不为空时注册失败
+type fakeScheduler struct {
+	fail    error
+	next    int
+	removed []int
+}
+
+func (s *fakeScheduler) Start() error { return nil }
+func (s *fakeScheduler) Stop()        {}
+
+func (s *fakeScheduler) AddJob(container *domain.Container) error {
+	if s.fail != nil {
+		return s.fail
+	}
+	s.next++
+	container.EntryID = s.next
+	return nil
+}
+
+func (s *fakeScheduler) RemoveJob(entryID int) {
+	if entryID > 0 {
+		s.removed = append(s.removed, entryID)
+	}
+}
+
+// failingTransactor 执行完 fn 后返回 err，使事务回滚
+type failingTransactor struct {
+	repository.Transactor
+	err error
+}
+
+func (t *failingTransactor) Transaction(fn func(repos *repository.Repositories) error) error {
+	return t.Transactor.Transaction(func(repos *repository.Repositories) error {
+		if err := fn(repos); err != nil {
+			return err
+		}
+		return t.err
+	})
+}
+
+// containerFixture 测试用的容器服务及仓储
+type containerFixture struct {
+	containers repository.ContainerRepository
+	tasks      repository.TaskRepository
+	relations  repository.RelationRepository
+	groups     repository.TaskGroupRepository
+	logs       repository.TaskLogRepository
+	tx         repository.Transactor
+	scheduler  *fakeScheduler
+	executor   *Executor
+	cid        int
+}
+
+func newContainerFixture(t *testing.T) *containerFixture {
+	t.Helper()
+	db := newTestDB(t)
+
+	f := &containerFixture{
+		containers: repository.NewContainerRepository(db),
+		tasks:      repository.NewTaskRepository(db),
+		relations:  repository.NewRelationRepository(db),
+		groups:     repository.NewTaskGroupRepository(db),
+		logs:       repository.NewTaskLogRepository(db, nil),
+		tx:         repository.NewTransactor(db, nil),
+		scheduler:  &fakeScheduler{},
+	}
+	f.executor = NewExecutor(f.tasks, f.relations, f.logs, f.containers, repository.NewTaskRunRepository(db),
+		f.groups, NewStreamHub(16, 16), &config.TaskLogConfig{}, &config.MaskingConfig{})
+
+	container := &domain.Container{Name: "c", Expression: "0 0 * * * *", EntryID: -1}
+	if err := f.containers.Save(container); err != nil {
+		t.Fatal(err)
+	}
+	f.cid = container.Cid
+	first := &domain.Task{Cid: f.cid, Name: "a"}
+	second := &domain.Task{Cid: f.cid, Name: "b"}
+	for _, task := range []*domain.Task{first, second} {
+		if err := f.tasks.Save(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.relations.Save(&domain.Relation{Cid: f.cid, Tid: first.Tid, NextTid: second.Tid}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.logs.Save(&domain.TaskLog{Lid: "lid", Cid: f.cid, Tid: first.Tid}); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *containerFixture) service(tx repository.Transactor, deleteCfg *config.DeleteConfig) ContainerService {
+	return NewContainerService(f.containers, f.tasks, f.relations, f.groups, tx, f.scheduler, f.executor, deleteCfg)
+}
+
+// assertIntact 检查容器、任务、关系和日志均未删除
+func (f *containerFixture) assertIntact(t *testing.T) {
+	t.Helper()
+	if _, err := f.containers.GetByID(f.cid); err != nil {
+		t.Errorf("container: %v", err)
+	}
+	if tasks, err := f.tasks.GetByCID(f.cid); err != nil || len(tasks) != 2 {
+		t.Errorf("tasks = %d, %v, want 2", len(tasks), err)
+	}
+	if relations, err := f.relations.GetByCID(f.cid); err != nil || len(relations) != 1 {
+		t.Errorf("relations = %d, %v, want 1", len(relations), err)
+	}
+	if _, err := f.logs.GetByID("lid"); err != nil {
+		t.Errorf("log: %v", err)
+	}
+}
+
+func TestContainerDeleteRollback(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.DeleteConfig
+		opts *DeleteOptions
+	}{
+		{name: "trash", cfg: &config.DeleteConfig{Trash: true}, opts: &DeleteOptions{}},
+		{name: "purge with logs", cfg: &config.DeleteConfig{}, opts: &DeleteOptions{Purge: true, CascadeLogs: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newContainerFixture(t)
+			container, _ := f.containers.GetByID(f.cid)
+			container.EntryID = 7
+			if err := f.containers.Save(container); err != nil {
+				t.Fatal(err)
+			}
+
+			svc := f.service(&failingTransactor{Transactor: f.tx, err: errors.New("commit failed")}, tt.cfg)
+			if err := svc.Delete(f.cid, tt.opts); err == nil {
+				t.Fatal("Delete() succeeded, want error")
+			}
+			f.assertIntact(t)
+			if len(f.scheduler.removed) > 0 {
+				t.Errorf("removed jobs %v after rollback", f.scheduler.removed)
+			}
+
+			if err := f.service(f.tx, tt.cfg).Delete(f.cid, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.containers.GetByID(f.cid); err == nil {
+				t.Error("container still visible after delete")
+			}
+			if len(f.scheduler.removed) != 1 || f.scheduler.removed[0] != 7 {
+				t.Errorf("removed jobs = %v, want [7]", f.scheduler.removed)
+			}
+		})
+	}
+}
+
+func TestContainerRestore(t *testing.T) {
+	tests := []struct {
+		name        string
+		schedule    error
+		commit      error
+		wantRemoved []int
+	}{
+		{name: "restored", wantRemoved: nil},
+		{name: "schedule failure", schedule: errors.New("bad expression")},
+		{name: "commit failure", commit: errors.New("commit failed"), wantRemoved: []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newContainerFixture(t)
+			cfg := &config.DeleteConfig{Trash: true}
+			if err := f.service(f.tx, cfg).Delete(f.cid, &DeleteOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			f.scheduler.fail = tt.schedule
+			tx := f.tx
+			if tt.commit != nil {
+				tx = &failingTransactor{Transactor: f.tx, err: tt.commit}
+			}
+			err := f.service(tx, cfg).Restore(f.cid)
+			failed := tt.schedule != nil || tt.commit != nil
+			if (err != nil) != failed {
+				t.Fatalf("Restore() error = %v", err)
+			}
+
+			if failed {
+				container, err := f.containers.GetByIDUnscoped(f.cid)
+				if err != nil || !container.DeletedAt.Valid {
+					t.Errorf("container left trash after failed restore: %v", err)
+				}
+				if tasks, _ := f.tasks.GetByCID(f.cid); len(tasks) != 0 {
+					t.Errorf("tasks restored = %d, want 0", len(tasks))
+				}
+			} else {
+				f.assertIntact(t)
+				container, _ := f.containers.GetByID(f.cid)
+				if container.EntryID != 1 {
+					t.Errorf("EntryID = %d, want 1", container.EntryID)
+				}
+			}
+			if !equalInts(f.scheduler.removed, tt.wantRemoved) {
+				t.Errorf("removed jobs = %v, want %v", f.scheduler.removed, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestContainerCloneSettings(t *testing.T) {
+	f := newContainerFixture(t)
+	src, _ := f.containers.GetByID(f.cid)
+	src.LogMaxAge, src.LogMaxCount, src.LogFailureMaxAge = 7, 20, 30
+	src.SlaDeadline, src.SlaMaxDuration = "06:00", 3600
+	if err := f.containers.Save(src); err != nil {
+		t.Fatal(err)
+	}
+
+	clone, err := f.service(f.tx, &config.DeleteConfig{}).Clone(f.cid, &CloneOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := [...]interface{}{clone.LogMaxAge, clone.LogMaxCount, clone.LogFailureMaxAge, clone.SlaDeadline, clone.SlaMaxDuration}
+	want := [...]interface{}{7, 20, 30, "06:00", 3600}
+	if got != want {
+		t.Errorf("cloned settings = %v, want %v", got, want)
+	}
+	if !clone.Disable || clone.Name != "c (copy)" {
+		t.Errorf("clone disable=%v name=%q, want disabled copy", clone.Disable, clone.Name)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
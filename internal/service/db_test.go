@@ -0,0 +1,24 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// newTestDB 创建测试用的 sqlite 数据库，测试结束后随临时目录删除
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	logger.Init(&logger.Config{Level: "error"})
+	db, err := repository.NewDB(&config.StorageConfig{Backend: domain.DBBackendSQLite, Conn: filepath.Join(t.TempDir(), "t.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
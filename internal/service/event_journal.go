@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// 事件日志写入参数
+const (
+	journalBatchSize     = 500
+	journalFlushInterval = time.Second
+	journalCleanInterval = time.Hour
+	journalReplayWait    = 5 * time.Second // 回放刚结束的批次时等待结束事件写入的最长时间
+)
+
+// ReplayOptions 批次回放参数
+type ReplayOptions struct {
+	Speed  float64       // 回放速度倍数，0 不等待直接输出
+	Filter *StreamFilter // 事件过滤条件
+	LastID int64         // 从该事件之后继续回放（断线重连）
+}
+
+// eventJournal 批次事件日志服务实现
+type eventJournal struct {
+	cfg      *config.JournalConfig
+	repo     repository.RunEventRepository
+	hub      *StreamHub
+	executor *Executor
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	flushedID int64         // 已写入的最后一个事件流 ID
+	flushed   chan struct{} // 每次写入后关闭并替换，通知等待的回放；为 nil 表示未启动记录
+}
+
+// NewEventJournal 创建批次事件日志服务
+func NewEventJournal(
+	cfg *config.JournalConfig,
+	repo repository.RunEventRepository,
+	hub *StreamHub,
+	executor *Executor,
+) EventJournal {
+	return &eventJournal{
+		cfg:      cfg,
+		repo:     repo,
+		hub:      hub,
+		executor: executor,
+	}
+}
+
+// Start 开始记录事件，未启用时不记录
+func (j *eventJournal) Start() {
+	if !j.cfg.Enable || j.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	j.mu.Lock()
+	j.flushed = make(chan struct{})
+	j.mu.Unlock()
+
+	// 启动时即订阅，不遗漏启动后立即发布的事件
+	filter := &StreamFilter{Kinds: []string{"run_start", "run_end", "task_start", "task_end", "meta", "sla_miss", "anomaly"}}
+	if j.cfg.Output {
+		filter.Kinds = append(filter.Kinds, domain.LogStreamStdout, domain.LogStreamStderr)
+	}
+	events := j.hub.Subscribe(ctx, filter, 0)
+
+	logger.Infof("[journal] event journal started, output=%v", j.cfg.Output)
+	go j.record(ctx, filter, events)
+}
+
+// Stop 停止记录并写入缓存的事件
+func (j *eventJournal) Stop() {
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	<-j.done
+	j.cancel = nil
+	j.mu.Lock()
+	j.flushed = nil
+	j.mu.Unlock()
+}
+
+// record 订阅事件流并批量写入
+func (j *eventJournal) record(ctx context.Context, filter *StreamFilter, events <-chan StreamEvent) {
+	defer close(j.done)
+
+	flushTicker := time.NewTicker(journalFlushInterval)
+	defer flushTicker.Stop()
+	cleanTicker := time.NewTicker(journalCleanInterval)
+	defer cleanTicker.Stop()
+	j.clean()
+
+	var pending []*domain.RunEvent
+	var lastID int64
+	flush := func() {
+		if err := j.repo.SaveBatch(pending); err != nil {
+			logger.Errorf("[journal] failed to save %d events: %v", len(pending), err)
+		}
+		pending = pending[:0]
+		j.markFlushed(lastID)
+	}
+	defer flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				// 写入过慢被断开订阅，从最后收到的事件处继续
+				flush()
+				logger.Warnf("[journal] subscription dropped, resuming from event %d", lastID)
+				events = j.hub.Subscribe(ctx, filter, lastID)
+				continue
+			}
+			lastID = ev.ID
+			if ev.Kind == "gap" {
+				logger.Warnf("[journal] %s", ev.Msg)
+				continue
+			}
+			// 单独运行的任务没有批次ID，不记录
+			if ev.RunID == "" {
+				continue
+			}
+			pending = append(pending, &domain.RunEvent{
+				RunID:      ev.RunID,
+				Ts:         ev.TS,
+				Kind:       ev.Kind,
+				Tid:        ev.Tid,
+				Cid:        ev.Cid,
+				TaskName:   ev.TaskName,
+				Status:     ev.Status,
+				DurationMs: ev.DurationMs,
+				Msg:        ev.Msg,
+			})
+			// 批次结束时立即写入，使回放能读到完整的事件
+			if ev.Kind == "run_end" || len(pending) >= journalBatchSize {
+				flush()
+			}
+		case <-flushTicker.C:
+			flush()
+		case <-cleanTicker.C:
+			j.clean()
+		}
+	}
+}
+
+// markFlushed 记录已写入到的事件流 ID 并通知等待的回放
+func (j *eventJournal) markFlushed(id int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if id > j.flushedID {
+		j.flushedID = id
+	}
+	close(j.flushed)
+	j.flushed = make(chan struct{})
+}
+
+// waitFlushed 等待事件流 ID 不大于 id 的事件写入，超时或 ctx 结束时返回
+func (j *eventJournal) waitFlushed(ctx context.Context, id int64) {
+	timer := time.NewTimer(journalReplayWait)
+	defer timer.Stop()
+	for {
+		j.mu.Lock()
+		flushed, done := j.flushed, j.flushed == nil || j.flushedID >= id
+		j.mu.Unlock()
+		if done {
+			return
+		}
+		select {
+		case <-flushed:
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// clean 删除超过保留天数的事件
+func (j *eventJournal) clean() {
+	if j.cfg.MaxAge <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -j.cfg.MaxAge).UnixMilli()
+	deleted, err := j.repo.DeleteBefore(before)
+	if err != nil {
+		logger.Errorf("[journal] failed to clean events: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Infof("[journal] cleaned %d events older than %d days", deleted, j.cfg.MaxAge)
+	}
+}
+
+// Events 获取批次的全部事件
+func (j *eventJournal) Events(runID string) ([]*domain.RunEvent, error) {
+	return j.repo.ListByRunID(runID, 0)
+}
+
+// Replay 按原始顺序回放已结束批次的事件，Speed > 0 时按原始时间间隔（除以倍数）等待
+//
+// 事件批量写入，批次刚结束时等待其结束事件写入后再读取，避免回放的事件不完整。
+func (j *eventJournal) Replay(ctx context.Context, runID string, opts *ReplayOptions) (<-chan StreamEvent, error) {
+	if j.executor.IsRunActive(runID) {
+		return nil, apperrors.InvalidParam("run is still running, follow it instead")
+	}
+	if opts.Speed < 0 {
+		return nil, apperrors.InvalidParam("speed must not be negative")
+	}
+
+	for _, ev := range j.hub.History(&StreamFilter{RunID: runID, Kinds: []string{"run_end"}}, 0) {
+		if ev.Kind == "run_end" {
+			j.waitFlushed(ctx, ev.ID)
+		}
+	}
+
+	records, err := j.repo.ListByRunID(runID, opts.LastID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 && opts.LastID == 0 {
+		return nil, apperrors.NotFound("run events")
+	}
+
+	out := make(chan StreamEvent)
+	go func() {
+		defer close(out)
+
+		var prevTs int64
+		for _, record := range records {
+			// 间隔按相邻事件计算，被过滤的事件同样占用时间
+			if opts.Speed > 0 && prevTs > 0 && record.Ts > prevTs {
+				wait := time.Duration(float64(record.Ts-prevTs) / opts.Speed * float64(time.Millisecond))
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+			prevTs = record.Ts
+
+			ev := StreamEvent{
+				ID:         record.ID,
+				TS:         record.Ts,
+				Kind:       record.Kind,
+				RunID:      record.RunID,
+				Tid:        record.Tid,
+				Cid:        record.Cid,
+				TaskName:   record.TaskName,
+				Status:     record.Status,
+				DurationMs: record.DurationMs,
+				Msg:        record.Msg,
+			}
+			if !opts.Filter.Match(&ev) {
+				continue
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"clock/internal/config"
+	"clock/internal/repository"
+)
+
+func TestEventJournalReplayAfterRunEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		events int // run_start 与 run_end 之间的事件数
+	}{
+		{name: "short run", events: 3},
+		{name: "long run", events: journalBatchSize + 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			hub := NewStreamHub(16, 2*journalBatchSize)
+			executor := NewExecutor(nil, nil, nil, nil, nil, nil, hub, &config.TaskLogConfig{}, &config.MaskingConfig{})
+			journal := NewEventJournal(&config.JournalConfig{Enable: true}, repository.NewRunEventRepository(db), hub, executor)
+			journal.Start()
+			defer journal.Stop()
+
+			hub.Publish(StreamEvent{Kind: "run_start", RunID: "r1", Cid: 1})
+			for i := 0; i < tt.events; i++ {
+				hub.Publish(StreamEvent{Kind: "task_start", RunID: "r1", Cid: 1, Tid: i})
+			}
+			hub.Publish(StreamEvent{Kind: "run_end", RunID: "r1", Cid: 1})
+
+			events, err := journal.Replay(context.Background(), "r1", &ReplayOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kinds []string
+			for ev := range events {
+				kinds = append(kinds, ev.Kind)
+			}
+			if len(kinds) != tt.events+2 || kinds[len(kinds)-1] != "run_end" {
+				t.Fatalf("replayed %d events, want %d ending with run_end", len(kinds), tt.events+2)
+			}
+		})
+	}
+}
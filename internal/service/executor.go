@@ -2,7 +2,6 @@ package service
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -13,6 +12,7 @@ import (
 
 	uuid "github.com/nu7hatch/gouuid"
 
+	"clock/internal/config"
 	"clock/internal/domain"
 	"clock/internal/logger"
 	"clock/internal/repository"
@@ -45,7 +45,11 @@ type Executor struct {
 	relationRepo  repository.RelationRepository
 	taskLogRepo   repository.TaskLogRepository
 	containerRepo repository.ContainerRepository
+	taskRunRepo   repository.TaskRunRepository
+	groupRepo     repository.TaskGroupRepository
 	hub           *StreamHub
+	logCfg        *config.TaskLogConfig
+	masker        *secretMasker
 
 	runningMu sync.RWMutex
 	running   map[int]*runningTask // key: tid
@@ -57,6 +61,24 @@ type Executor struct {
 	// runningContainers 用于追踪正在运行的容器，实现阻塞式运行
 	runningContainersMu sync.RWMutex
 	runningContainers   map[int]string // key: cid, value: runID
+
+	// activeRuns 正在执行的批次，用于跟随批次的事件流
+	activeRunsMu sync.RWMutex
+	activeRuns   map[string]struct{}
+
+	// groupRuns 单独执行的任务组批次，用于按任务组取消
+	groupRunsMu sync.RWMutex
+	groupRuns   map[int]groupRun // key: gid
+
+	// skippedTasks 批次中被取消的任务组成员，后续阶段跳过这些任务
+	skippedTasksMu sync.RWMutex
+	skippedTasks   map[string]map[int]struct{} // key: runID
+}
+
+// groupRun 单独执行的任务组批次
+type groupRun struct {
+	cid   int
+	runID string
 }
 
 // NewExecutor 创建执行器
@@ -65,17 +87,28 @@ func NewExecutor(
 	relationRepo repository.RelationRepository,
 	taskLogRepo repository.TaskLogRepository,
 	containerRepo repository.ContainerRepository,
+	taskRunRepo repository.TaskRunRepository,
+	groupRepo repository.TaskGroupRepository,
 	hub *StreamHub,
+	logCfg *config.TaskLogConfig,
+	maskCfg *config.MaskingConfig,
 ) *Executor {
 	return &Executor{
 		taskRepo:          taskRepo,
 		relationRepo:      relationRepo,
 		taskLogRepo:       taskLogRepo,
 		containerRepo:     containerRepo,
+		taskRunRepo:       taskRunRepo,
+		groupRepo:         groupRepo,
 		hub:               hub,
+		logCfg:            logCfg,
+		masker:            newSecretMasker(maskCfg),
 		running:           make(map[int]*runningTask),
 		cancelledRuns:     make(map[string]struct{}),
 		runningContainers: make(map[int]string),
+		activeRuns:        make(map[string]struct{}),
+		groupRuns:         make(map[int]groupRun),
+		skippedTasks:      make(map[string]map[int]struct{}),
 	}
 }
 
@@ -93,8 +126,7 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 		e.cancelledRunsMu.RUnlock()
 		if cancelled {
 			task.Status = domain.StatusCancelled
-			task.UpdateAt = time.Now().Unix()
-			_ = e.taskRepo.Save(task)
+			_ = e.taskRepo.UpdateStatus(task)
 			e.hub.Publish(StreamEvent{
 				Kind:     "task_end",
 				RunID:    runID,
@@ -108,11 +140,10 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 		}
 	}
 
-	var stdOutBuf bytes.Buffer
-	var stdErrBuf bytes.Buffer
-
 	startAt := time.Now()
+	output := e.openOutput(task, runID, startAt)
 	errMsg := ""
+	exitCode := -1
 	cancelled := false
 
 	// 创建可取消的 context
@@ -121,17 +152,13 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 	// 设置开始状态
 	task.Status = domain.StatusStart
 	defer func() {
-		// 从 running map 中移除
-		e.runningMu.Lock()
-		delete(e.running, task.Tid)
-		e.runningMu.Unlock()
-
-		task.UpdateAt = time.Now().Unix()
+		task.Duration = time.Since(startAt).Milliseconds()
 		logger.Debugf("[%d] finished task [%s]", task.Tid, task.Name)
-		_ = e.taskRepo.Save(task)
-		e.saveLog(task, stdOutBuf, stdErrBuf)
+		_ = e.taskRepo.UpdateStatus(task)
+		output.Close(task.Status)
+		e.saveRun(task, runID, startAt, exitCode, errMsg)
 
-		e.hub.Publish(StreamEvent{
+		ev := StreamEvent{
 			Kind:       "task_end",
 			RunID:      runID,
 			Tid:        task.Tid,
@@ -140,7 +167,17 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 			Status:     domain.StatusText(task.Status),
 			DurationMs: time.Since(startAt).Milliseconds(),
 			Msg:        errMsg,
-		})
+		}
+		if exitCode >= 0 {
+			code := exitCode
+			ev.ExitCode = &code
+		}
+		e.hub.Publish(ev)
+
+		// 结束事件发布后再从 running map 中移除，跟随者看到任务结束时结束事件已在缓冲中
+		e.runningMu.Lock()
+		delete(e.running, task.Tid)
+		e.runningMu.Unlock()
 	}()
 
 	if task.Command == "" {
@@ -151,7 +188,7 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 	}
 
 	logger.Debugf("[%d] running task [%s]", task.Tid, task.Name)
-	_ = e.taskRepo.Save(task)
+	_ = e.taskRepo.UpdateStatus(task)
 
 	e.hub.Publish(StreamEvent{
 		Kind:     "task_start",
@@ -208,12 +245,13 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 	var wg sync.WaitGroup
 	wg.Add(2)
 
-	readStream := func(kind string, r *bufio.Scanner, buf *bytes.Buffer) {
+	// 输出先脱敏，再写入日志和推送，原始内容不落盘
+	readStream := func(kind string, r *bufio.Scanner) {
 		defer wg.Done()
+		var state maskState
 		for r.Scan() {
-			line := r.Text()
-			buf.WriteString(line)
-			buf.WriteByte('\n')
+			line := e.masker.Mask(r.Text(), &state)
+			output.WriteLine(kind, line)
 			e.hub.Publish(StreamEvent{
 				Kind:     kind,
 				RunID:    runID,
@@ -224,15 +262,14 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 			})
 		}
 		if scanErr := r.Err(); scanErr != nil {
-			buf.WriteString(scanErr.Error())
-			buf.WriteByte('\n')
+			output.WriteLine(kind, e.masker.Mask(scanErr.Error(), nil))
 			e.hub.Publish(StreamEvent{
 				Kind:     "meta",
 				RunID:    runID,
 				Tid:      task.Tid,
 				Cid:      task.Cid,
 				TaskName: task.Name,
-				Msg:      fmt.Sprintf("%s reader error: %v", kind, scanErr),
+				Msg:      e.masker.Mask(fmt.Sprintf("%s reader error: %v", kind, scanErr), nil),
 			})
 		}
 	}
@@ -242,8 +279,8 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 	stderrScanner := bufio.NewScanner(stderrPipe)
 	stderrScanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 
-	go readStream("stdout", stdoutScanner, &stdOutBuf)
-	go readStream("stderr", stderrScanner, &stdErrBuf)
+	go readStream(domain.LogStreamStdout, stdoutScanner)
+	go readStream(domain.LogStreamStderr, stderrScanner)
 
 	// Wait for process completion (with optional timeout)
 	var waitErr error
@@ -284,6 +321,13 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 	// Ensure readers have drained pipes before we persist logs.
 	wg.Wait()
 
+	var exitErr *exec.ExitError
+	if waitErr == nil {
+		exitCode = 0
+	} else if errors.As(waitErr, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
 	// 优先判断取消状态（取消优先于超时）
 	if cancelled {
 		task.Status = domain.StatusCancelled
@@ -301,8 +345,7 @@ func (e *Executor) RunTaskWithRunID(task *domain.Task, runID string) error {
 
 	if waitErr != nil {
 		task.Status = domain.StatusFailure
-		stdErrBuf.WriteString(waitErr.Error())
-		stdErrBuf.WriteByte('\n')
+		output.WriteLine(domain.LogStreamStderr, e.masker.Mask(waitErr.Error(), nil))
 		errMsg = waitErr.Error()
 		return waitErr
 	}
@@ -364,6 +407,127 @@ func (e *Executor) CancelRun(runID string) error {
 	return nil
 }
 
+// CancelContainer 取消容器所有正在运行的批次和任务
+func (e *Executor) CancelContainer(cid int) {
+	runIDs := make(map[string]struct{})
+
+	e.runningContainersMu.RLock()
+	if runID, ok := e.runningContainers[cid]; ok {
+		runIDs[runID] = struct{}{}
+	}
+	e.runningContainersMu.RUnlock()
+
+	// 单独运行的任务没有 runID，直接取消
+	var toCancel []*runningTask
+	e.runningMu.RLock()
+	for _, rt := range e.running {
+		if rt.cid != cid {
+			continue
+		}
+		if rt.runID != "" {
+			runIDs[rt.runID] = struct{}{}
+		} else {
+			toCancel = append(toCancel, rt)
+		}
+	}
+	e.runningMu.RUnlock()
+
+	for runID := range runIDs {
+		_ = e.CancelRun(runID)
+	}
+	for _, rt := range toCancel {
+		rt.cancel()
+		logger.Infof("task %d cancelled because container %d was deleted", rt.tid, cid)
+	}
+}
+
+// CancelGroup 取消任务组，tids 为任务组（含嵌套子组）内的任务
+//
+// 单独执行的任务组取消整个批次；任务组在容器或其他任务组的批次中执行时，
+// 取消正在运行的成员，并在这些批次的后续阶段跳过其余成员。
+func (e *Executor) CancelGroup(group *domain.TaskGroup, tids []int) {
+	member := make(map[int]bool, len(tids))
+	for _, tid := range tids {
+		member[tid] = true
+	}
+
+	e.groupRunsMu.RLock()
+	own, ok := e.groupRuns[group.Gid]
+	var runIDs []string
+	for gid, run := range e.groupRuns {
+		if gid != group.Gid && run.cid == group.Cid {
+			runIDs = append(runIDs, run.runID)
+		}
+	}
+	e.groupRunsMu.RUnlock()
+	if ok {
+		_ = e.CancelRun(own.runID)
+	}
+
+	e.runningContainersMu.RLock()
+	if runID, ok := e.runningContainers[group.Cid]; ok {
+		runIDs = append(runIDs, runID)
+	}
+	e.runningContainersMu.RUnlock()
+
+	var toCancel []*runningTask
+	e.runningMu.RLock()
+	for _, rt := range e.running {
+		if member[rt.tid] {
+			toCancel = append(toCancel, rt)
+			if rt.runID != "" {
+				runIDs = append(runIDs, rt.runID)
+			}
+		}
+	}
+	e.runningMu.RUnlock()
+
+	// 先标记跳过再取消运行中的成员，避免取消后后续阶段仍执行其余成员
+	e.skippedTasksMu.Lock()
+	for _, runID := range runIDs {
+		if e.skippedTasks[runID] == nil {
+			e.skippedTasks[runID] = make(map[int]struct{})
+		}
+		for _, tid := range tids {
+			e.skippedTasks[runID][tid] = struct{}{}
+		}
+	}
+	e.skippedTasksMu.Unlock()
+
+	for _, rt := range toCancel {
+		rt.cancel()
+		logger.Infof("task %d (runID: %s) cancelled with group %d", rt.tid, rt.runID, group.Gid)
+	}
+}
+
+// isTaskSkipped 检查任务是否在批次中被跳过
+func (e *Executor) isTaskSkipped(runID string, tid int) bool {
+	e.skippedTasksMu.RLock()
+	defer e.skippedTasksMu.RUnlock()
+	_, ok := e.skippedTasks[runID][tid]
+	return ok
+}
+
+// skipTask 将批次中被跳过的任务标记为已取消
+func (e *Executor) skipTask(tid int, runID string) error {
+	task, err := e.taskRepo.GetByID(tid)
+	if err != nil {
+		return err
+	}
+	task.Status = domain.StatusCancelled
+	_ = e.taskRepo.UpdateStatus(task)
+	e.hub.Publish(StreamEvent{
+		Kind:     "task_end",
+		RunID:    runID,
+		Tid:      task.Tid,
+		Cid:      task.Cid,
+		TaskName: task.Name,
+		Status:   "cancelled",
+		Msg:      "group was cancelled",
+	})
+	return errors.New("group was cancelled")
+}
+
 // GetRunningTasks 获取正在运行的任务列表
 func (e *Executor) GetRunningTasks() []RunningTaskInfo {
 	e.runningMu.RLock()
@@ -390,6 +554,43 @@ func (e *Executor) IsContainerRunning(cid int) bool {
 	return ok
 }
 
+// IsRunActive 检查批次是否正在执行
+func (e *Executor) IsRunActive(runID string) bool {
+	e.activeRunsMu.RLock()
+	defer e.activeRunsMu.RUnlock()
+	_, ok := e.activeRuns[runID]
+	return ok
+}
+
+// RunCid 返回正在执行的批次所属的容器
+func (e *Executor) RunCid(runID string) (int, bool) {
+	e.runningContainersMu.RLock()
+	for cid, id := range e.runningContainers {
+		if id == runID {
+			e.runningContainersMu.RUnlock()
+			return cid, true
+		}
+	}
+	e.runningContainersMu.RUnlock()
+
+	e.runningMu.RLock()
+	defer e.runningMu.RUnlock()
+	for _, rt := range e.running {
+		if rt.runID == runID {
+			return rt.cid, true
+		}
+	}
+	return 0, false
+}
+
+// IsTaskRunning 检查任务是否正在执行
+func (e *Executor) IsTaskRunning(tid int) bool {
+	e.runningMu.RLock()
+	defer e.runningMu.RUnlock()
+	_, ok := e.running[tid]
+	return ok
+}
+
 // registerRunningContainer 注册运行中的容器
 func (e *Executor) registerRunningContainer(cid int, runID string) {
 	e.runningContainersMu.Lock()
@@ -421,8 +622,8 @@ func (e *Executor) RunTaskByIDWithRunID(tid int, runID string) error {
 		return err
 	}
 
-	// 检查前置任务是否都已成功
-	relations, err := e.relationRepo.GetByCID(task.Cid)
+	// 检查前置任务是否都已成功（任务组端点展开为具体任务）
+	relations, err := e.loadRelations(task.Cid)
 	if err != nil {
 		return err
 	}
@@ -445,7 +646,7 @@ func (e *Executor) RunTaskByIDWithRunID(tid int, runID string) error {
 			// 如果有前置任务失败、等待中或已取消，当前任务设为等待
 			if preTask.Status == domain.StatusFailure || preTask.Status == domain.StatusPending || preTask.Status == domain.StatusCancelled {
 				task.Status = domain.StatusPending
-				_ = e.taskRepo.Save(task)
+				_ = e.taskRepo.UpdateStatus(task)
 				return nil
 			}
 		}
@@ -473,35 +674,162 @@ func (e *Executor) RunContainer(container *domain.Container, tasks []*domain.Tas
 
 	// 设置容器开始状态并保存
 	container.Status = domain.StatusStart
-	container.UpdateAt = time.Now().Unix()
-	_ = e.containerRepo.Save(container)
+	_ = e.containerRepo.UpdateStatus(container)
 
 	defer func() {
 		container.Status = domain.StatusPending
-		container.UpdateAt = time.Now().Unix()
-		_ = e.containerRepo.Save(container)
+		_ = e.containerRepo.UpdateStatus(container)
 	}()
 
 	// 重置所有任务状态为 Pending，确保干净的执行环境
 	for _, task := range tasks {
 		task.Status = domain.StatusPending
-		_ = e.taskRepo.Save(task)
+		_ = e.taskRepo.UpdateStatus(task)
+	}
+
+	groups, err := e.groupRepo.GetByCID(container.Cid)
+	if err != nil {
+		return err
+	}
+
+	e.runStageTasksWithRunID(tasks, groups, relations, runID)
+	return nil
+}
+
+// RunGroup 重新执行任务组（含嵌套子组）内的所有任务
+func (e *Executor) RunGroup(group *domain.TaskGroup) error {
+	tasks, err := e.taskRepo.GetByCID(group.Cid)
+	if err != nil {
+		return err
+	}
+
+	groups, err := e.groupRepo.GetByCID(group.Cid)
+	if err != nil {
+		return err
+	}
+
+	relations, err := e.relationRepo.GetByCID(group.Cid)
+	if err != nil {
+		return err
+	}
+
+	members := newGroupTree(groups).members(group.Gid, tasks)
+	if len(members) == 0 {
+		return nil
+	}
+
+	runID := genGUID(8)
+	logger.Infof("[executor] retry group %s (gid=%d) with runID %s", group.Name, group.Gid, runID)
+
+	e.groupRunsMu.Lock()
+	e.groupRuns[group.Gid] = groupRun{cid: group.Cid, runID: runID}
+	e.groupRunsMu.Unlock()
+	defer func() {
+		e.groupRunsMu.Lock()
+		if e.groupRuns[group.Gid].runID == runID {
+			delete(e.groupRuns, group.Gid)
+		}
+		e.groupRunsMu.Unlock()
+	}()
+
+	for _, task := range members {
+		task.Status = domain.StatusPending
+		_ = e.taskRepo.UpdateStatus(task)
 	}
 
-	e.runStageTasksWithRunID(tasks, relations, runID)
+	// 展开时需要完整的任务列表以正确计算嵌套任务组的入口/出口
+	relations = expandGroupRelations(tasks, groups, relations)
+	e.runStageTasksWithRunID(members, nil, relations, runID)
 	return nil
 }
 
+// loadRelations 获取容器内任务级别的依赖关系（展开任务组端点）
+func (e *Executor) loadRelations(cid int) ([]*domain.Relation, error) {
+	relations, err := e.relationRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := e.groupRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return relations, nil
+	}
+
+	tasks, err := e.taskRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+	return expandGroupRelations(tasks, groups, relations), nil
+}
+
 // runStageTasksWithRunID 按阶段执行任务，带 runID
-func (e *Executor) runStageTasksWithRunID(tasks []*domain.Task, relations []*domain.Relation, runID string) {
+//
+// 以任务组为端点的关系在此展开为组的入口/出口任务，
+// 端点不在本次执行任务列表中的关系会被忽略。
+func (e *Executor) runStageTasksWithRunID(tasks []*domain.Task, groups []*domain.TaskGroup, relations []*domain.Relation, runID string) {
 	stage := 0
+	startAt := time.Now()
+	failed := false
+
+	cid := 0
+	if len(tasks) > 0 {
+		cid = tasks[0].Cid
+	}
+
+	e.activeRunsMu.Lock()
+	e.activeRuns[runID] = struct{}{}
+	e.activeRunsMu.Unlock()
+
+	e.hub.Publish(StreamEvent{Kind: "run_start", RunID: runID, Cid: cid, Msg: "running"})
+
+	defer func() {
+		e.cancelledRunsMu.RLock()
+		_, cancelled := e.cancelledRuns[runID]
+		e.cancelledRunsMu.RUnlock()
+
+		status := domain.StatusSuccess
+		if cancelled {
+			status = domain.StatusCancelled
+		} else if failed {
+			status = domain.StatusFailure
+		}
+		e.hub.Publish(StreamEvent{
+			Kind:       "run_end",
+			RunID:      runID,
+			Cid:        cid,
+			Status:     domain.StatusText(status),
+			DurationMs: time.Since(startAt).Milliseconds(),
+		})
+
+		// 结束事件发布后再标记批次结束，跟随者看到批次结束时结束事件已在缓冲中
+		e.activeRunsMu.Lock()
+		delete(e.activeRuns, runID)
+		e.activeRunsMu.Unlock()
+
+		e.skippedTasksMu.Lock()
+		delete(e.skippedTasks, runID)
+		e.skippedTasksMu.Unlock()
+	}()
 
-	// 复制任务和关系列表
+	// 复制任务列表
 	taskList := make([]*domain.Task, len(tasks))
 	copy(taskList, tasks)
 
-	relationList := make([]*domain.Relation, len(relations))
-	copy(relationList, relations)
+	inRun := make(map[int]bool, len(tasks))
+	for _, task := range tasks {
+		inRun[task.Tid] = true
+	}
+
+	// 展开任务组并过滤关系
+	relationList := make([]*domain.Relation, 0, len(relations))
+	for _, rel := range expandGroupRelations(tasks, groups, relations) {
+		if inRun[rel.Tid] && inRun[rel.NextTid] {
+			relationList = append(relationList, rel)
+		}
+	}
 
 	for {
 		// 检查该 runID 是否已被取消
@@ -551,7 +879,13 @@ func (e *Executor) runStageTasksWithRunID(tasks []*domain.Task, relations []*dom
 
 		// 执行当前阶段的任务
 		for _, tid := range rootTids {
-			if err := e.RunTaskByIDWithRunID(tid, runID); err != nil {
+			// 所属任务组已取消的任务不再执行
+			run := e.RunTaskByIDWithRunID
+			if e.isTaskSkipped(runID, tid) {
+				run = e.skipTask
+			}
+			if err := run(tid, runID); err != nil {
+				failed = true
 				logger.Errorf("[executor] task %d failed: %v", tid, err)
 			}
 		}
@@ -570,20 +904,49 @@ func (e *Executor) runStageTasksWithRunID(tasks []*domain.Task, relations []*dom
 	}
 }
 
-// saveLog 保存执行日志
-func (e *Executor) saveLog(task *domain.Task, stdOut, stdErr bytes.Buffer) {
-	// 保存日志到数据库
-	if task.LogEnable {
-		lid := genGUID(8)
-		log := &domain.TaskLog{
-			Lid:      lid,
-			Tid:      task.Tid,
-			Cid:      task.Cid,
-			StdOut:   stdOut.String(),
-			StdErr:   stdErr.String(),
-			UpdateAt: time.Now().Unix(),
-		}
-		_ = e.taskLogRepo.Save(log)
+// openOutput 创建任务输出写入器，未启用日志时返回 nil
+func (e *Executor) openOutput(task *domain.Task, runID string, startAt time.Time) *outputWriter {
+	if !task.LogEnable {
+		return nil
+	}
+
+	log := &domain.TaskLog{
+		Lid:      genGUID(8),
+		RunID:    runID,
+		Tid:      task.Tid,
+		Cid:      task.Cid,
+		StartAt:  startAt.UnixMilli(),
+		UpdateAt: startAt.Unix(),
+	}
+	w, err := e.taskLogRepo.Create(log)
+	if err != nil {
+		logger.Errorf("[%d] failed to create log: %v", task.Tid, err)
+		return nil
+	}
+
+	maxOutput := e.logCfg.MaxOutput
+	if task.MaxOutput > 0 {
+		maxOutput = task.MaxOutput
+	}
+	return newOutputWriter(w, log, int64(maxOutput)*1024)
+}
+
+// saveRun 保存执行记录
+func (e *Executor) saveRun(task *domain.Task, runID string, startAt time.Time, exitCode int, errMsg string) {
+	endAt := time.Now()
+	run := &domain.TaskRun{
+		RunID:    runID,
+		Tid:      task.Tid,
+		Cid:      task.Cid,
+		Status:   task.Status,
+		ExitCode: exitCode,
+		StartAt:  startAt.UnixMilli(),
+		EndAt:    endAt.UnixMilli(),
+		Duration: endAt.Sub(startAt).Milliseconds(),
+		Msg:      errMsg,
+	}
+	if err := e.taskRunRepo.Save(run); err != nil {
+		logger.Errorf("[executor] failed to save run of task %d: %v", task.Tid, err)
 	}
 }
 
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"clock/internal/domain"
+)
+
+// GraphExportOptions 关系图导出参数
+type GraphExportOptions struct {
+	Format       string // dot, mermaid, svg
+	WithStatus   bool   // 按最近一次状态着色
+	WithDuration bool   // 标注最近一次执行耗时
+}
+
+// SVG 布局参数
+const (
+	svgNodeWidth  = 160
+	svgNodeHeight = 40
+	svgColGap     = 80
+	svgRowGap     = 30
+	svgPadding    = 20
+)
+
+// statusColor 返回状态对应的填充颜色
+func statusColor(status int) string {
+	switch status {
+	case domain.StatusPending:
+		return "#909399"
+	case domain.StatusStart:
+		return "#00ccff"
+	case domain.StatusSuccess:
+		return "#00ff88"
+	case domain.StatusFailure:
+		return "#ff4466"
+	case domain.StatusCancelled:
+		return "#e6a23c"
+	default:
+		return "#ffffff"
+	}
+}
+
+// nodeLabel 生成节点标签（任务名，可选附加耗时）
+func nodeLabel(task *domain.Task, opts *GraphExportOptions) (string, string) {
+	if opts.WithDuration && task.Duration > 0 {
+		return task.Name, (time.Duration(task.Duration) * time.Millisecond).String()
+	}
+	return task.Name, ""
+}
+
+// exportDOT 导出 Graphviz DOT 格式
+func exportDOT(cid int, tasks []*domain.Task, relations []*domain.Relation, opts *GraphExportOptions) []byte {
+	escape := func(s string) string {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		return strings.ReplaceAll(s, `"`, `\"`)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "digraph container_%d {\n", cid)
+	buf.WriteString("  rankdir=LR;\n")
+	buf.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];\n")
+
+	for _, task := range tasks {
+		name, duration := nodeLabel(task, opts)
+		label := escape(name)
+		if duration != "" {
+			label += `\n` + escape(duration)
+		}
+		attrs := fmt.Sprintf("label=\"%s\"", label)
+		if opts.WithStatus {
+			attrs += fmt.Sprintf(", fillcolor=\"%s\", tooltip=\"%s\"", statusColor(task.Status), domain.StatusText(task.Status))
+		}
+		fmt.Fprintf(&buf, "  t%d [%s];\n", task.Tid, attrs)
+	}
+
+	for _, rel := range relations {
+		fmt.Fprintf(&buf, "  t%d -> t%d;\n", rel.Tid, rel.NextTid)
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// exportMermaid 导出 Mermaid flowchart 格式
+func exportMermaid(tasks []*domain.Task, relations []*domain.Relation, opts *GraphExportOptions) []byte {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, `"`, "#quot;")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("flowchart LR\n")
+
+	for _, task := range tasks {
+		name, duration := nodeLabel(task, opts)
+		label := escape(name)
+		if duration != "" {
+			label += "<br/>" + escape(duration)
+		}
+		fmt.Fprintf(&buf, "  t%d[\"%s\"]\n", task.Tid, label)
+	}
+
+	for _, rel := range relations {
+		fmt.Fprintf(&buf, "  t%d --> t%d\n", rel.Tid, rel.NextTid)
+	}
+
+	if opts.WithStatus {
+		used := make(map[int][]string)
+		for _, task := range tasks {
+			used[task.Status] = append(used[task.Status], fmt.Sprintf("t%d", task.Tid))
+		}
+
+		statuses := make([]int, 0, len(used))
+		for status := range used {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+
+		for _, status := range statuses {
+			class := domain.StatusText(status)
+			fmt.Fprintf(&buf, "  classDef %s fill:%s\n", class, statusColor(status))
+			fmt.Fprintf(&buf, "  class %s %s\n", strings.Join(used[status], ","), class)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// exportSVG 服务端渲染 SVG，无需依赖 Graphviz
+func exportSVG(tasks []*domain.Task, relations []*domain.Relation, opts *GraphExportOptions) []byte {
+	positions := layoutGraph(tasks, relations)
+
+	width, height := 0, 0
+	for _, p := range positions {
+		if p[0]+svgNodeWidth > width {
+			width = p[0] + svgNodeWidth
+		}
+		if p[1]+svgNodeHeight > height {
+			height = p[1] + svgNodeHeight
+		}
+	}
+	width += svgPadding
+	height += svgPadding
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\" font-size=\"12\">\n", width, height, width, height)
+	buf.WriteString("  <defs><marker id=\"arrow\" markerWidth=\"10\" markerHeight=\"10\" refX=\"9\" refY=\"3\" orient=\"auto\"><path d=\"M0,0 L0,6 L9,3 z\" fill=\"#606266\"/></marker></defs>\n")
+
+	for _, rel := range relations {
+		from, ok1 := positions[rel.Tid]
+		to, ok2 := positions[rel.NextTid]
+		if !ok1 || !ok2 {
+			continue
+		}
+		fmt.Fprintf(&buf, "  <line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#606266\" marker-end=\"url(#arrow)\"/>\n",
+			from[0]+svgNodeWidth, from[1]+svgNodeHeight/2, to[0], to[1]+svgNodeHeight/2)
+	}
+
+	for _, task := range tasks {
+		p := positions[task.Tid]
+		fill := "#ffffff"
+		if opts.WithStatus {
+			fill = statusColor(task.Status)
+		}
+		name, duration := nodeLabel(task, opts)
+
+		buf.WriteString("  <g>\n")
+		if opts.WithStatus {
+			fmt.Fprintf(&buf, "    <title>%s</title>\n", domain.StatusText(task.Status))
+		}
+		fmt.Fprintf(&buf, "    <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"6\" fill=\"%s\" stroke=\"#606266\"/>\n",
+			p[0], p[1], svgNodeWidth, svgNodeHeight, fill)
+		if duration == "" {
+			fmt.Fprintf(&buf, "    <text x=\"%d\" y=\"%d\" text-anchor=\"middle\" dominant-baseline=\"middle\">%s</text>\n",
+				p[0]+svgNodeWidth/2, p[1]+svgNodeHeight/2, html.EscapeString(name))
+		} else {
+			fmt.Fprintf(&buf, "    <text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+				p[0]+svgNodeWidth/2, p[1]+svgNodeHeight/2-2, html.EscapeString(name))
+			fmt.Fprintf(&buf, "    <text x=\"%d\" y=\"%d\" text-anchor=\"middle\" font-size=\"10\" fill=\"#303133\">%s</text>\n",
+				p[0]+svgNodeWidth/2, p[1]+svgNodeHeight/2+12, html.EscapeString(duration))
+		}
+		buf.WriteString("  </g>\n")
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// layoutGraph 计算节点左上角坐标
+//
+// 优先使用可视化编辑器保存的坐标；若所有坐标均未设置，则按拓扑层级自动分层布局。
+func layoutGraph(tasks []*domain.Task, relations []*domain.Relation) map[int][2]int {
+	positions := make(map[int][2]int, len(tasks))
+
+	hasPoint := false
+	for _, task := range tasks {
+		if task.PointX != 0 || task.PointY != 0 {
+			hasPoint = true
+			break
+		}
+	}
+
+	if hasPoint {
+		minX, minY := tasks[0].PointX, tasks[0].PointY
+		for _, task := range tasks {
+			if task.PointX < minX {
+				minX = task.PointX
+			}
+			if task.PointY < minY {
+				minY = task.PointY
+			}
+		}
+		for _, task := range tasks {
+			positions[task.Tid] = [2]int{
+				task.PointX - minX + svgPadding,
+				task.PointY - minY + svgPadding,
+			}
+		}
+		return positions
+	}
+
+	for col, stage := range topoStages(tasks, relations) {
+		for row, tid := range stage {
+			positions[tid] = [2]int{
+				svgPadding + col*(svgNodeWidth+svgColGap),
+				svgPadding + row*(svgNodeHeight+svgRowGap),
+			}
+		}
+	}
+	return positions
+}
+
+// topoStages 按拓扑层级对任务分组，环上的节点统一放在最后一层
+func topoStages(tasks []*domain.Task, relations []*domain.Relation) [][]int {
+	inDegree := make(map[int]int, len(tasks))
+	for _, task := range tasks {
+		inDegree[task.Tid] = 0
+	}
+	for _, rel := range relations {
+		if _, ok := inDegree[rel.NextTid]; ok {
+			inDegree[rel.NextTid]++
+		}
+	}
+
+	var stages [][]int
+	for len(inDegree) > 0 {
+		var roots []int
+		for tid, degree := range inDegree {
+			if degree == 0 {
+				roots = append(roots, tid)
+			}
+		}
+
+		if len(roots) == 0 {
+			for tid := range inDegree {
+				roots = append(roots, tid)
+			}
+		}
+		sort.Ints(roots)
+
+		for _, tid := range roots {
+			delete(inDegree, tid)
+		}
+		for _, rel := range relations {
+			for _, tid := range roots {
+				if rel.Tid == tid {
+					if _, ok := inDegree[rel.NextTid]; ok {
+						inDegree[rel.NextTid]--
+					}
+				}
+			}
+		}
+		stages = append(stages, roots)
+	}
+	return stages
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"clock/internal/domain"
+)
+
+// groupTree 任务组层级索引
+type groupTree struct {
+	groups map[int]*domain.TaskGroup
+}
+
+// newGroupTree 创建任务组层级索引
+func newGroupTree(groups []*domain.TaskGroup) *groupTree {
+	tree := &groupTree{groups: make(map[int]*domain.TaskGroup, len(groups))}
+	for _, group := range groups {
+		tree.groups[group.Gid] = group
+	}
+	return tree
+}
+
+// ancestors 返回任务组自身及其所有祖先（由内向外），遇到环时停止
+func (t *groupTree) ancestors(gid int) []int {
+	var chain []int
+	seen := make(map[int]bool)
+	for gid != 0 && !seen[gid] {
+		group, ok := t.groups[gid]
+		if !ok {
+			break
+		}
+		seen[gid] = true
+		chain = append(chain, gid)
+		gid = group.ParentGid
+	}
+	return chain
+}
+
+// contains 判断任务组 gid 是否为 ancestor 或其后代
+func (t *groupTree) contains(ancestor int, gid int) bool {
+	for _, g := range t.ancestors(gid) {
+		if g == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// members 返回任务组（含嵌套子组）内的任务
+func (t *groupTree) members(gid int, tasks []*domain.Task) []*domain.Task {
+	var result []*domain.Task
+	for _, task := range tasks {
+		if task.Gid != 0 && t.contains(gid, task.Gid) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// depthOrder 返回按嵌套深度由深到浅排序的任务组ID
+func (t *groupTree) depthOrder() []int {
+	gids := make([]int, 0, len(t.groups))
+	depth := make(map[int]int, len(t.groups))
+	for gid := range t.groups {
+		gids = append(gids, gid)
+		depth[gid] = len(t.ancestors(gid))
+	}
+	sort.Slice(gids, func(i, j int) bool {
+		if depth[gids[i]] != depth[gids[j]] {
+			return depth[gids[i]] > depth[gids[j]]
+		}
+		return gids[i] < gids[j]
+	})
+	return gids
+}
+
+// expandGroupRelations 将以任务组为端点的关系展开为任务之间的关系
+//
+// 指向任务组的边展开为指向其入口任务（组内无前置任务的任务），
+// 从任务组出发的边展开为从其出口任务（组内无后续任务的任务）出发。
+// 嵌套任务组由深到浅依次计算，空任务组上的边会被忽略。
+func expandGroupRelations(tasks []*domain.Task, groups []*domain.TaskGroup, relations []*domain.Relation) []*domain.Relation {
+	var expanded, pending []*domain.Relation
+	for _, rel := range relations {
+		if rel.Gid == 0 && rel.NextGid == 0 {
+			expanded = append(expanded, rel)
+		} else {
+			pending = append(pending, rel)
+		}
+	}
+	if len(pending) == 0 {
+		return expanded
+	}
+
+	tree := newGroupTree(groups)
+	entries := make(map[int][]int)
+	exits := make(map[int][]int)
+	seen := make(map[string]bool)
+	for _, rel := range expanded {
+		seen[fmt.Sprintf("%d-%d", rel.Tid, rel.NextTid)] = true
+	}
+
+	// expandResolved 展开两端任务组均已计算出入口/出口的关系
+	expandResolved := func() {
+		remain := pending[:0]
+		for _, rel := range pending {
+			sources := []int{rel.Tid}
+			if rel.Gid != 0 {
+				s, ok := exits[rel.Gid]
+				if !ok {
+					remain = append(remain, rel)
+					continue
+				}
+				sources = s
+			}
+
+			targets := []int{rel.NextTid}
+			if rel.NextGid != 0 {
+				t, ok := entries[rel.NextGid]
+				if !ok {
+					remain = append(remain, rel)
+					continue
+				}
+				targets = t
+			}
+
+			for _, tid := range sources {
+				for _, nextTid := range targets {
+					key := fmt.Sprintf("%d-%d", tid, nextTid)
+					if tid == nextTid || seen[key] {
+						continue
+					}
+					seen[key] = true
+					expanded = append(expanded, &domain.Relation{
+						Rid:     rel.Rid,
+						Cid:     rel.Cid,
+						Tid:     tid,
+						NextTid: nextTid,
+					})
+				}
+			}
+		}
+		pending = remain
+	}
+
+	for _, gid := range tree.depthOrder() {
+		expandResolved()
+
+		members := tree.members(gid, tasks)
+		inGroup := make(map[int]bool, len(members))
+		for _, task := range members {
+			inGroup[task.Tid] = true
+		}
+
+		hasPrev := make(map[int]bool)
+		hasNext := make(map[int]bool)
+		for _, rel := range expanded {
+			if inGroup[rel.Tid] && inGroup[rel.NextTid] {
+				hasNext[rel.Tid] = true
+				hasPrev[rel.NextTid] = true
+			}
+		}
+
+		entries[gid] = []int{}
+		exits[gid] = []int{}
+		for _, task := range members {
+			if !hasPrev[task.Tid] {
+				entries[gid] = append(entries[gid], task.Tid)
+			}
+			if !hasNext[task.Tid] {
+				exits[gid] = append(exits[gid], task.Tid)
+			}
+		}
+	}
+	expandResolved()
+
+	// 剩余关系引用了不存在的任务组，忽略
+	return expanded
+}
+
+// collapseGraph 将折叠的任务组合并为单个节点
+func collapseGraph(graph *domain.RelationGraph, groups []*domain.TaskGroup, relations []*domain.Relation, collapsed map[int]bool) {
+	tree := newGroupTree(groups)
+
+	// rep 返回任务组所在的最外层折叠组，未折叠时返回 0
+	rep := func(gid int) int {
+		result := 0
+		for _, g := range tree.ancestors(gid) {
+			if collapsed[g] {
+				result = g
+			}
+		}
+		return result
+	}
+
+	nodes := make([]domain.Node, 0, len(graph.Nodes))
+	groupNodes := make(map[int]*domain.Node)
+	groupMembers := make(map[int][]domain.Node)
+	taskRep := make(map[int]int, len(graph.Nodes))
+	var order []int
+
+	for _, node := range graph.Nodes {
+		g := rep(node.Gid)
+		taskRep[node.ID] = g
+		if g == 0 {
+			nodes = append(nodes, node)
+			continue
+		}
+		if _, ok := groupNodes[g]; !ok {
+			groupNodes[g] = &domain.Node{
+				ID:   g,
+				Kind: domain.NodeKindGroup,
+				Name: tree.groups[g].Name,
+				Gid:  tree.groups[g].ParentGid,
+			}
+			order = append(order, g)
+		}
+		groupMembers[g] = append(groupMembers[g], node)
+	}
+
+	for _, g := range order {
+		node := groupNodes[g]
+		members := groupMembers[g]
+		node.Status = aggregateStatus(members)
+
+		var stats *domain.NodeStats
+		for _, m := range members {
+			node.X += m.X
+			node.Y += m.Y
+			if m.Stats != nil {
+				if stats == nil {
+					stats = &domain.NodeStats{RecentStatuses: []int{}}
+				}
+				stats.AvgDuration += m.Stats.AvgDuration
+				stats.P95Duration += m.Stats.P95Duration
+				stats.Critical = stats.Critical || m.Stats.Critical
+			}
+		}
+		node.X /= len(members)
+		node.Y /= len(members)
+		node.Stats = stats
+
+		nodes = append(nodes, *node)
+	}
+
+	// endpoint 返回关系端点折叠后的 (tid, gid)
+	endpoint := func(tid, gid int) (int, int) {
+		if gid != 0 {
+			if g := rep(gid); g != 0 {
+				return 0, g
+			}
+			return 0, gid
+		}
+		if g := taskRep[tid]; g != 0 {
+			return 0, g
+		}
+		return tid, 0
+	}
+
+	links := make([]domain.Link, 0, len(relations))
+	seen := make(map[[4]int]bool)
+	for _, rel := range relations {
+		tid, gid := endpoint(rel.Tid, rel.Gid)
+		nextTid, nextGid := endpoint(rel.NextTid, rel.NextGid)
+		key := [4]int{tid, gid, nextTid, nextGid}
+		if (tid == nextTid && gid == nextGid) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		links = append(links, domain.Link{
+			ID:      rel.Rid,
+			Cid:     rel.Cid,
+			Tid:     tid,
+			NextTid: nextTid,
+			Gid:     gid,
+			NextGid: nextGid,
+		})
+	}
+
+	graph.Nodes = nodes
+	graph.Links = links
+}
+
+// aggregateStatus 汇总任务组状态：运行中 > 失败 > 已取消 > 等待中 > 成功
+func aggregateStatus(nodes []domain.Node) int {
+	priority := []int{
+		domain.StatusStart,
+		domain.StatusFailure,
+		domain.StatusCancelled,
+		domain.StatusPending,
+	}
+	for _, status := range priority {
+		for _, node := range nodes {
+			if node.Status == status {
+				return status
+			}
+		}
+	}
+	return domain.StatusSuccess
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"clock/internal/domain"
+)
+
+func TestExpandGroupRelations(t *testing.T) {
+	// 任务 1、6 不在任务组中；组 10 包含 2→3；组 20 包含 4、5（无内部关系）；
+	// 组 30 嵌套组 20 并包含任务 7；组 40 为空
+	tasks := []*domain.Task{
+		{Tid: 1}, {Tid: 2, Gid: 10}, {Tid: 3, Gid: 10}, {Tid: 4, Gid: 20}, {Tid: 5, Gid: 20}, {Tid: 6}, {Tid: 7, Gid: 30},
+	}
+	groups := []*domain.TaskGroup{{Gid: 10}, {Gid: 20, ParentGid: 30}, {Gid: 30}, {Gid: 40}}
+	internal := []*domain.Relation{{Tid: 2, NextTid: 3}}
+
+	tests := []struct {
+		name      string
+		relations []*domain.Relation
+		want      []string
+	}{
+		{name: "task edges unchanged", relations: []*domain.Relation{{Tid: 1, NextTid: 6}}, want: []string{"1-6"}},
+		{name: "task to group enters entries", relations: []*domain.Relation{{Tid: 1, NextGid: 10}}, want: []string{"1-2"}},
+		{name: "group to task leaves exits", relations: []*domain.Relation{{Gid: 10, NextTid: 6}}, want: []string{"3-6"}},
+		{name: "group to group", relations: []*domain.Relation{{Gid: 10, NextGid: 20}}, want: []string{"3-4", "3-5"}},
+		{
+			name:      "nested group entries follow inner edges",
+			relations: []*domain.Relation{{Tid: 7, NextGid: 20}, {Tid: 1, NextGid: 30}, {Gid: 30, NextTid: 6}},
+			want:      []string{"1-7", "4-6", "5-6", "7-4", "7-5"},
+		},
+		{name: "empty group ignored", relations: []*domain.Relation{{Tid: 1, NextGid: 40}, {Gid: 40, NextTid: 6}}},
+		{name: "unknown group ignored", relations: []*domain.Relation{{Tid: 1, NextGid: 99}}},
+		{name: "duplicates merged", relations: []*domain.Relation{{Tid: 1, NextTid: 2}, {Tid: 1, NextGid: 10}}, want: []string{"1-2"}},
+		{name: "no self edges", relations: []*domain.Relation{{Tid: 3, NextGid: 10}}, want: []string{"3-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relations := append(slices.Clone(internal), tt.relations...)
+			var got []string
+			for _, rel := range expandGroupRelations(tasks, groups, relations) {
+				if rel.Gid != 0 || rel.NextGid != 0 {
+					t.Errorf("unexpanded relation %+v", rel)
+				}
+				if rel.Tid == 2 && rel.NextTid == 3 {
+					continue
+				}
+				got = append(got, fmt.Sprintf("%d-%d", rel.Tid, rel.NextTid))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expandGroupRelations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupTreeCycle(t *testing.T) {
+	tree := newGroupTree([]*domain.TaskGroup{{Gid: 1, ParentGid: 2}, {Gid: 2, ParentGid: 1}, {Gid: 3, ParentGid: 1}})
+	if got := tree.ancestors(3); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Errorf("ancestors(3) = %v, want [3 1 2]", got)
+	}
+	if !tree.contains(2, 3) || tree.contains(3, 1) {
+		t.Error("contains() does not follow parents")
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"clock/internal/domain"
+)
+
+// 关系图统计默认参数
+const (
+	defaultStatsRuns   = 10
+	defaultStatsWindow = 50
+)
+
+// buildNodeStats 根据执行记录计算节点统计（runs 按结束时间倒序）
+func buildNodeStats(runs []*domain.TaskRun, recent int) *domain.NodeStats {
+	stats := &domain.NodeStats{
+		RecentStatuses: make([]int, 0, recent),
+	}
+
+	var durations []int64
+	for i, run := range runs {
+		if i < recent {
+			stats.RecentStatuses = append(stats.RecentStatuses, run.Status)
+		}
+		if run.Status == domain.StatusSuccess {
+			durations = append(durations, run.Duration)
+		}
+	}
+
+	if len(durations) == 0 {
+		return stats
+	}
+
+	var total int64
+	for _, d := range durations {
+		total += d
+	}
+	stats.AvgDuration = total / int64(len(durations))
+	stats.P95Duration = percentile(durations, 0.95)
+
+	return stats
+}
+
+// percentile 计算分位数（nearest-rank）
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]int64, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// criticalPath 计算以节点耗时为权重的最长路径，存在环时返回 nil
+func criticalPath(tasks []*domain.Task, relations []*domain.Relation, weight map[int]int64) ([]int, int64) {
+	inDegree := make(map[int]int, len(tasks))
+	for _, task := range tasks {
+		inDegree[task.Tid] = 0
+	}
+
+	next := make(map[int][]int)
+	for _, rel := range relations {
+		if _, ok := inDegree[rel.Tid]; !ok {
+			continue
+		}
+		if _, ok := inDegree[rel.NextTid]; !ok {
+			continue
+		}
+		next[rel.Tid] = append(next[rel.Tid], rel.NextTid)
+		inDegree[rel.NextTid]++
+	}
+
+	var queue []int
+	for _, task := range tasks {
+		if inDegree[task.Tid] == 0 {
+			queue = append(queue, task.Tid)
+		}
+	}
+
+	// dist 为到达该节点（含自身）的最长耗时，prev 用于回溯路径
+	dist := make(map[int]int64, len(tasks))
+	prev := make(map[int]int, len(tasks))
+	visited := 0
+
+	for len(queue) > 0 {
+		tid := queue[0]
+		queue = queue[1:]
+		visited++
+
+		dist[tid] += weight[tid]
+		for _, nextTid := range next[tid] {
+			if _, ok := prev[nextTid]; !ok || dist[tid] > dist[nextTid] {
+				dist[nextTid] = dist[tid]
+				prev[nextTid] = tid
+			}
+			inDegree[nextTid]--
+			if inDegree[nextTid] == 0 {
+				queue = append(queue, nextTid)
+			}
+		}
+	}
+
+	// 存在环
+	if visited != len(tasks) || len(tasks) == 0 {
+		return nil, 0
+	}
+
+	end := tasks[0].Tid
+	for _, task := range tasks {
+		if dist[task.Tid] > dist[end] {
+			end = task.Tid
+		}
+	}
+
+	path := []int{end}
+	for {
+		p, ok := prev[path[0]]
+		if !ok {
+			break
+		}
+		path = append([]int{p}, path...)
+	}
+
+	return path, dist[end]
+}
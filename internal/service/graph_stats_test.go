@@ -0,0 +1,139 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"clock/internal/domain"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int64
+		p      float64
+		want   int64
+	}{
+		{name: "empty", p: 0.95},
+		{name: "single", values: []int64{7}, p: 0.95, want: 7},
+		{name: "unsorted median", values: []int64{5, 1, 4, 2, 3}, p: 0.5, want: 3},
+		{name: "p95 of ten", values: []int64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, p: 0.95, want: 10},
+		{name: "p90 of ten", values: []int64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, p: 0.9, want: 9},
+		{name: "p0", values: []int64{3, 1, 2}, p: 0, want: 1},
+		{name: "p100", values: []int64{3, 1, 2}, p: 1, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := slices.Clone(tt.values)
+			if got := percentile(tt.values, tt.p); got != tt.want {
+				t.Errorf("percentile(%v, %v) = %d, want %d", tt.values, tt.p, got, tt.want)
+			}
+			if !slices.Equal(values, tt.values) {
+				t.Errorf("percentile modified input to %v", tt.values)
+			}
+		})
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	tasks := func(tids ...int) []*domain.Task {
+		out := make([]*domain.Task, len(tids))
+		for i, tid := range tids {
+			out[i] = &domain.Task{Tid: tid}
+		}
+		return out
+	}
+	rels := func(pairs ...[2]int) []*domain.Relation {
+		out := make([]*domain.Relation, len(pairs))
+		for i, p := range pairs {
+			out[i] = &domain.Relation{Tid: p[0], NextTid: p[1]}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		tasks     []*domain.Task
+		relations []*domain.Relation
+		weight    map[int]int64
+		wantPath  []int
+		wantTotal int64
+	}{
+		{name: "empty"},
+		{name: "single", tasks: tasks(1), weight: map[int]int64{1: 5}, wantPath: []int{1}, wantTotal: 5},
+		{
+			name:      "chain",
+			tasks:     tasks(1, 2, 3),
+			relations: rels([2]int{1, 2}, [2]int{2, 3}),
+			weight:    map[int]int64{1: 1, 2: 2, 3: 3},
+			wantPath:  []int{1, 2, 3},
+			wantTotal: 6,
+		},
+		{
+			name:      "diamond takes heavier branch",
+			tasks:     tasks(1, 2, 3, 4),
+			relations: rels([2]int{1, 2}, [2]int{1, 3}, [2]int{2, 4}, [2]int{3, 4}),
+			weight:    map[int]int64{1: 1, 2: 10, 3: 2, 4: 1},
+			wantPath:  []int{1, 2, 4},
+			wantTotal: 12,
+		},
+		{
+			name:      "disconnected heavier node",
+			tasks:     tasks(1, 2, 3),
+			relations: rels([2]int{1, 2}),
+			weight:    map[int]int64{1: 1, 2: 1, 3: 5},
+			wantPath:  []int{3},
+			wantTotal: 5,
+		},
+		{
+			name:      "missing weights count as zero",
+			tasks:     tasks(1, 2),
+			relations: rels([2]int{1, 2}),
+			weight:    map[int]int64{2: 4},
+			wantPath:  []int{1, 2},
+			wantTotal: 4,
+		},
+		{
+			name:      "relations to unknown tasks ignored",
+			tasks:     tasks(1, 2),
+			relations: rels([2]int{1, 2}, [2]int{2, 9}, [2]int{9, 1}),
+			weight:    map[int]int64{1: 1, 2: 1, 9: 100},
+			wantPath:  []int{1, 2},
+			wantTotal: 2,
+		},
+		{
+			name:      "cycle",
+			tasks:     tasks(1, 2, 3),
+			relations: rels([2]int{1, 2}, [2]int{2, 3}, [2]int{3, 2}),
+			weight:    map[int]int64{1: 1, 2: 1, 3: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, total := criticalPath(tt.tasks, tt.relations, tt.weight)
+			if !slices.Equal(path, tt.wantPath) || total != tt.wantTotal {
+				t.Errorf("criticalPath() = %v, %d, want %v, %d", path, total, tt.wantPath, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestBuildNodeStats(t *testing.T) {
+	runs := []*domain.TaskRun{
+		{Status: domain.StatusSuccess, Duration: 100},
+		{Status: domain.StatusFailure, Duration: 5000},
+		{Status: domain.StatusSuccess, Duration: 300},
+		{Status: domain.StatusSuccess, Duration: 200},
+	}
+	stats := buildNodeStats(runs, 2)
+	if !slices.Equal(stats.RecentStatuses, []int{domain.StatusSuccess, domain.StatusFailure}) {
+		t.Errorf("RecentStatuses = %v", stats.RecentStatuses)
+	}
+	if stats.AvgDuration != 200 || stats.P95Duration != 300 {
+		t.Errorf("avg=%d p95=%d, want 200 and 300 (failures excluded)", stats.AvgDuration, stats.P95Duration)
+	}
+
+	if stats := buildNodeStats([]*domain.TaskRun{{Status: domain.StatusFailure, Duration: 9}}, 5); stats.AvgDuration != 0 || stats.P95Duration != 0 {
+		t.Errorf("failures only: avg=%d p95=%d, want 0", stats.AvgDuration, stats.P95Duration)
+	}
+}
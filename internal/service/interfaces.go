@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"clock/internal/domain"
+	"clock/internal/notify"
 	"clock/internal/repository"
 )
 
@@ -18,7 +21,8 @@ type TaskService interface {
 	Get(tid int) (*domain.Task, error)
 	List(query *repository.TaskQuery) (*ListResult[*domain.Task], error)
 	Save(task *domain.Task) error
-	Delete(tid int) error
+	Delete(tid int, opts *DeleteOptions) error
+	Restore(tid int) error
 	Run(tid int) error
 	UpdateNodes(nodes []domain.Node) error
 	CancelTask(tid int) error
@@ -31,24 +35,219 @@ type ContainerService interface {
 	Get(cid int) (*domain.Container, error)
 	List(query *repository.ContainerQuery) (*ListResult[*domain.Container], error)
 	Save(container *domain.Container) error
-	Delete(cid int) error
+	Delete(cid int, opts *DeleteOptions) error
+	Restore(cid int) error
 	Run(cid int) error
+	Clone(cid int, opts *CloneOptions) (*domain.Container, error)
+}
+
+// DeleteOptions 删除参数
+type DeleteOptions struct {
+	Purge       bool // 彻底删除（不进入回收站）
+	CascadeLogs bool // 彻底删除时同时删除执行日志（与配置项取或）
+}
+
+// Replacement 查找替换规则
+type Replacement struct {
+	Find    string `json:"find"`
+	Replace string `json:"replace"`
+}
+
+// CloneOptions 容器克隆参数
+type CloneOptions struct {
+	Name      string        `json:"name"`      // 新容器名称，为空时使用 "<原名称> (copy)"
+	Command   []Replacement `json:"command"`   // 任务命令替换规则
+	Directory []Replacement `json:"directory"` // 任务工作目录替换规则
 }
 
 // RelationService 关系服务接口
 type RelationService interface {
-	GetGraph(cid int) (*domain.RelationGraph, error)
+	GetGraph(cid int, opts *GraphOptions) (*domain.RelationGraph, error)
+	ExportGraph(cid int, opts *GraphExportOptions) ([]byte, error)
 	Add(relation *domain.Relation) error
 	Delete(rid int) error
 	CheckCircle(tasks []*domain.Task, relations []*domain.Relation) bool
 }
 
+// TaskGroupService 任务组服务接口
+type TaskGroupService interface {
+	List(cid int) ([]*domain.TaskGroup, error)
+	Save(group *domain.TaskGroup) error
+	Delete(gid int) error
+	Cancel(gid int) error
+	Retry(gid int) error
+	Clear(gid int) error
+}
+
+// GraphOptions 关系图查询参数
+type GraphOptions struct {
+	Stats  bool // 附带历史执行统计、关键路径和预计耗时
+	Runs   int  // 返回最近执行状态的数量，默认 10
+	Window int  // 计算耗时统计使用的历史记录数，默认 50
+
+	Collapse []int // 额外折叠的任务组ID
+	Expand   []int // 额外展开的任务组ID
+}
+
 // TaskLogService 任务日志服务接口
 type TaskLogService interface {
 	List(query *repository.LogQuery) (*ListResult[*domain.TaskLog], error)
 	Delete(query *repository.LogQuery) error
 	DeleteByID(lid string) error
 	DeleteAll() error
+	OpenOutput(lid string, stream string) (io.ReadCloser, error)
+	Download(opts *LogDownloadOptions) (*LogDownload, error)
+	Lines(lid string, query *repository.LogLineQuery) (*LogLinesResult, error)
+	Migrate() (int, error)
+	Reindex() (int, error)
+	// Recover 结束服务异常退出时仍在运行中的日志，启动时在执行器和日志清理启动前调用
+	Recover() (int, error)
+}
+
+// LogLinesResult 日志行查询结果
+type LogLinesResult struct {
+	Items   []*domain.LogLine `json:"items"`
+	HasMore bool              `json:"has_more"` // 范围内是否还有更多行
+	Next    int64             `json:"next"`     // 下一页的起始行号（HasMore 为 true 时有效）
+}
+
+// EventJournal 批次事件日志服务接口，持久化实时事件并回放历史批次
+type EventJournal interface {
+	Start()
+	Stop()
+	Events(runID string) ([]*domain.RunEvent, error)
+	Replay(ctx context.Context, runID string, opts *ReplayOptions) (<-chan StreamEvent, error)
+}
+
+// LogJanitor 日志清理服务接口，按保留策略定期清理日志
+type LogJanitor interface {
+	Start()
+	Stop()
+	Run() (*RetentionReport, error)
+}
+
+// RetentionReport 一次清理的结果
+type RetentionReport struct {
+	ByAge      int   `json:"by_age"`      // 超过保留天数删除的条数
+	ByCount    int   `json:"by_count"`    // 超过保留条数删除的条数
+	BySize     int   `json:"by_size"`     // 超过总大小删除的条数
+	FreedBytes int64 `json:"freed_bytes"` // 释放的输出字节数
+}
+
+// Total 返回删除的日志总数
+func (r *RetentionReport) Total() int {
+	return r.ByAge + r.ByCount + r.BySize
+}
+
+// NotifyService 通知服务接口，任务和批次结束时按规则发送通知
+type NotifyService interface {
+	Start()
+	Stop()
+	Channels() []NotifyChannelInfo
+	ListRules(cid int, tid int) ([]*domain.NotifyRule, error)
+	SaveRule(rule *domain.NotifyRule) error
+	DeleteRule(id int) error
+	Test(ctx context.Context, channel string) error
+	Send(ctx context.Context, channel string, msg *notify.Message) error
+	Preview(req *NotifyPreviewRequest) (*NotifyPreview, error)
+}
+
+// NotifyPreviewRequest 模板预览参数
+type NotifyPreviewRequest struct {
+	Template string `json:"template"` // 待预览的模板，为空时使用规则或通道的模板
+	Channel  string `json:"channel"`  // 通道名称，用于选择通道模板
+	RuleID   int    `json:"rule_id"`  // 规则ID，使用规则的通道和模板
+	RunID    string `json:"run_id"`   // 历史批次ID
+	Tid      int    `json:"tid"`      // 任务ID，指定时预览任务结束消息
+}
+
+// NotifyPreview 模板预览结果
+type NotifyPreview struct {
+	Title   string          `json:"title"`
+	Text    string          `json:"text"`
+	Message *notify.Message `json:"message"` // 模板上下文
+}
+
+// WebhookService webhook 订阅服务接口，将事件签名后投递到外部系统
+type WebhookService interface {
+	Start()
+	Stop()
+	List() ([]*domain.Webhook, error)
+	Save(hook *domain.Webhook) error
+	Delete(id int) error
+	Deliveries(query *repository.DeliveryQuery) (*ListResult[*domain.WebhookDelivery], error)
+	GetDelivery(id string) (*domain.WebhookDelivery, error)
+	Redeliver(id string) (*domain.WebhookDelivery, error)
+	Ping(id int) (*domain.WebhookDelivery, error)
+}
+
+// SlaMonitor SLA 监控服务接口，检查截止时间和预期运行时间
+type SlaMonitor interface {
+	Start()
+	Stop()
+	Misses(query *repository.SlaMissQuery) (*ListResult[*domain.SlaMiss], error)
+}
+
+// AnomalyDetector 耗时异常检测服务接口，根据历史执行耗时标记异常执行
+type AnomalyDetector interface {
+	Start()
+	Stop()
+	Stats(tid int) (*domain.DurationStats, error)
+}
+
+// ReportService 运行汇总报表服务接口，定期生成日报和周报并通过通知通道发送
+type ReportService interface {
+	Start()
+	Stop()
+	Generate(from time.Time, to time.Time) (*Report, error)
+	Render(report *Report, format string) (string, error)
+	Send(ctx context.Context, report *Report, channels []string) error
+}
+
+// UserService 用户服务接口
+type UserService interface {
+	Bootstrap() error
+	Authenticate(name string, password string) (*domain.User, error)
+	Get(id int) (*domain.User, error)
+	GetByName(name string) (*domain.User, error)
+	List() ([]*domain.User, error)
+	Create(req *UserRequest) (*domain.User, error)
+	SetDisable(id int, disable bool) error
+	ResetPassword(id int, password string) error
+	ChangePassword(id int, req *PasswordRequest) error
+}
+
+// APITokenService API 令牌服务接口
+type APITokenService interface {
+	List(userID int) ([]*domain.APIToken, error)
+	Get(id int) (*domain.APIToken, error)
+	Create(userID int, req *APITokenRequest) (*APITokenCreated, error)
+	Revoke(id int) error
+	Authenticate(raw string) (*domain.APIToken, *domain.User, error)
+}
+
+// AuditService 审计服务接口
+type AuditService interface {
+	Start()
+	Stop()
+	List(query *repository.AuditQuery) (*ListResult[*domain.AuditLog], error)
+	Snapshot(targetType string, id string) (interface{}, error)
+	Record(entry *domain.AuditLog, before interface{}, after interface{}) error
+}
+
+// AccessService 权限服务接口，管理角色授权并解析资源所属的容器
+type AccessService interface {
+	Permissions(userID int) (*domain.Permissions, error)
+	ListBindings(query *repository.RoleBindingQuery) ([]*domain.RoleBinding, error)
+	GetBinding(id int) (*domain.RoleBinding, error)
+	Grant(binding *domain.RoleBinding) (*domain.RoleBinding, error)
+	Revoke(id int) error
+	TaskCid(tid int) (int, error)
+	GroupCid(gid int) (int, error)
+	RelationCid(rid int) (int, error)
+	LogCid(lid string) (int, error)
+	RunCid(runID string) (int, error)
+	NotifyRuleCid(id int) (int, error)
 }
 
 // SystemService 系统监控服务接口
@@ -72,6 +271,7 @@ type SchedulerService interface {
 // All subscribers should receive the same stream.
 type MessageService interface {
 	Publish(event StreamEvent)
-	Subscribe(ctx context.Context) <-chan StreamEvent
-	GetCounters() []domain.TaskCounter
+	Subscribe(ctx context.Context, filter *StreamFilter, lastID int64) <-chan StreamEvent
+	Follow(ctx context.Context, filter *StreamFilter, lastID int64) (*FollowStream, error)
+	GetCounters(cids []int) []domain.TaskCounter
 }
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+)
+
+// LogDownloadOptions 日志下载参数（Lid 和 RunID 二选一）
+type LogDownloadOptions struct {
+	Lid    string // 日志ID
+	RunID  string // 批次ID，下载批次内所有任务的日志
+	Stream string // stdout, stderr, all（默认 all）
+	Gzip   bool   // 以 gzip 压缩文件下载
+}
+
+// LogDownload 日志下载内容
+//
+// 单条日志的未压缩下载直接从存储读取，Range 请求时重新打开输出并跳过前面的内容；
+// 批次下载和 gzip 下载先写入临时文件，以便按 Range 请求读取任意区间，Close 时删除临时文件。
+type LogDownload struct {
+	Name        string    // 下载文件名
+	ContentType string    // 内容类型
+	ModTime     time.Time // 最后修改时间（运行中的日志为当前时间）
+	Content     io.ReadSeekCloser
+}
+
+// tempContent 读取结束后自动删除的临时文件
+type tempContent struct {
+	*os.File
+}
+
+// Close 关闭并删除临时文件
+func (t *tempContent) Close() error {
+	err := t.File.Close()
+	_ = os.Remove(t.File.Name())
+	return err
+}
+
+// Download 导出日志的纯文本输出
+func (s *taskLogService) Download(opts *LogDownloadOptions) (*LogDownload, error) {
+	if opts.Stream == "" {
+		opts.Stream = domain.LogStreamAll
+	}
+	switch opts.Stream {
+	case domain.LogStreamStdout, domain.LogStreamStderr, domain.LogStreamAll:
+	default:
+		return nil, apperrors.InvalidParam("invalid stream: " + opts.Stream)
+	}
+
+	var logs []*domain.TaskLog
+	var name string
+	switch {
+	case opts.Lid != "":
+		log, err := s.taskLogRepo.GetByID(opts.Lid)
+		if err != nil {
+			return nil, err
+		}
+		logs = []*domain.TaskLog{log}
+		name = opts.Lid
+	case opts.RunID != "":
+		var err error
+		if logs, err = s.taskLogRepo.FindByRunID(opts.RunID); err != nil {
+			return nil, err
+		}
+		if len(logs) == 0 {
+			return nil, apperrors.NotFound("log")
+		}
+		name = "run-" + opts.RunID
+	default:
+		return nil, apperrors.InvalidParam("lid or run_id is required")
+	}
+
+	var content io.ReadSeekCloser
+	var err error
+	if opts.Lid != "" && !opts.Gzip {
+		content, err = s.openOutputContent(opts.Lid, opts.Stream)
+	} else {
+		content, err = s.writeTempContent(logs, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	download := &LogDownload{
+		Name:        fmt.Sprintf("%s.%s.log", name, opts.Stream),
+		ContentType: "text/plain; charset=utf-8",
+		ModTime:     downloadModTime(logs),
+		Content:     content,
+	}
+	if opts.Gzip {
+		download.Name += ".gz"
+		download.ContentType = "application/gzip"
+	}
+	return download, nil
+}
+
+// openOutputContent 返回直接从存储读取单条日志输出的内容，先完整读取一遍以确定大小
+func (s *taskLogService) openOutputContent(lid string, stream string) (io.ReadSeekCloser, error) {
+	content := &outputContent{
+		open: func() (io.ReadCloser, error) {
+			return s.taskLogRepo.OpenOutput(lid, stream)
+		},
+	}
+	size, err := content.skip(-1)
+	_ = content.Close()
+	if err != nil {
+		return nil, err
+	}
+	content.size = size
+	return content, nil
+}
+
+// writeTempContent 将日志输出写入临时文件
+func (s *taskLogService) writeTempContent(logs []*domain.TaskLog, opts *LogDownloadOptions) (io.ReadSeekCloser, error) {
+	f, err := os.CreateTemp("", "clock-log-*")
+	if err != nil {
+		return nil, apperrors.Storage(err)
+	}
+	content := &tempContent{File: f}
+
+	if err := s.writeDownload(f, logs, opts); err != nil {
+		_ = content.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = content.Close()
+		return nil, apperrors.Storage(err)
+	}
+	return content, nil
+}
+
+// outputContent 按需打开日志输出的可定位内容
+//
+// 输出是流式解压的，无法直接定位：向后定位时跳过中间的内容，向前定位时关闭后重新打开。
+type outputContent struct {
+	open   func() (io.ReadCloser, error)
+	rc     io.ReadCloser
+	pos    int64 // rc 已读取到的位置
+	offset int64 // 下次读取的位置
+	size   int64 // 内容大小（创建时确定，运行中的日志之后追加的内容不在下载范围内）
+}
+
+// Read 从当前位置读取
+func (c *outputContent) Read(p []byte) (int, error) {
+	if c.offset >= c.size {
+		return 0, io.EOF
+	}
+	if _, err := c.skip(c.offset); err != nil {
+		return 0, err
+	}
+	if remain := c.size - c.offset; int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err := c.rc.Read(p)
+	c.pos += int64(n)
+	c.offset = c.pos
+	return n, wrapStorageErr(err)
+}
+
+// Seek 设置下次读取的位置，实际定位推迟到读取时
+func (c *outputContent) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += c.offset
+	case io.SeekEnd:
+		offset += c.size
+	default:
+		return 0, apperrors.InvalidParam("invalid whence")
+	}
+	if offset < 0 {
+		return 0, apperrors.InvalidParam("negative position")
+	}
+	c.offset = offset
+	return offset, nil
+}
+
+// skip 将 rc 定位到 to（to < 0 时读到末尾），返回定位后的位置
+func (c *outputContent) skip(to int64) (int64, error) {
+	// 目标位置在已读取的位置之前时重新打开
+	if c.rc == nil || (to >= 0 && to < c.pos) {
+		if err := c.Close(); err != nil {
+			return 0, wrapStorageErr(err)
+		}
+		rc, err := c.open()
+		if err != nil {
+			return 0, err
+		}
+		c.rc, c.pos = rc, 0
+	}
+
+	var n int64
+	var err error
+	if to < 0 {
+		n, err = io.Copy(io.Discard, c.rc)
+	} else {
+		n, err = io.CopyN(io.Discard, c.rc, to-c.pos)
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	c.pos += n
+	return c.pos, wrapStorageErr(err)
+}
+
+// Close 关闭当前打开的输出
+func (c *outputContent) Close() error {
+	if c.rc == nil {
+		return nil
+	}
+	err := c.rc.Close()
+	c.rc = nil
+	return err
+}
+
+// wrapStorageErr 将读取输出的错误转换为存储错误，业务错误和 io.EOF 原样返回
+func wrapStorageErr(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	if _, ok := err.(*apperrors.AppError); ok {
+		return err
+	}
+	return apperrors.Storage(err)
+}
+
+// writeDownload 依次写入日志输出，批次下载时每条日志前附加标题行
+func (s *taskLogService) writeDownload(w io.Writer, logs []*domain.TaskLog, opts *LogDownloadOptions) error {
+	var zw *gzip.Writer
+	if opts.Gzip {
+		zw = gzip.NewWriter(w)
+		w = zw
+	}
+
+	for i, log := range logs {
+		if opts.RunID != "" {
+			if i > 0 {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return apperrors.Storage(err)
+				}
+			}
+			header := fmt.Sprintf("==> tid=%d lid=%s status=%s <==\n", log.Tid, log.Lid, domain.StatusText(log.Status))
+			if _, err := io.WriteString(w, header); err != nil {
+				return apperrors.Storage(err)
+			}
+		}
+
+		rc, err := s.taskLogRepo.OpenOutput(log.Lid, opts.Stream)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, rc)
+		_ = rc.Close()
+		if err != nil {
+			if _, ok := err.(*apperrors.AppError); ok {
+				return err
+			}
+			return apperrors.Storage(err)
+		}
+	}
+
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return apperrors.Storage(err)
+		}
+	}
+	return nil
+}
+
+// downloadModTime 返回下载内容的修改时间，运行中的日志内容仍在变化，使用当前时间
+func downloadModTime(logs []*domain.TaskLog) time.Time {
+	var latest int64
+	for _, log := range logs {
+		if log.Running {
+			return time.Now()
+		}
+		if log.UpdateAt > latest {
+			latest = log.UpdateAt
+		}
+	}
+	return time.Unix(latest, 0)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"clock/internal/domain"
+	"clock/internal/repository"
+)
+
+func TestLogDownloadRange(t *testing.T) {
+	dir := t.TempDir()
+	db := newTestDB(t)
+	store, err := repository.NewFileLogStore(filepath.Join(dir, "logs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := strings.Repeat("0123456789", 10000)
+	if err := repository.NewTaskLogRepository(db, store).Save(&domain.TaskLog{Lid: "lid", RunID: "r1", Tid: 1, Cid: 1, StdOut: output}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewTaskLogService(repository.NewTaskLogRepository(db, store))
+
+	tests := []struct {
+		name     string
+		opts     *LogDownloadOptions
+		rng      string
+		want     string
+		wantTemp bool
+	}{
+		{name: "whole", opts: &LogDownloadOptions{Lid: "lid", Stream: "stdout"}, want: output},
+		{name: "prefix", opts: &LogDownloadOptions{Lid: "lid", Stream: "stdout"}, rng: "bytes=0-4", want: "01234"},
+		{name: "middle", opts: &LogDownloadOptions{Lid: "lid", Stream: "stdout"}, rng: "bytes=50003-50007", want: "34567"},
+		{name: "suffix", opts: &LogDownloadOptions{Lid: "lid", Stream: "stdout"}, rng: "bytes=-3", want: "789"},
+		{name: "open ended", opts: &LogDownloadOptions{Lid: "lid", Stream: "stdout"}, rng: "bytes=99995-", want: "56789"},
+		{name: "run", opts: &LogDownloadOptions{RunID: "r1", Stream: "stdout"}, rng: "bytes=0-4", want: "==> t", wantTemp: true},
+		{name: "gzip", opts: &LogDownloadOptions{Lid: "lid", Stream: "stdout", Gzip: true}, rng: "bytes=0-1", want: "\x1f\x8b", wantTemp: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			download, err := svc.Download(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer download.Content.Close()
+			if _, temp := download.Content.(*tempContent); temp != tt.wantTemp {
+				t.Errorf("temp file = %v, want %v", temp, tt.wantTemp)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.rng != "" {
+				req.Header.Set("Range", tt.rng)
+			}
+			rec := httptest.NewRecorder()
+			http.ServeContent(rec, req, download.Name, download.ModTime, download.Content)
+
+			body, _ := io.ReadAll(rec.Body)
+			if string(body) != tt.want {
+				t.Errorf("status %d body %.20q (%d bytes), want %.20q", rec.Code, body, len(body), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// retentionBatchSize 按总大小清理时每批查询的日志条数
+const retentionBatchSize = 100
+
+// retentionPolicy 单个任务生效的保留策略，0 表示不限
+type retentionPolicy struct {
+	maxAge        int   // 保留天数
+	maxCount      int   // 保留条数
+	maxSize       int64 // 该任务日志的输出总大小上限（字节）
+	failureMaxAge int   // 失败日志保留天数（keepFailures 为 true 时有效）
+	keepFailures  bool  // 失败日志单独保留，不计入 maxCount 和 maxAge
+}
+
+// logJanitor 日志清理服务实现
+type logJanitor struct {
+	cfg           *config.RetentionConfig
+	taskLogRepo   repository.TaskLogRepository
+	taskRepo      repository.TaskRepository
+	containerRepo repository.ContainerRepository
+	hub           *StreamHub
+
+	mu   sync.Mutex // 保证同一时间只有一次清理
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewLogJanitor 创建日志清理服务
+func NewLogJanitor(
+	cfg *config.RetentionConfig,
+	taskLogRepo repository.TaskLogRepository,
+	taskRepo repository.TaskRepository,
+	containerRepo repository.ContainerRepository,
+	hub *StreamHub,
+) LogJanitor {
+	return &logJanitor{
+		cfg:           cfg,
+		taskLogRepo:   taskLogRepo,
+		taskRepo:      taskRepo,
+		containerRepo: containerRepo,
+		hub:           hub,
+	}
+}
+
+// Start 启动后台清理，interval 为 0 时不启动
+func (j *logJanitor) Start() {
+	if j.cfg.Interval <= 0 || j.stop != nil {
+		return
+	}
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+
+	logger.Infof("[retention] log janitor started, interval %d minutes", j.cfg.Interval)
+	go j.loop(time.Duration(j.cfg.Interval) * time.Minute)
+}
+
+// Stop 停止后台清理
+func (j *logJanitor) Stop() {
+	if j.stop == nil {
+		return
+	}
+	close(j.stop)
+	<-j.done
+	j.stop = nil
+}
+
+// loop 定期执行清理
+func (j *logJanitor) loop(interval time.Duration) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			if _, err := j.Run(); err != nil {
+				logger.Errorf("[retention] log cleanup failed: %v", err)
+			}
+		}
+	}
+}
+
+// Run 按保留策略执行一次清理
+func (j *logJanitor) Run() (*RetentionReport, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	report := &RetentionReport{}
+	now := time.Now()
+
+	tids, err := j.taskLogRepo.Tids()
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make(map[int]*domain.Container)
+	for _, tid := range tids {
+		policy := j.resolve(tid, containers)
+		if err := j.cleanTask(tid, policy, now, report); err != nil {
+			return report, err
+		}
+	}
+
+	// 依次按容器和全局的大小上限清理
+	for cid, container := range containers {
+		if container == nil || container.LogMaxSize <= 0 {
+			continue
+		}
+		if err := j.cleanSize(&repository.LogCleanupQuery{Cid: cid}, megabytes(container.LogMaxSize), report); err != nil {
+			return report, err
+		}
+	}
+	if err := j.cleanSize(&repository.LogCleanupQuery{}, megabytes(j.cfg.MaxSize), report); err != nil {
+		return report, err
+	}
+
+	if report.Total() > 0 {
+		msg := fmt.Sprintf("log retention purged %d logs (age %d, count %d, size %d), freed %d bytes",
+			report.Total(), report.ByAge, report.ByCount, report.BySize, report.FreedBytes)
+		logger.Infof("[retention] %s", msg)
+		if j.hub != nil {
+			j.hub.Publish(StreamEvent{Kind: "meta", Msg: msg})
+		}
+	}
+	return report, nil
+}
+
+// resolve 计算任务生效的保留策略：任务 > 容器 > 全局配置
+//
+// 大小上限不继承，任务的上限只约束该任务的日志，容器的上限在 Run 中按容器清理。
+func (j *logJanitor) resolve(tid int, containers map[int]*domain.Container) *retentionPolicy {
+	maxAge, maxCount, failureMaxAge := j.cfg.MaxAge, j.cfg.MaxCount, j.cfg.FailureMaxAge
+	maxSize := 0
+
+	// 任务已被彻底删除时使用全局配置
+	task, err := j.taskRepo.GetByIDUnscoped(tid)
+	if err == nil {
+		container, ok := containers[task.Cid]
+		if !ok {
+			container, _ = j.containerRepo.GetByIDUnscoped(task.Cid)
+			containers[task.Cid] = container
+		}
+		if container != nil {
+			maxAge = inherit(container.LogMaxAge, maxAge)
+			maxCount = inherit(container.LogMaxCount, maxCount)
+			failureMaxAge = inherit(container.LogFailureMaxAge, failureMaxAge)
+		}
+		maxAge = inherit(task.LogMaxAge, maxAge)
+		maxCount = inherit(task.LogMaxCount, maxCount)
+		failureMaxAge = inherit(task.LogFailureMaxAge, failureMaxAge)
+		maxSize = task.LogMaxSize
+	}
+
+	policy := &retentionPolicy{
+		maxAge:       unlimited(maxAge),
+		maxCount:     unlimited(maxCount),
+		maxSize:      megabytes(maxSize),
+		keepFailures: failureMaxAge != 0,
+	}
+	if policy.keepFailures {
+		policy.failureMaxAge = unlimited(failureMaxAge)
+	}
+	return policy
+}
+
+// inherit 覆盖值为 0 时继承上级配置
+func inherit(value, parent int) int {
+	if value != 0 {
+		return value
+	}
+	return parent
+}
+
+// unlimited 将 -1（不限）统一转换为 0
+func unlimited(value int) int {
+	if value < 0 {
+		return 0
+	}
+	return value
+}
+
+// megabytes 将 MB 转换为字节，不大于 0 时返回 0（不限）
+func megabytes(value int) int64 {
+	if value <= 0 {
+		return 0
+	}
+	return int64(value) * 1024 * 1024
+}
+
+// cleanTask 按保留天数、保留条数和大小上限清理单个任务的日志
+func (j *logJanitor) cleanTask(tid int, policy *retentionPolicy, now time.Time, report *RetentionReport) error {
+	// 失败日志单独保留时，普通规则只作用于非失败日志
+	var failed *bool
+	if policy.keepFailures {
+		failed = new(bool)
+	}
+
+	if policy.maxCount > 0 {
+		n, err := j.purge(&repository.LogCleanupQuery{Tid: tid, Failed: failed, Offset: policy.maxCount}, report)
+		if err != nil {
+			return err
+		}
+		report.ByCount += n
+	}
+
+	if policy.maxAge > 0 {
+		before := now.AddDate(0, 0, -policy.maxAge).Unix()
+		n, err := j.purge(&repository.LogCleanupQuery{Tid: tid, Before: before, Failed: failed}, report)
+		if err != nil {
+			return err
+		}
+		report.ByAge += n
+	}
+
+	if policy.keepFailures && policy.failureMaxAge > 0 {
+		isFailed := true
+		before := now.AddDate(0, 0, -policy.failureMaxAge).Unix()
+		n, err := j.purge(&repository.LogCleanupQuery{Tid: tid, Before: before, Failed: &isFailed}, report)
+		if err != nil {
+			return err
+		}
+		report.ByAge += n
+	}
+
+	return j.cleanSize(&repository.LogCleanupQuery{Tid: tid}, policy.maxSize, report)
+}
+
+// cleanSize 范围内（scope 的任务或容器）日志总大小超过 limit 字节时从最旧的日志开始清理，失败日志最后清理；
+// limit 为 0 时不限
+func (j *logJanitor) cleanSize(scope *repository.LogCleanupQuery, limit int64, report *RetentionReport) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	total, err := j.taskLogRepo.TotalSize(scope)
+	if err != nil {
+		return err
+	}
+
+	for _, isFailed := range []bool{false, true} {
+		for total > limit {
+			logs, err := j.taskLogRepo.FindForCleanup(&repository.LogCleanupQuery{
+				Tid:    scope.Tid,
+				Cid:    scope.Cid,
+				Failed: &isFailed,
+				Oldest: true,
+				Limit:  retentionBatchSize,
+			})
+			if err != nil {
+				return err
+			}
+			if len(logs) == 0 {
+				break
+			}
+
+			// 只删除降到上限以内所需的日志
+			var batch []*domain.TaskLog
+			for _, log := range logs {
+				if total <= limit {
+					break
+				}
+				batch = append(batch, log)
+				total -= log.OutSize + log.ErrSize
+			}
+			if err := j.delete(batch, report); err != nil {
+				return err
+			}
+			report.BySize += len(batch)
+		}
+	}
+	return nil
+}
+
+// purge 删除匹配查询的日志，返回删除条数
+func (j *logJanitor) purge(query *repository.LogCleanupQuery, report *RetentionReport) (int, error) {
+	logs, err := j.taskLogRepo.FindForCleanup(query)
+	if err != nil {
+		return 0, err
+	}
+	if err := j.delete(logs, report); err != nil {
+		return 0, err
+	}
+	return len(logs), nil
+}
+
+// delete 删除日志并累计释放的字节数
+func (j *logJanitor) delete(logs []*domain.TaskLog, report *RetentionReport) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	lids := make([]string, len(logs))
+	for i, log := range logs {
+		lids[i] = log.Lid
+	}
+	if err := j.taskLogRepo.DeleteByIDs(lids); err != nil {
+		return err
+	}
+	for _, log := range logs {
+		report.FreedBytes += log.OutSize + log.ErrSize
+	}
+	return nil
+}
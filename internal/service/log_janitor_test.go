@@ -0,0 +1,239 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	"clock/internal/repository"
+)
+
+// janitorFixture 测试用的日志清理服务及仓储
+type janitorFixture struct {
+	janitor    *logJanitor
+	logs       repository.TaskLogRepository
+	tasks      repository.TaskRepository
+	containers repository.ContainerRepository
+}
+
+func newJanitorFixture(t *testing.T, cfg *config.RetentionConfig) *janitorFixture {
+	t.Helper()
+	db := newTestDB(t)
+	f := &janitorFixture{
+		logs:       repository.NewTaskLogRepository(db, nil),
+		tasks:      repository.NewTaskRepository(db),
+		containers: repository.NewContainerRepository(db),
+	}
+	f.janitor = NewLogJanitor(cfg, f.logs, f.tasks, f.containers, nil).(*logJanitor)
+	return f
+}
+
+// addLog 保存任务日志，age 为距今天数
+func (f *janitorFixture) addLog(t *testing.T, lid string, cid, tid int, age int, status int, size int) {
+	t.Helper()
+	log := &domain.TaskLog{
+		Lid:      lid,
+		Cid:      cid,
+		Tid:      tid,
+		Status:   status,
+		StdOut:   strings.Repeat("x", size),
+		UpdateAt: time.Now().AddDate(0, 0, -age).Add(-time.Minute).Unix(),
+	}
+	if err := f.logs.Save(log); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// remaining 返回未被删除的日志ID
+func (f *janitorFixture) remaining(t *testing.T) []string {
+	t.Helper()
+	logs, err := f.logs.FindForCleanup(&repository.LogCleanupQuery{Oldest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lids := make([]string, 0, len(logs))
+	for _, log := range logs {
+		lids = append(lids, log.Lid)
+	}
+	slices.Sort(lids)
+	return lids
+}
+
+func TestLogJanitorResolve(t *testing.T) {
+	global := &config.RetentionConfig{MaxAge: 30, MaxCount: 100, FailureMaxAge: 90}
+	tests := []struct {
+		name      string
+		cfg       *config.RetentionConfig
+		container *domain.Container
+		task      *domain.Task // nil 表示任务已被彻底删除
+		want      retentionPolicy
+	}{
+		{
+			name: "deleted task uses global",
+			cfg:  global,
+			want: retentionPolicy{maxAge: 30, maxCount: 100, failureMaxAge: 90, keepFailures: true},
+		},
+		{
+			name:      "inherit global",
+			cfg:       global,
+			container: &domain.Container{},
+			task:      &domain.Task{},
+			want:      retentionPolicy{maxAge: 30, maxCount: 100, failureMaxAge: 90, keepFailures: true},
+		},
+		{
+			name:      "container overrides global",
+			cfg:       global,
+			container: &domain.Container{LogMaxAge: 7, LogMaxCount: 10, LogMaxSize: 5},
+			task:      &domain.Task{},
+			want:      retentionPolicy{maxAge: 7, maxCount: 10, failureMaxAge: 90, keepFailures: true},
+		},
+		{
+			name:      "task overrides container",
+			cfg:       global,
+			container: &domain.Container{LogMaxAge: 7, LogMaxCount: 10},
+			task:      &domain.Task{LogMaxAge: 3, LogMaxCount: -1, LogMaxSize: 2},
+			want:      retentionPolicy{maxAge: 3, maxSize: 2 << 20, failureMaxAge: 90, keepFailures: true},
+		},
+		{
+			name:      "keep failures forever",
+			cfg:       global,
+			container: &domain.Container{LogFailureMaxAge: -1},
+			task:      &domain.Task{},
+			want:      retentionPolicy{maxAge: 30, maxCount: 100, keepFailures: true},
+		},
+		{
+			name:      "failures follow normal rules",
+			cfg:       &config.RetentionConfig{MaxAge: 30},
+			container: &domain.Container{},
+			task:      &domain.Task{},
+			want:      retentionPolicy{maxAge: 30},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newJanitorFixture(t, tt.cfg)
+			tid := 99
+			if tt.task != nil {
+				if err := f.containers.Save(tt.container); err != nil {
+					t.Fatal(err)
+				}
+				tt.task.Cid = tt.container.Cid
+				if err := f.tasks.Save(tt.task); err != nil {
+					t.Fatal(err)
+				}
+				tid = tt.task.Tid
+			}
+
+			got := f.janitor.resolve(tid, make(map[int]*domain.Container))
+			if *got != tt.want {
+				t.Errorf("resolve() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogJanitorCleanTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy retentionPolicy
+		want   []string
+	}{
+		{name: "unlimited", want: []string{"a", "b", "c", "d", "e"}},
+		{name: "max count", policy: retentionPolicy{maxCount: 2}, want: []string{"a", "b"}},
+		{name: "max age", policy: retentionPolicy{maxAge: 30}, want: []string{"a", "b", "c"}},
+		{name: "keep failures", policy: retentionPolicy{maxCount: 1, keepFailures: true}, want: []string{"a", "b", "e"}},
+		{name: "failure max age", policy: retentionPolicy{maxAge: 30, failureMaxAge: 45, keepFailures: true}, want: []string{"a", "b", "c"}},
+		{name: "max size deletes failures last", policy: retentionPolicy{maxSize: 250}, want: []string{"b", "e"}},
+		{name: "max size deletes failures when needed", policy: retentionPolicy{maxSize: 150}, want: []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newJanitorFixture(t, &config.RetentionConfig{})
+			f.addLog(t, "a", 1, 1, 1, domain.StatusSuccess, 100)
+			f.addLog(t, "b", 1, 1, 2, domain.StatusFailure, 100)
+			f.addLog(t, "c", 1, 1, 3, domain.StatusSuccess, 100)
+			f.addLog(t, "d", 1, 1, 40, domain.StatusSuccess, 100)
+			f.addLog(t, "e", 1, 1, 50, domain.StatusFailure, 100)
+			f.addLog(t, "other", 1, 2, 60, domain.StatusSuccess, 100)
+
+			report := &RetentionReport{}
+			policy := tt.policy
+			if err := f.janitor.cleanTask(1, &policy, time.Now(), report); err != nil {
+				t.Fatal(err)
+			}
+			got := f.remaining(t)
+			want := append(slices.Clone(tt.want), "other")
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("remaining = %v, want %v", got, want)
+			}
+			if deleted := 5 - len(tt.want); report.Total() != deleted || report.FreedBytes != int64(deleted*100) {
+				t.Errorf("report = %+v, want %d deleted", report, deleted)
+			}
+		})
+	}
+}
+
+func TestLogJanitorSizeScopes(t *testing.T) {
+	const mb = 1 << 20
+	tests := []struct {
+		name      string
+		global    int
+		container int
+		task      int
+		want      []string
+	}{
+		{name: "unlimited", want: []string{"c1t1a", "c1t1b", "c1t2a", "c2t3a", "c2t3b"}},
+		{name: "task limit", task: 1, want: []string{"c1t1a", "c1t2a", "c2t3a", "c2t3b"}},
+		{name: "container limit", container: 1, want: []string{"c1t1a", "c2t3a", "c2t3b"}},
+		{name: "global limit", global: 2, want: []string{"c1t1a", "c1t2a", "c2t3a"}},
+		{name: "all scopes", global: 1, container: 1, task: 1, want: []string{"c1t1a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newJanitorFixture(t, &config.RetentionConfig{MaxSize: tt.global})
+			first := &domain.Container{Name: "c1", LogMaxSize: tt.container}
+			second := &domain.Container{Name: "c2"}
+			for _, c := range []*domain.Container{first, second} {
+				if err := f.containers.Save(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tasks := []*domain.Task{
+				{Cid: first.Cid, Name: "t1", LogMaxSize: tt.task},
+				{Cid: first.Cid, Name: "t2"},
+				{Cid: second.Cid, Name: "t3"},
+			}
+			for _, task := range tasks {
+				if err := f.tasks.Save(task); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// 每条 0.6MB，时间从旧到新：c2t3b, c1t1b, c1t2a, c2t3a, c1t1a
+			size := mb * 6 / 10
+			f.addLog(t, "c2t3b", second.Cid, tasks[2].Tid, 5, domain.StatusSuccess, size)
+			f.addLog(t, "c1t1b", first.Cid, tasks[0].Tid, 4, domain.StatusSuccess, size)
+			f.addLog(t, "c1t2a", first.Cid, tasks[1].Tid, 3, domain.StatusSuccess, size)
+			f.addLog(t, "c2t3a", second.Cid, tasks[2].Tid, 2, domain.StatusSuccess, size)
+			f.addLog(t, "c1t1a", first.Cid, tasks[0].Tid, 1, domain.StatusSuccess, size)
+
+			report, err := f.janitor.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := f.remaining(t)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("remaining = %v, want %v", got, want)
+			}
+			if report.BySize != 5-len(tt.want) {
+				t.Errorf("report = %+v, want %d deleted by size", *report, 5-len(tt.want))
+			}
+		})
+	}
+}
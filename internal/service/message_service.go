@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"slices"
+	"sync/atomic"
 
 	"clock/internal/domain"
+	apperrors "clock/internal/errors"
 	"clock/internal/repository"
 )
 
@@ -12,6 +15,7 @@ type messageService struct {
 	hub           *StreamHub
 	taskRepo      repository.TaskRepository
 	containerRepo repository.ContainerRepository
+	executor      *Executor
 }
 
 // NewMessageService 创建消息服务
@@ -19,11 +23,13 @@ func NewMessageService(
 	hub *StreamHub,
 	taskRepo repository.TaskRepository,
 	containerRepo repository.ContainerRepository,
+	executor *Executor,
 ) MessageService {
 	return &messageService{
 		hub:           hub,
 		taskRepo:      taskRepo,
 		containerRepo: containerRepo,
+		executor:      executor,
 	}
 }
 
@@ -31,15 +37,141 @@ func (s *messageService) Publish(event StreamEvent) {
 	s.hub.Publish(event)
 }
 
-func (s *messageService) Subscribe(ctx context.Context) <-chan StreamEvent {
-	return s.hub.Subscribe(ctx)
+// Subscribe 订阅事件流，lastID > 0 时先回放该 ID 之后的事件
+func (s *messageService) Subscribe(ctx context.Context, filter *StreamFilter, lastID int64) <-chan StreamEvent {
+	return s.hub.Subscribe(ctx, filter, lastID)
 }
 
-// GetCounters 获取任务统计
-func (s *messageService) GetCounters() []domain.TaskCounter {
+// FollowStream 跟随批次或任务的事件流
+type FollowStream struct {
+	Events <-chan StreamEvent // 收到结束事件、客户端断开或批次结束后关闭
+	ended  atomic.Bool
+}
+
+// Ended 事件流是否因收到结束事件（run_end 或 task_end）而关闭，Events 关闭后读取
+func (f *FollowStream) Ended() bool {
+	return f.ended.Load()
+}
+
+// Follow 跟随单个批次（RunID）或单个任务（Tid）的事件，批次或任务结束后关闭事件流
+//
+// 订阅者过慢被断开时从最后收到的事件重新订阅；批次已结束但结束事件已从缓冲区淘汰时，
+// 回放剩余事件后关闭，此时 Ended 返回 false。
+func (s *messageService) Follow(ctx context.Context, filter *StreamFilter, lastID int64) (*FollowStream, error) {
+	// 结束事件用于判断何时关闭，不受事件类型过滤影响
+	endKind := "run_end"
+	if filter.RunID == "" {
+		if filter.Tid <= 0 {
+			return nil, apperrors.InvalidParam("runId or tid is required")
+		}
+		endKind = "task_end"
+	}
+	if err := s.checkVisible(filter); err != nil {
+		return nil, err
+	}
+	kinds := filter.Kinds
+	sub := *filter
+	if len(kinds) > 0 && !slices.Contains(kinds, endKind) {
+		sub.Kinds = append(slices.Clone(kinds), endKind)
+	}
+
+	active := func() bool {
+		if filter.RunID != "" {
+			return s.executor.IsRunActive(filter.RunID)
+		}
+		return s.executor.IsTaskRunning(filter.Tid)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	events := s.hub.Subscribe(ctx, &sub, lastID)
+
+	// 先订阅再检查，避免错过订阅前刚结束的事件
+	if !active() {
+		cancel()
+		if filter.RunID != "" {
+			return nil, apperrors.NotFound("running run")
+		}
+		return nil, apperrors.NotFound("running task")
+	}
+
+	out := make(chan StreamEvent)
+	stream := &FollowStream{Events: out}
+
+	// send 转发事件，返回是否继续
+	send := func(ev StreamEvent) bool {
+		lastID = ev.ID
+		end := ev.Kind == endKind
+		if !end || len(kinds) == 0 || slices.Contains(kinds, endKind) {
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if end {
+			stream.ended.Store(true)
+			return false
+		}
+		return true
+	}
+
+	go func() {
+		defer close(out)
+		defer cancel()
+		for {
+			for ev := range events {
+				if !send(ev) {
+					return
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			// 未收到结束事件时事件流被关闭：订阅者过慢被断开，从最后收到的事件继续
+			if active() {
+				events = s.hub.Subscribe(ctx, &sub, lastID)
+				continue
+			}
+			for _, ev := range s.hub.History(&sub, lastID) {
+				if !send(ev) {
+					return
+				}
+			}
+			return
+		}
+	}()
+	return stream, nil
+}
+
+// checkVisible 检查跟随的批次或任务所属的容器对当前用户可见，不可见时视为不存在
+func (s *messageService) checkVisible(filter *StreamFilter) error {
+	if filter.Cids == nil {
+		return nil
+	}
+	if filter.RunID != "" {
+		cid, ok := s.executor.RunCid(filter.RunID)
+		if !ok || !slices.Contains(filter.Cids, cid) {
+			return apperrors.NotFound("running run")
+		}
+		return nil
+	}
+	task, err := s.taskRepo.GetByID(filter.Tid)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(filter.Cids, task.Cid) {
+		return apperrors.NotFound("task")
+	}
+	return nil
+}
+
+// GetCounters 获取任务统计，cids 不为 nil 时只统计这些容器的任务
+func (s *messageService) GetCounters(cids []int) []domain.TaskCounter {
 	// 获取所有任务
 	tasks, _ := s.taskRepo.List(&repository.TaskQuery{
 		Page: repository.Page{Count: 10000},
+		Cids: cids,
 	})
 
 	// 统计各状态数量
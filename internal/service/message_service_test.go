@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"clock/internal/config"
+	apperrors "clock/internal/errors"
+)
+
+func TestMessageServiceFollow(t *testing.T) {
+	tests := []struct {
+		name      string
+		history   int
+		cids      []int
+		inactive  bool
+		events    int  // 跟随期间发布的批次事件数
+		evictEnd  bool // 批次结束后发布其他事件，使 run_end 被淘汰
+		wantErr   bool
+		wantEnded bool
+	}{
+		{name: "invisible run", history: 100, cids: []int{2}, wantErr: true},
+		{name: "inactive run", history: 100, inactive: true, wantErr: true},
+		{name: "visible run", history: 100, cids: []int{1}, events: 3, wantEnded: true},
+		{name: "slow subscriber resumes", history: 100, events: 50, wantEnded: true},
+		{name: "end evicted", history: 8, events: 50, evictEnd: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewStreamHub(2, tt.history)
+			executor := NewExecutor(nil, nil, nil, nil, nil, nil, hub, &config.TaskLogConfig{}, &config.MaskingConfig{})
+			if !tt.inactive {
+				executor.activeRuns["r1"] = struct{}{}
+				executor.runningContainers[1] = "r1"
+			}
+			svc := NewMessageService(hub, nil, nil, executor)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream, err := svc.Follow(ctx, &StreamFilter{RunID: "r1", Cids: tt.cids}, 0)
+			if tt.wantErr {
+				if !apperrors.IsNotFound(err) {
+					t.Fatalf("Follow() error = %v, want not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 不读取事件流，使订阅者缓冲区写满被断开
+			for i := 0; i < tt.events; i++ {
+				hub.Publish(StreamEvent{Kind: "stdout", RunID: "r1", Cid: 1})
+			}
+			hub.Publish(StreamEvent{Kind: "run_end", RunID: "r1", Cid: 1})
+			executor.activeRunsMu.Lock()
+			delete(executor.activeRuns, "r1")
+			executor.activeRunsMu.Unlock()
+			if tt.evictEnd {
+				for i := 0; i < tt.history; i++ {
+					hub.Publish(StreamEvent{Kind: "stdout", RunID: "r2", Cid: 1})
+				}
+			}
+
+			var ids []int64
+			var kinds []string
+			for ev := range stream.Events {
+				ids = append(ids, ev.ID)
+				kinds = append(kinds, ev.Kind)
+			}
+			if stream.Ended() != tt.wantEnded {
+				t.Fatalf("Ended() = %v, want %v (kinds %v)", stream.Ended(), tt.wantEnded, kinds)
+			}
+			if !tt.wantEnded {
+				return
+			}
+			if len(ids) != tt.events+1 || kinds[len(kinds)-1] != "run_end" {
+				t.Fatalf("got %d events ending with %v, want %d ending with run_end", len(ids), kinds[len(kinds)-1], tt.events+1)
+			}
+			for i, id := range ids {
+				if id != int64(i+1) {
+					t.Fatalf("event %d has id %d, want %d", i, id, i+1)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,634 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/notify"
+	"clock/internal/repository"
+)
+
+// notifySendTimeout 单条通知在所有通道上的发送超时
+const notifySendTimeout = 30 * time.Second
+
+// notifyStatuses 可触发通知的结束状态，sla_miss 表示 SLA 未达成
+var notifyStatuses = []string{"success", "failure", "cancelled", "sla_miss"}
+
+// notifyFilter 通知服务订阅的事件，错误输出用于附加到任务结束通知
+var notifyFilter = &StreamFilter{Kinds: []string{"run_start", "run_end", "task_start", "task_end", "sla_miss", domain.LogStreamStderr}}
+
+// NotifyChannelInfo 通知通道信息
+type NotifyChannelInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// notifyService 通知服务实现
+//
+// 订阅事件流，在任务和批次结束时按规则发送通知。任务的错误输出在内存中
+// 保留最近 N 行，任务结束时附加到消息中。
+type notifyService struct {
+	cfg           *config.NotifyConfig
+	repo          repository.NotifyRuleRepository
+	taskRepo      repository.TaskRepository
+	containerRepo repository.ContainerRepository
+	taskRunRepo   repository.TaskRunRepository
+	taskLogRepo   repository.TaskLogRepository
+	hub           *StreamHub
+
+	channels  map[string]notify.Channel
+	templates map[string]*template.Template // 通道的消息模板
+	names     []string                      // 按配置顺序排列的通道名称
+
+	stderr map[int][]string    // 运行中任务最近的错误输出
+	failed map[string][]string // 运行中批次失败的任务名称
+
+	cancel  context.CancelFunc
+	done    chan struct{}
+	sending sync.WaitGroup
+}
+
+// NewNotifyService 创建通知服务，无效的通道配置记录错误后忽略
+func NewNotifyService(
+	cfg *config.NotifyConfig,
+	repo repository.NotifyRuleRepository,
+	taskRepo repository.TaskRepository,
+	containerRepo repository.ContainerRepository,
+	taskRunRepo repository.TaskRunRepository,
+	taskLogRepo repository.TaskLogRepository,
+	hub *StreamHub,
+) NotifyService {
+	s := &notifyService{
+		cfg:           cfg,
+		repo:          repo,
+		taskRepo:      taskRepo,
+		containerRepo: containerRepo,
+		taskRunRepo:   taskRunRepo,
+		taskLogRepo:   taskLogRepo,
+		hub:           hub,
+		channels:      make(map[string]notify.Channel),
+		templates:     make(map[string]*template.Template),
+	}
+	for i := range cfg.Channels {
+		chCfg := &cfg.Channels[i]
+		ch, err := notify.New(chCfg)
+		if err != nil {
+			logger.Errorf("[notify] invalid channel: %v", err)
+			continue
+		}
+		if _, ok := s.channels[ch.Name()]; ok {
+			logger.Errorf("[notify] duplicate channel name %q", ch.Name())
+			continue
+		}
+		if chCfg.Template != "" {
+			tmpl, err := notify.ParseTemplate(chCfg.Template)
+			if err != nil {
+				logger.Errorf("[notify] invalid template of channel %s: %v", ch.Name(), err)
+				continue
+			}
+			s.templates[ch.Name()] = tmpl
+		}
+		s.channels[ch.Name()] = ch
+		s.names = append(s.names, ch.Name())
+	}
+	return s
+}
+
+// Start 开始监听任务和批次结束事件，未配置通道时不启动
+func (s *notifyService) Start() {
+	if len(s.channels) == 0 || s.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.stderr = make(map[int][]string)
+	s.failed = make(map[string][]string)
+
+	// 先订阅再返回，启动后发生的结束事件都会被处理
+	events := s.hub.Subscribe(ctx, notifyFilter, 0)
+	logger.Infof("[notify] notify service started, channels: %s", strings.Join(s.names, ", "))
+	go s.listen(ctx, events)
+}
+
+// Stop 停止监听并等待发送中的通知完成
+func (s *notifyService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+	s.sending.Wait()
+	s.cancel = nil
+}
+
+// listen 订阅事件流，写入过慢被断开时从最后收到的事件处继续
+func (s *notifyService) listen(ctx context.Context, events <-chan StreamEvent) {
+	defer close(s.done)
+
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Warnf("[notify] subscription dropped, resuming from event %d", lastID)
+				events = s.hub.Subscribe(ctx, notifyFilter, lastID)
+				continue
+			}
+			lastID = ev.ID
+			s.handle(ctx, &ev)
+		}
+	}
+}
+
+// handle 处理单个事件
+func (s *notifyService) handle(ctx context.Context, ev *StreamEvent) {
+	switch ev.Kind {
+	case "task_start":
+		delete(s.stderr, ev.Tid)
+	case domain.LogStreamStderr:
+		lines := append(s.stderr[ev.Tid], ev.Msg)
+		if len(lines) > s.cfg.StderrLines {
+			lines = lines[len(lines)-s.cfg.StderrLines:]
+		}
+		s.stderr[ev.Tid] = lines
+	case "run_start":
+		delete(s.failed, ev.RunID)
+	case "task_end":
+		stderr := s.stderr[ev.Tid]
+		delete(s.stderr, ev.Tid)
+		if ev.RunID != "" && ev.Status == "failure" {
+			s.failed[ev.RunID] = append(s.failed[ev.RunID], ev.TaskName)
+		}
+
+		rules, err := s.repo.FindForTask(ev.Tid, ev.Cid)
+		if err != nil {
+			logger.Errorf("[notify] failed to find rules of task %d: %v", ev.Tid, err)
+			return
+		}
+		s.dispatch(ctx, rules, ev.Status, func() *notify.Message {
+			return s.taskMessage(ev, stderr)
+		})
+	case "run_end":
+		failed := s.failed[ev.RunID]
+		delete(s.failed, ev.RunID)
+
+		rules, err := s.repo.FindForRun(ev.Cid)
+		if err != nil {
+			logger.Errorf("[notify] failed to find rules of container %d: %v", ev.Cid, err)
+			return
+		}
+		s.dispatch(ctx, rules, ev.Status, func() *notify.Message {
+			return s.runMessage(ev, failed)
+		})
+	case "sla_miss":
+		var rules []*domain.NotifyRule
+		var err error
+		if ev.Tid > 0 {
+			rules, err = s.repo.FindForTask(ev.Tid, ev.Cid)
+		} else {
+			rules, err = s.repo.FindForRun(ev.Cid)
+		}
+		if err != nil {
+			logger.Errorf("[notify] failed to find rules of sla miss: %v", err)
+			return
+		}
+		s.dispatch(ctx, rules, ev.Kind, func() *notify.Message {
+			msg := s.runMessage(ev, nil)
+			msg.Status = ev.Kind
+			msg.Tid = ev.Tid
+			msg.TaskName = ev.TaskName
+			return msg
+		})
+	}
+}
+
+// message 根据结束事件创建通知消息
+func (s *notifyService) message(ev *StreamEvent) *notify.Message {
+	msg := &notify.Message{
+		Event:      ev.Kind,
+		Status:     ev.Status,
+		Cid:        ev.Cid,
+		RunID:      ev.RunID,
+		DurationMs: ev.DurationMs,
+		Error:      ev.Msg,
+		Time:       time.Now().Unix(),
+	}
+	if ev.TS > 0 {
+		msg.Time = ev.TS / 1000
+	}
+	if container, err := s.containerRepo.GetByIDUnscoped(ev.Cid); err == nil {
+		msg.ContainerName = container.Name
+	}
+	return msg
+}
+
+// taskMessage 创建任务结束消息
+func (s *notifyService) taskMessage(ev *StreamEvent, stderr []string) *notify.Message {
+	msg := s.message(ev)
+	msg.TaskName = ev.TaskName
+	msg.Tid = ev.Tid
+	msg.ExitCode = ev.ExitCode
+	msg.Stderr = stderr
+	if s.cfg.BaseURL != "" {
+		msg.URL = fmt.Sprintf("%s/log/list?cid=%d&tid=%d", strings.TrimRight(s.cfg.BaseURL, "/"), ev.Cid, ev.Tid)
+	}
+	return msg
+}
+
+// runMessage 创建批次结束消息
+func (s *notifyService) runMessage(ev *StreamEvent, failed []string) *notify.Message {
+	msg := s.message(ev)
+	msg.Failed = failed
+	if s.cfg.BaseURL != "" {
+		msg.URL = fmt.Sprintf("%s/log/list?cid=%d", strings.TrimRight(s.cfg.BaseURL, "/"), ev.Cid)
+	}
+	return msg
+}
+
+// delivery 一次发送：同一通道使用不同模板的规则分别发送
+type delivery struct {
+	channel  notify.Channel
+	template string // 规则模板，为空使用通道模板
+}
+
+// dispatch 选出匹配结束状态的规则，按通道和模板去重后异步发送
+func (s *notifyService) dispatch(ctx context.Context, rules []*domain.NotifyRule, status string, build func() *notify.Message) {
+	var deliveries []delivery
+	for _, rule := range rules {
+		if !slices.Contains(splitList(rule.Statuses), status) {
+			continue
+		}
+		ch, ok := s.channels[rule.Channel]
+		if !ok {
+			logger.Warnf("[notify] rule %d refers to unknown channel %q", rule.ID, rule.Channel)
+			continue
+		}
+		d := delivery{channel: ch, template: rule.Template}
+		if !slices.Contains(deliveries, d) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	base := build()
+
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+		// 服务停止时仍发送已产生的通知，只受发送超时限制
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifySendTimeout)
+		defer cancel()
+		for _, d := range deliveries {
+			msg := *base
+			if err := s.render(&msg, d.channel.Name(), d.template); err != nil {
+				// 模板执行失败时退回默认模板，避免丢失通知
+				logger.Errorf("[notify] failed to render message for %s: %v", d.channel.Name(), err)
+				_ = notify.Render(&msg, nil)
+			}
+			if err := d.channel.Send(sendCtx, &msg); err != nil {
+				logger.Errorf("[notify] failed to send %q via %s: %v", msg.Title, d.channel.Name(), err)
+			}
+		}
+	}()
+}
+
+// render 按 规则模板 > 通道模板 > 默认模板 的顺序渲染消息
+func (s *notifyService) render(msg *notify.Message, channel string, ruleTemplate string) error {
+	tmpl := s.templates[channel]
+	if ruleTemplate != "" {
+		var err error
+		if tmpl, err = notify.ParseTemplate(ruleTemplate); err != nil {
+			return err
+		}
+	}
+	return notify.Render(msg, tmpl)
+}
+
+// splitList 解析逗号分隔的列表
+func splitList(statuses string) []string {
+	var result []string
+	for _, status := range strings.Split(statuses, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+// Channels 获取已配置的通知通道
+func (s *notifyService) Channels() []NotifyChannelInfo {
+	infos := make([]NotifyChannelInfo, 0, len(s.names))
+	for _, name := range s.names {
+		infos = append(infos, NotifyChannelInfo{Name: name, Type: s.channels[name].Type()})
+	}
+	return infos
+}
+
+// ListRules 获取容器或任务的通知规则
+func (s *notifyService) ListRules(cid int, tid int) ([]*domain.NotifyRule, error) {
+	return s.repo.List(cid, tid)
+}
+
+// SaveRule 校验并保存通知规则，任务规则的容器ID取自任务
+func (s *notifyService) SaveRule(rule *domain.NotifyRule) error {
+	if _, ok := s.channels[rule.Channel]; !ok {
+		return apperrors.InvalidParam("unknown channel: " + rule.Channel)
+	}
+
+	statuses := splitList(rule.Statuses)
+	if len(statuses) == 0 {
+		return apperrors.InvalidParam("statuses is required")
+	}
+	for _, status := range statuses {
+		if !slices.Contains(notifyStatuses, status) {
+			return apperrors.InvalidParam("invalid status: " + status)
+		}
+	}
+	rule.Statuses = strings.Join(statuses, ",")
+
+	if rule.Template != "" {
+		if _, err := notify.ParseTemplate(rule.Template); err != nil {
+			return apperrors.InvalidParam("invalid template: " + err.Error())
+		}
+	}
+
+	if rule.Tid > 0 {
+		task, err := s.taskRepo.GetByID(rule.Tid)
+		if err != nil {
+			return err
+		}
+		rule.Cid = task.Cid
+		rule.Scope = ""
+	} else {
+		if rule.Cid <= 0 {
+			return apperrors.InvalidParam("cid or tid is required")
+		}
+		if _, err := s.containerRepo.GetByID(rule.Cid); err != nil {
+			return err
+		}
+		switch rule.Scope {
+		case "":
+			rule.Scope = domain.NotifyScopeRun
+		case domain.NotifyScopeRun, domain.NotifyScopeTask:
+		default:
+			return apperrors.InvalidParam("invalid scope: " + rule.Scope)
+		}
+	}
+
+	if rule.ID > 0 {
+		if _, err := s.repo.GetByID(rule.ID); err != nil {
+			return err
+		}
+	}
+	return s.repo.Save(rule)
+}
+
+// DeleteRule 删除通知规则
+func (s *notifyService) DeleteRule(id int) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Test 通过指定通道发送一条测试消息
+func (s *notifyService) Test(ctx context.Context, channel string) error {
+	ch, ok := s.channels[channel]
+	if !ok {
+		return apperrors.NotFound("channel")
+	}
+
+	exitCode := 1
+	msg := &notify.Message{
+		Event:      "task_end",
+		Status:     "failure",
+		TaskName:   "notify test",
+		RunID:      "test",
+		DurationMs: 1500,
+		ExitCode:   &exitCode,
+		Error:      "this is a test notification",
+		Stderr:     []string{"test stderr line"},
+		Time:       time.Now().Unix(),
+	}
+	if err := s.render(msg, channel, ""); err != nil {
+		return apperrors.InvalidParam("invalid template: " + err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifySendTimeout)
+	defer cancel()
+	if err := ch.Send(ctx, msg); err != nil {
+		return apperrors.Notify(err)
+	}
+	return nil
+}
+
+// Send 通过指定通道发送已生成内容的消息，不使用模板
+func (s *notifyService) Send(ctx context.Context, channel string, msg *notify.Message) error {
+	ch, ok := s.channels[channel]
+	if !ok {
+		return apperrors.NotFound("channel")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifySendTimeout)
+	defer cancel()
+	if err := ch.Send(ctx, msg); err != nil {
+		return apperrors.Notify(err)
+	}
+	return nil
+}
+
+// Preview 使用历史执行记录渲染消息模板
+//
+// 指定 Tid 时渲染任务结束消息（RunID 为空时使用任务最近一次执行），
+// 只指定 RunID 时渲染批次结束消息。
+func (s *notifyService) Preview(req *NotifyPreviewRequest) (*NotifyPreview, error) {
+	tmplText := req.Template
+	channel := req.Channel
+	if req.RuleID > 0 {
+		rule, err := s.repo.GetByID(req.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		if channel == "" {
+			channel = rule.Channel
+		}
+		if tmplText == "" {
+			tmplText = rule.Template
+		}
+	}
+	if channel != "" {
+		if _, ok := s.channels[channel]; !ok {
+			return nil, apperrors.NotFound("channel")
+		}
+	}
+
+	var msg *notify.Message
+	var err error
+	switch {
+	case req.Tid > 0:
+		msg, err = s.previewTask(req.RunID, req.Tid)
+	case req.RunID != "":
+		msg, err = s.previewRun(req.RunID)
+	default:
+		return nil, apperrors.InvalidParam("run_id or tid is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.render(msg, channel, tmplText); err != nil {
+		return nil, apperrors.InvalidParam("invalid template: " + err.Error())
+	}
+	return &NotifyPreview{Title: msg.Title, Text: msg.Text, Message: msg}, nil
+}
+
+// previewTask 根据任务的执行记录创建任务结束消息
+func (s *notifyService) previewTask(runID string, tid int) (*notify.Message, error) {
+	var runs []*domain.TaskRun
+	var err error
+	if runID != "" {
+		runs, err = s.taskRunRepo.ListByRunID(runID)
+	} else {
+		runs, err = s.taskRunRepo.ListRecentByTID(tid, 1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var run *domain.TaskRun
+	for _, r := range runs {
+		if r.Tid == tid {
+			run = r
+			break
+		}
+	}
+	if run == nil {
+		return nil, apperrors.NotFound("task run")
+	}
+
+	ev := runRecordEvent(run)
+	if task, err := s.taskRepo.GetByIDUnscoped(tid); err == nil {
+		ev.TaskName = task.Name
+	}
+	return s.taskMessage(ev, s.stderrTail(run)), nil
+}
+
+// previewRun 根据批次内的执行记录创建批次结束消息
+func (s *notifyService) previewRun(runID string) (*notify.Message, error) {
+	runs, err := s.taskRunRepo.ListByRunID(runID)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, apperrors.NotFound("run")
+	}
+
+	ev := &StreamEvent{Kind: "run_end", RunID: runID, Cid: runs[0].Cid}
+	status := domain.StatusSuccess
+	var startAt, endAt int64
+	var failed []string
+	for _, run := range runs {
+		if startAt == 0 || run.StartAt < startAt {
+			startAt = run.StartAt
+		}
+		if run.EndAt > endAt {
+			endAt = run.EndAt
+		}
+		switch run.Status {
+		case domain.StatusCancelled:
+			status = domain.StatusCancelled
+		case domain.StatusFailure:
+			if status != domain.StatusCancelled {
+				status = domain.StatusFailure
+			}
+			name := fmt.Sprintf("#%d", run.Tid)
+			if task, err := s.taskRepo.GetByIDUnscoped(run.Tid); err == nil {
+				name = task.Name
+			}
+			failed = append(failed, name)
+		}
+	}
+	ev.Status = domain.StatusText(status)
+	ev.DurationMs = endAt - startAt
+	ev.TS = endAt
+	return s.runMessage(ev, failed), nil
+}
+
+// runRecordEvent 将执行记录转换为任务结束事件
+func runRecordEvent(run *domain.TaskRun) *StreamEvent {
+	ev := &StreamEvent{
+		Kind:       "task_end",
+		TS:         run.EndAt,
+		RunID:      run.RunID,
+		Tid:        run.Tid,
+		Cid:        run.Cid,
+		Status:     domain.StatusText(run.Status),
+		DurationMs: run.Duration,
+		Msg:        run.Msg,
+	}
+	if run.ExitCode >= 0 {
+		code := run.ExitCode
+		ev.ExitCode = &code
+	}
+	return ev
+}
+
+// stderrTail 读取执行记录对应日志的最后 N 行错误输出，没有日志时返回空
+func (s *notifyService) stderrTail(run *domain.TaskRun) []string {
+	var log *domain.TaskLog
+	if run.RunID != "" {
+		logs, err := s.taskLogRepo.FindByRunID(run.RunID)
+		if err != nil {
+			return nil
+		}
+		for _, l := range logs {
+			if l.Tid == run.Tid {
+				log = l
+			}
+		}
+	} else {
+		logs, err := s.taskLogRepo.List(&repository.LogQuery{Page: repository.Page{Count: 1}, Tid: run.Tid})
+		if err != nil || len(logs) == 0 {
+			return nil
+		}
+		log = logs[0]
+	}
+	if log == nil {
+		return nil
+	}
+
+	rc, err := s.taskLogRepo.OpenOutput(log.Lid, domain.LogStreamStderr)
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > s.cfg.StderrLines {
+		lines = lines[len(lines)-s.cfg.StderrLines:]
+	}
+	return lines
+}
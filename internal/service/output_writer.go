@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"clock/internal/domain"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// logFlushInterval 运行中输出的落盘间隔
+const logFlushInterval = time.Second
+
+// outputWriter 任务输出写入器
+//
+// 输出按行附带时间戳和输出流增量写入日志存储，超过上限后只保留头部和尾部：
+// 头部直接写入存储，尾部保存在有界队列中，结束时附加截断标记后写入。
+type outputWriter struct {
+	mu        sync.Mutex
+	w         repository.LogWriter
+	log       *domain.TaskLog
+	seq       int64 // 已分配的行号
+	headLimit int64
+	written   int64 // 已写入存储的头部字节数
+	tail      *lineQueue
+	dirty     bool
+	err       error
+	done      chan struct{}
+}
+
+// newOutputWriter 创建任务输出写入器，maxBytes 为日志输出的保存上限
+func newOutputWriter(w repository.LogWriter, log *domain.TaskLog, maxBytes int64) *outputWriter {
+	headLimit := maxBytes / 2
+
+	o := &outputWriter{
+		w:         w,
+		log:       log,
+		headLimit: headLimit,
+		tail:      &lineQueue{limit: maxBytes - headLimit},
+		done:      make(chan struct{}),
+	}
+	go o.flushLoop()
+	return o
+}
+
+// WriteLine 写入一行输出，nil 写入器（未启用日志）忽略
+func (o *outputWriter) WriteLine(stream string, text string) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.err != nil {
+		return
+	}
+
+	o.seq++
+	line := &domain.LogLine{
+		No:     o.seq,
+		Ts:     time.Now().UnixMilli(),
+		Stream: stream,
+		Text:   text,
+	}
+
+	if o.written < o.headLimit {
+		if err := o.w.Write(line); err != nil {
+			o.fail(err)
+			return
+		}
+		o.written += lineSize(line)
+		o.dirty = true
+		return
+	}
+	o.tail.push(line)
+}
+
+// Close 写入尾部输出并以任务结束状态结束日志
+func (o *outputWriter) Close(status int) {
+	if o == nil {
+		return
+	}
+	close(o.done)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.log.Status = status
+	if o.err == nil {
+		var lines []*domain.LogLine
+		if o.tail.dropped > 0 {
+			o.log.Truncated = true
+			lines = append(lines, &domain.LogLine{
+				No:     o.tail.firstDropped.No,
+				Ts:     o.tail.firstDropped.Ts,
+				Stream: domain.LogStreamMeta,
+				Text:   fmt.Sprintf("... [truncated %d lines, %d bytes] ...", o.tail.dropped, o.tail.droppedBytes),
+			})
+		}
+		lines = append(lines, o.tail.lines()...)
+
+		for _, line := range lines {
+			if err := o.w.Write(line); err != nil {
+				o.fail(err)
+				break
+			}
+		}
+	}
+
+	if err := o.w.Close(); err != nil {
+		logger.Errorf("[%d] failed to close log %s: %v", o.log.Tid, o.log.Lid, err)
+	}
+}
+
+// flushLoop 定期落盘，使运行中的输出可通过日志接口读取
+func (o *outputWriter) flushLoop() {
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-ticker.C:
+			o.mu.Lock()
+			if o.dirty && o.err == nil {
+				if err := o.w.Flush(); err != nil {
+					o.fail(err)
+				}
+				o.dirty = false
+			}
+			o.mu.Unlock()
+		}
+	}
+}
+
+// fail 记录写入错误，之后的输出不再写入存储
+func (o *outputWriter) fail(err error) {
+	o.err = err
+	logger.Errorf("[%d] failed to write log %s: %v", o.log.Tid, o.log.Lid, err)
+}
+
+// lineSize 行记录占用的字节数（按内容估算）
+func lineSize(line *domain.LogLine) int64 {
+	return int64(len(line.Text)) + 1
+}
+
+// lineQueue 有界行队列，总大小超过 limit 时丢弃最早的行
+type lineQueue struct {
+	limit        int64
+	size         int64
+	items        []*domain.LogLine
+	start        int
+	dropped      int64
+	droppedBytes int64
+	firstDropped *domain.LogLine
+}
+
+// push 追加一行，超出容量时从队首丢弃
+func (q *lineQueue) push(line *domain.LogLine) {
+	q.items = append(q.items, line)
+	q.size += lineSize(line)
+
+	for q.size > q.limit && q.start < len(q.items) {
+		head := q.items[q.start]
+		q.items[q.start] = nil
+		q.start++
+		q.size -= lineSize(head)
+		q.dropped++
+		q.droppedBytes += lineSize(head)
+		if q.firstDropped == nil {
+			q.firstDropped = head
+		}
+	}
+
+	// 已丢弃部分超过一半时压缩底层数组
+	if q.start > len(q.items)/2 {
+		n := copy(q.items, q.items[q.start:])
+		clear(q.items[n:])
+		q.items = q.items[:n]
+		q.start = 0
+	}
+}
+
+// lines 按顺序返回保留的行
+func (q *lineQueue) lines() []*domain.LogLine {
+	return q.items[q.start:]
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"clock/internal/domain"
+	apperrors "clock/internal/errors"
 	"clock/internal/repository"
 	"clock/pkg/util"
 )
@@ -10,30 +11,42 @@ import (
 type relationService struct {
 	relationRepo repository.RelationRepository
 	taskRepo     repository.TaskRepository
+	taskRunRepo  repository.TaskRunRepository
+	groupRepo    repository.TaskGroupRepository
 }
 
 // NewRelationService 创建关系服务
 func NewRelationService(
 	relationRepo repository.RelationRepository,
 	taskRepo repository.TaskRepository,
+	taskRunRepo repository.TaskRunRepository,
+	groupRepo repository.TaskGroupRepository,
 ) RelationService {
 	return &relationService{
 		relationRepo: relationRepo,
 		taskRepo:     taskRepo,
+		taskRunRepo:  taskRunRepo,
+		groupRepo:    groupRepo,
 	}
 }
 
 // GetGraph 获取关系图
-func (s *relationService) GetGraph(cid int) (*domain.RelationGraph, error) {
+func (s *relationService) GetGraph(cid int, opts *GraphOptions) (*domain.RelationGraph, error) {
 	tasks, err := s.taskRepo.GetByCID(cid)
 	if err != nil {
 		return nil, err
 	}
 
+	groups, err := s.groupRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(tasks) == 0 {
 		return &domain.RelationGraph{
-			Nodes: []domain.Node{},
-			Links: []domain.Link{},
+			Nodes:  []domain.Node{},
+			Links:  []domain.Link{},
+			Groups: derefGroups(groups),
 		}, nil
 	}
 
@@ -42,7 +55,120 @@ func (s *relationService) GetGraph(cid int) (*domain.RelationGraph, error) {
 		return nil, err
 	}
 
-	return s.makeGraph(tasks, relations), nil
+	graph := s.makeGraph(tasks, relations)
+	graph.Groups = derefGroups(groups)
+
+	if opts.Stats {
+		expanded := expandGroupRelations(tasks, groups, relations)
+		if err := s.fillStats(graph, tasks, expanded, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// 折叠任务组：默认使用任务组的折叠设置，可通过参数追加折叠或展开
+	collapsed := make(map[int]bool)
+	for _, group := range groups {
+		collapsed[group.Gid] = group.Collapsed
+	}
+	for _, gid := range opts.Collapse {
+		collapsed[gid] = true
+	}
+	for _, gid := range opts.Expand {
+		collapsed[gid] = false
+	}
+	for _, c := range collapsed {
+		if c {
+			collapseGraph(graph, groups, relations, collapsed)
+			break
+		}
+	}
+
+	return graph, nil
+}
+
+// derefGroups 转换为值切片用于输出
+func derefGroups(groups []*domain.TaskGroup) []domain.TaskGroup {
+	result := make([]domain.TaskGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	return result
+}
+
+// fillStats 填充节点历史统计、关键路径和预计耗时
+func (s *relationService) fillStats(graph *domain.RelationGraph, tasks []*domain.Task, relations []*domain.Relation, opts *GraphOptions) error {
+	runs := opts.Runs
+	if runs <= 0 {
+		runs = defaultStatsRuns
+	}
+	window := opts.Window
+	if window <= 0 {
+		window = defaultStatsWindow
+	}
+	if window < runs {
+		window = runs
+	}
+
+	tids := make([]int, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		tids = append(tids, node.ID)
+	}
+	history, err := s.taskRunRepo.ListRecentByTIDs(tids, window)
+	if err != nil {
+		return err
+	}
+
+	weight := make(map[int]int64, len(tasks))
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		node.Stats = buildNodeStats(history[node.ID], runs)
+		weight[node.ID] = node.Stats.AvgDuration
+
+		// 执行器按阶段串行执行，预计总耗时为各任务平均耗时之和
+		graph.EstimatedDuration += node.Stats.AvgDuration
+	}
+
+	path, total := criticalPath(tasks, relations, weight)
+	graph.CriticalPath = path
+	graph.CriticalDuration = total
+
+	for i := range graph.Nodes {
+		graph.Nodes[i].Stats.Critical = util.ContainsInt(path, graph.Nodes[i].ID)
+	}
+
+	return nil
+}
+
+// ExportGraph 导出关系图（dot, mermaid, svg）
+func (s *relationService) ExportGraph(cid int, opts *GraphExportOptions) ([]byte, error) {
+	tasks, err := s.taskRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	relations, err := s.relationRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.groupRepo.GetByCID(cid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 导出任务级别的依赖关系
+	relations = expandGroupRelations(tasks, groups, relations)
+
+	switch opts.Format {
+	case domain.GraphFormatDOT:
+		return exportDOT(cid, tasks, relations, opts), nil
+	case domain.GraphFormatMermaid:
+		return exportMermaid(tasks, relations, opts), nil
+	case domain.GraphFormatSVG:
+		return exportSVG(tasks, relations, opts), nil
+	default:
+		return nil, apperrors.InvalidParam("unsupported graph format: " + opts.Format)
+	}
 }
 
 // Add 添加关系
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"clock/internal/domain"
+	"clock/internal/repository"
+)
+
+// countingRunRepo 统计执行记录的查询次数
+type countingRunRepo struct {
+	repository.TaskRunRepository
+	queries int
+}
+
+func (r *countingRunRepo) ListRecentByTID(tid int, limit int) ([]*domain.TaskRun, error) {
+	r.queries++
+	return r.TaskRunRepository.ListRecentByTID(tid, limit)
+}
+
+func (r *countingRunRepo) ListRecentByTIDs(tids []int, limit int) (map[int][]*domain.TaskRun, error) {
+	r.queries++
+	return r.TaskRunRepository.ListRecentByTIDs(tids, limit)
+}
+
+func TestRelationGraphStats(t *testing.T) {
+	db := newTestDB(t)
+	tasks := repository.NewTaskRepository(db)
+	relations := repository.NewRelationRepository(db)
+	runs := &countingRunRepo{TaskRunRepository: repository.NewTaskRunRepository(db)}
+	svc := NewRelationService(relations, tasks, runs, repository.NewTaskGroupRepository(db))
+
+	// a→b 与 c 并行，a、b 合计耗时超过 c
+	list := []*domain.Task{{Cid: 1, Name: "a"}, {Cid: 1, Name: "b"}, {Cid: 1, Name: "c"}}
+	for _, task := range list {
+		if err := tasks.Save(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := relations.Save(&domain.Relation{Cid: 1, Tid: list[0].Tid, NextTid: list[1].Tid}); err != nil {
+		t.Fatal(err)
+	}
+	history := map[int][]int64{list[0].Tid: {100, 300}, list[1].Tid: {400}, list[2].Tid: {450}}
+	var end int64
+	for tid, durations := range history {
+		for _, d := range durations {
+			end++
+			if err := runs.Save(&domain.TaskRun{Tid: tid, Cid: 1, Status: domain.StatusSuccess, EndAt: end, Duration: d}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	graph, err := svc.GetGraph(1, &GraphOptions{Stats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs.queries != 1 {
+		t.Errorf("run history queried %d times, want 1", runs.queries)
+	}
+
+	avg := map[int]int64{list[0].Tid: 200, list[1].Tid: 400, list[2].Tid: 450}
+	for _, node := range graph.Nodes {
+		if node.Stats.AvgDuration != avg[node.ID] || len(node.Stats.RecentStatuses) != len(history[node.ID]) {
+			t.Errorf("node %d stats = %+v, want avg %d", node.ID, *node.Stats, avg[node.ID])
+		}
+	}
+	if want := []int{list[0].Tid, list[1].Tid}; !slices.Equal(graph.CriticalPath, want) || graph.CriticalDuration != 600 {
+		t.Errorf("critical path = %v (%d), want %v (600)", graph.CriticalPath, graph.CriticalDuration, want)
+	}
+	if graph.EstimatedDuration != 1050 {
+		t.Errorf("estimated duration = %d, want 1050", graph.EstimatedDuration)
+	}
+}
@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/notify"
+	"clock/internal/repository"
+)
+
+// 报表参数
+const (
+	reportDateLayout = "2006-01-02"
+	reportMaxDays    = 366 // 单个报表最多覆盖的天数
+)
+
+// 报表格式
+const (
+	ReportFormatJSON = "json"
+	ReportFormatText = "text"
+	ReportFormatHTML = "html"
+)
+
+// Report 运行汇总报表，时间范围为 [From, To)
+type Report struct {
+	Title        string             `json:"title"`
+	From         int64              `json:"from"` // 开始时间(秒)
+	To           int64              `json:"to"`   // 结束时间(秒)，不含
+	Runs         int                `json:"runs"`
+	FailedRuns   int                `json:"failed_runs"`
+	TaskRuns     int                `json:"task_runs"`
+	Succeeded    int                `json:"succeeded"`
+	Failed       int                `json:"failed"`
+	Cancelled    int                `json:"cancelled"`
+	SuccessRate  float64            `json:"success_rate"` // 任务执行成功率（百分比）
+	Containers   []*ReportContainer `json:"containers"`
+	Failures     []*ReportFailure   `json:"failures"`      // 最近的失败执行，最多 Top 条
+	FailureTotal int                `json:"failure_total"` // 失败执行总数
+	Slowest      []*ReportSlowTask  `json:"slowest"`       // 平均耗时最长的任务，最多 Top 个
+	SlaMisses    []*ReportSlaMiss   `json:"sla_misses"`    // 最近的 SLA 未达成记录，最多 Top 条
+	SlaMissTotal int                `json:"sla_miss_total"`
+}
+
+// ReportContainer 单个容器的汇总
+type ReportContainer struct {
+	Cid         int     `json:"cid"`
+	Name        string  `json:"name"`
+	Runs        int     `json:"runs"`        // 批次数
+	FailedRuns  int     `json:"failed_runs"` // 包含失败任务的批次数
+	TaskRuns    int     `json:"task_runs"`   // 任务执行次数（含单独运行）
+	Succeeded   int     `json:"succeeded"`
+	Failed      int     `json:"failed"`
+	SuccessRate float64 `json:"success_rate"` // 任务执行成功率（百分比）
+	SlaMisses   int     `json:"sla_misses"`
+}
+
+// ReportFailure 失败的任务执行
+type ReportFailure struct {
+	Tid           int    `json:"tid"`
+	Cid           int    `json:"cid"`
+	TaskName      string `json:"task_name"`
+	ContainerName string `json:"container_name"`
+	RunID         string `json:"run_id"`
+	EndAt         int64  `json:"end_at"` // 结束时间(毫秒)
+	ExitCode      int    `json:"exit_code"`
+	Msg           string `json:"msg"`
+	URL           string `json:"url,omitempty"` // 界面中查看日志的链接（配置 base_url 后生成）
+}
+
+// ReportSlowTask 任务的耗时汇总
+type ReportSlowTask struct {
+	Tid           int    `json:"tid"`
+	Cid           int    `json:"cid"`
+	TaskName      string `json:"task_name"`
+	ContainerName string `json:"container_name"`
+	Runs          int    `json:"runs"`
+	AvgDuration   int64  `json:"avg_duration"` // 平均耗时(毫秒)
+	MaxDuration   int64  `json:"max_duration"` // 最长耗时(毫秒)
+}
+
+// ReportSlaMiss SLA 未达成记录
+type ReportSlaMiss struct {
+	*domain.SlaMiss
+	ContainerName string `json:"container_name"`
+}
+
+// reportService 运行汇总报表服务实现
+type reportService struct {
+	cfg           *config.ReportConfig
+	baseURL       string
+	taskRunRepo   repository.TaskRunRepository
+	slaMissRepo   repository.SlaMissRepository
+	taskRepo      repository.TaskRepository
+	containerRepo repository.ContainerRepository
+	notifyService NotifyService
+
+	cron *cron.Cron
+}
+
+// NewReportService 创建运行汇总报表服务，baseURL 用于生成失败执行的日志链接
+func NewReportService(
+	cfg *config.ReportConfig,
+	baseURL string,
+	taskRunRepo repository.TaskRunRepository,
+	slaMissRepo repository.SlaMissRepository,
+	taskRepo repository.TaskRepository,
+	containerRepo repository.ContainerRepository,
+	notifyService NotifyService,
+) ReportService {
+	return &reportService{
+		cfg:           cfg,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		taskRunRepo:   taskRunRepo,
+		slaMissRepo:   slaMissRepo,
+		taskRepo:      taskRepo,
+		containerRepo: containerRepo,
+		notifyService: notifyService,
+	}
+}
+
+// Start 按配置的调度发送日报和周报，未配置通道时不启动
+func (s *reportService) Start() {
+	if len(s.cfg.Channels) == 0 || s.cron != nil {
+		return
+	}
+	s.cron = cron.New(cron.WithLogger(logger.NewCronLogger()))
+
+	schedules := []struct {
+		name string
+		expr string
+		days int
+	}{
+		{"Daily", s.cfg.Daily, 1},
+		{"Weekly", s.cfg.Weekly, 7},
+	}
+	for _, schedule := range schedules {
+		if schedule.expr == "" {
+			continue
+		}
+		name, days := schedule.name, schedule.days
+		if _, err := s.cron.AddFunc(schedule.expr, func() { s.sendPeriod(name, days) }); err != nil {
+			logger.Errorf("[report] invalid %s schedule %q: %v", strings.ToLower(name), schedule.expr, err)
+		}
+	}
+	s.cron.Start()
+	logger.Infof("[report] report service started, channels: %s", strings.Join(s.cfg.Channels, ", "))
+}
+
+// Stop 停止调度并等待发送中的报表完成
+func (s *reportService) Stop() {
+	if s.cron == nil {
+		return
+	}
+	<-s.cron.Stop().Done()
+	s.cron = nil
+}
+
+// sendPeriod 汇总截至今天零点的若干天并发送，name 为 Daily 或 Weekly
+func (s *reportService) sendPeriod(name string, days int) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from := to.AddDate(0, 0, -days)
+
+	report, err := s.Generate(from, to)
+	if err != nil {
+		logger.Errorf("[report] failed to generate %s report: %v", strings.ToLower(name), err)
+		return
+	}
+	report.Title = reportTitle(name+" run report", from, to)
+	if err := s.Send(context.Background(), report, nil); err != nil {
+		logger.Errorf("[report] failed to send %s report: %v", strings.ToLower(name), err)
+	}
+}
+
+// ParseReportRange 解析报表日期范围（含 to 当天），为空时默认为此前 7 天
+func ParseReportRange(fromText string, toText string) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	to := today
+	if toText != "" {
+		day, err := time.ParseInLocation(reportDateLayout, toText, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, apperrors.InvalidParam("invalid to date, expect " + reportDateLayout)
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -7)
+	if fromText != "" {
+		day, err := time.ParseInLocation(reportDateLayout, fromText, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, apperrors.InvalidParam("invalid from date, expect " + reportDateLayout)
+		}
+		from = day
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, apperrors.InvalidParam("from must not be after to")
+	}
+	if to.Sub(from) > reportMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, apperrors.InvalidParam(fmt.Sprintf("range must not exceed %d days", reportMaxDays))
+	}
+	return from, to, nil
+}
+
+// Generate 汇总 [from, to) 内结束的任务执行和发现的 SLA 未达成记录
+func (s *reportService) Generate(from time.Time, to time.Time) (*Report, error) {
+	runs, err := s.taskRunRepo.ListBetween(from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	misses, err := s.slaMissRepo.ListBetween(from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Title:      reportTitle("Run report", from, to),
+		From:       from.Unix(),
+		To:         to.Unix(),
+		Failures:   []*ReportFailure{},
+		Slowest:    []*ReportSlowTask{},
+		SlaMisses:  []*ReportSlaMiss{},
+		Containers: []*ReportContainer{},
+	}
+	names := newReportNames(s.taskRepo, s.containerRepo)
+
+	containers := make(map[int]*ReportContainer)
+	container := func(cid int) *ReportContainer {
+		c, ok := containers[cid]
+		if !ok {
+			c = &ReportContainer{Cid: cid, Name: names.container(cid)}
+			containers[cid] = c
+		}
+		return c
+	}
+
+	runStatus := make(map[string]bool) // 批次ID -> 是否包含失败任务
+	runCid := make(map[string]int)
+	slow := make(map[int]*ReportSlowTask)
+	var failures []*ReportFailure
+	for _, run := range runs {
+		c := container(run.Cid)
+		c.TaskRuns++
+		report.TaskRuns++
+		switch run.Status {
+		case domain.StatusSuccess:
+			c.Succeeded++
+			report.Succeeded++
+		case domain.StatusFailure:
+			c.Failed++
+			report.Failed++
+			failures = append(failures, &ReportFailure{
+				Tid:           run.Tid,
+				Cid:           run.Cid,
+				TaskName:      names.task(run.Tid),
+				ContainerName: c.Name,
+				RunID:         run.RunID,
+				EndAt:         run.EndAt,
+				ExitCode:      run.ExitCode,
+				Msg:           run.Msg,
+				URL:           s.logURL(run.Cid, run.Tid),
+			})
+		case domain.StatusCancelled:
+			report.Cancelled++
+		}
+
+		if run.RunID != "" {
+			runStatus[run.RunID] = runStatus[run.RunID] || run.Status == domain.StatusFailure
+			runCid[run.RunID] = run.Cid
+		}
+
+		task, ok := slow[run.Tid]
+		if !ok {
+			task = &ReportSlowTask{Tid: run.Tid, Cid: run.Cid, TaskName: names.task(run.Tid), ContainerName: c.Name}
+			slow[run.Tid] = task
+		}
+		task.Runs++
+		task.AvgDuration += run.Duration // 先累计总耗时
+		task.MaxDuration = max(task.MaxDuration, run.Duration)
+	}
+
+	for runID, failed := range runStatus {
+		c := container(runCid[runID])
+		c.Runs++
+		report.Runs++
+		if failed {
+			c.FailedRuns++
+			report.FailedRuns++
+		}
+	}
+
+	for _, miss := range misses {
+		c := container(miss.Cid)
+		c.SlaMisses++
+	}
+	report.SlaMissTotal = len(misses)
+	for i := len(misses) - 1; i >= 0 && len(report.SlaMisses) < s.cfg.Top; i-- {
+		report.SlaMisses = append(report.SlaMisses, &ReportSlaMiss{SlaMiss: misses[i], ContainerName: names.container(misses[i].Cid)})
+	}
+
+	// 失败执行按时间倒序
+	report.FailureTotal = len(failures)
+	for i := len(failures) - 1; i >= 0 && len(report.Failures) < s.cfg.Top; i-- {
+		report.Failures = append(report.Failures, failures[i])
+	}
+
+	for _, task := range slow {
+		task.AvgDuration /= int64(task.Runs)
+		report.Slowest = append(report.Slowest, task)
+	}
+	sort.Slice(report.Slowest, func(i, j int) bool {
+		if report.Slowest[i].AvgDuration != report.Slowest[j].AvgDuration {
+			return report.Slowest[i].AvgDuration > report.Slowest[j].AvgDuration
+		}
+		return report.Slowest[i].Tid < report.Slowest[j].Tid
+	})
+	if len(report.Slowest) > s.cfg.Top {
+		report.Slowest = report.Slowest[:s.cfg.Top]
+	}
+
+	for _, c := range containers {
+		c.SuccessRate = successRate(c.Succeeded, c.TaskRuns)
+		report.Containers = append(report.Containers, c)
+	}
+	sort.Slice(report.Containers, func(i, j int) bool { return report.Containers[i].Cid < report.Containers[j].Cid })
+	report.SuccessRate = successRate(report.Succeeded, report.TaskRuns)
+	return report, nil
+}
+
+// reportTitle 报表标题，to 不含当天
+func reportTitle(name string, from time.Time, to time.Time) string {
+	last := to.AddDate(0, 0, -1)
+	if !last.After(from) {
+		return name + " " + from.Format(reportDateLayout)
+	}
+	return fmt.Sprintf("%s %s ~ %s", name, from.Format(reportDateLayout), last.Format(reportDateLayout))
+}
+
+// successRate 计算成功率（百分比）
+func successRate(succeeded int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(succeeded) * 100 / float64(total)
+}
+
+// logURL 生成查看任务日志的链接
+func (s *reportService) logURL(cid int, tid int) string {
+	if s.baseURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/log/list?cid=%d&tid=%d", s.baseURL, cid, tid)
+}
+
+// Render 将报表渲染为纯文本或 HTML
+func (s *reportService) Render(report *Report, format string) (string, error) {
+	switch format {
+	case ReportFormatText:
+		return renderReport(reportTextTemplate, report)
+	case ReportFormatHTML:
+		return renderReport(reportHTMLTemplate, report)
+	default:
+		return "", apperrors.InvalidParam("invalid report format: " + format)
+	}
+}
+
+// Send 通过通知通道发送报表，channels 为空时使用配置的通道
+func (s *reportService) Send(ctx context.Context, report *Report, channels []string) error {
+	if len(channels) == 0 {
+		channels = s.cfg.Channels
+	}
+	if len(channels) == 0 {
+		return apperrors.InvalidParam("no report channel configured")
+	}
+
+	text, err := s.Render(report, ReportFormatText)
+	if err != nil {
+		return err
+	}
+	html, err := s.Render(report, ReportFormatHTML)
+	if err != nil {
+		return err
+	}
+	msg := &notify.Message{
+		Event: "report",
+		Title: "[clock] " + report.Title,
+		Text:  text,
+		HTML:  html,
+		Time:  time.Now().Unix(),
+	}
+
+	// 逐个通道发送，返回第一个错误
+	var firstErr error
+	for _, channel := range channels {
+		if err := s.notifyService.Send(ctx, channel, msg); err != nil {
+			logger.Errorf("[report] failed to send %q via %s: %v", msg.Title, channel, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// reportNames 报表生成期间缓存的任务和容器名称，已彻底删除时显示ID
+type reportNames struct {
+	taskRepo      repository.TaskRepository
+	containerRepo repository.ContainerRepository
+	tasks         map[int]string
+	containers    map[int]string
+}
+
+func newReportNames(taskRepo repository.TaskRepository, containerRepo repository.ContainerRepository) *reportNames {
+	return &reportNames{
+		taskRepo:      taskRepo,
+		containerRepo: containerRepo,
+		tasks:         make(map[int]string),
+		containers:    make(map[int]string),
+	}
+}
+
+func (n *reportNames) task(tid int) string {
+	name, ok := n.tasks[tid]
+	if !ok {
+		name = fmt.Sprintf("#%d", tid)
+		if task, err := n.taskRepo.GetByIDUnscoped(tid); err == nil {
+			name = task.Name
+		}
+		n.tasks[tid] = name
+	}
+	return name
+}
+
+func (n *reportNames) container(cid int) string {
+	name, ok := n.containers[cid]
+	if !ok {
+		name = fmt.Sprintf("#%d", cid)
+		if cid == 0 {
+			name = "-"
+		} else if container, err := n.containerRepo.GetByIDUnscoped(cid); err == nil {
+			name = container.Name
+		}
+		n.containers[cid] = name
+	}
+	return name
+}
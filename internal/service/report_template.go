@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	texttemplate "text/template"
+	"time"
+)
+
+// reportFuncs 报表模板可用的函数
+var reportFuncs = map[string]any{
+	"date": func(sec int64) string {
+		return time.Unix(sec, 0).Format("2006-01-02 15:04")
+	},
+	"datems": func(ms int64) string {
+		return time.UnixMilli(ms).Format("2006-01-02 15:04")
+	},
+	"duration": func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+	},
+	"pct": func(rate float64) string {
+		return fmt.Sprintf("%.1f%%", rate)
+	},
+}
+
+// reportTextTemplate 纯文本报表
+var reportTextTemplate = texttemplate.Must(texttemplate.New("report").Funcs(reportFuncs).Parse(`{{.Title}}
+Period: {{date .From}} ~ {{date .To}}
+
+Runs: {{.Runs}} ({{.FailedRuns}} with failures)
+Task runs: {{.TaskRuns}} (success {{.Succeeded}}, failure {{.Failed}}, cancelled {{.Cancelled}}), success rate {{pct .SuccessRate}}
+SLA misses: {{.SlaMissTotal}}
+{{if .Containers}}
+Containers:
+{{range .Containers}}  {{.Name}}: {{.Runs}} runs, {{.FailedRuns}} with failures, {{.TaskRuns}} task runs, success rate {{pct .SuccessRate}}{{if .SlaMisses}}, {{.SlaMisses}} SLA misses{{end}}
+{{end}}{{end}}{{if .Failures}}
+Failures ({{len .Failures}} of {{.FailureTotal}}):
+{{range .Failures}}  {{datems .EndAt}} {{.ContainerName}} / {{.TaskName}} (exit {{.ExitCode}}){{if .Msg}}: {{.Msg}}{{end}}
+{{if .URL}}    {{.URL}}
+{{end}}{{end}}{{end}}{{if .Slowest}}
+Slowest tasks:
+{{range .Slowest}}  {{.ContainerName}} / {{.TaskName}}: avg {{duration .AvgDuration}}, max {{duration .MaxDuration}}, {{.Runs}} runs
+{{end}}{{end}}{{if .SlaMisses}}
+SLA misses ({{len .SlaMisses}} of {{.SlaMissTotal}}):
+{{range .SlaMisses}}  {{date .CreateAt}} {{.ContainerName}}: {{.Msg}}
+{{end}}{{end}}`))
+
+// reportHTMLTemplate HTML 报表，使用内联样式以便在邮件客户端中显示
+var reportHTMLTemplate = htmltemplate.Must(htmltemplate.New("report").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family: sans-serif; font-size: 14px; color: #303133;">
+<h2>{{.Title}}</h2>
+<p>Period: {{date .From}} ~ {{date .To}}</p>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr><td>Runs</td><td><b>{{.Runs}}</b> ({{.FailedRuns}} with failures)</td></tr>
+<tr><td>Task runs</td><td><b>{{.TaskRuns}}</b> (success {{.Succeeded}}, failure {{.Failed}}, cancelled {{.Cancelled}})</td></tr>
+<tr><td>Success rate</td><td><b>{{pct .SuccessRate}}</b></td></tr>
+<tr><td>SLA misses</td><td><b>{{.SlaMissTotal}}</b></td></tr>
+</table>
+{{if .Containers}}
+<h3>Containers</h3>
+<table border="1" cellpadding="6" style="border-collapse: collapse;">
+<tr><th>Container</th><th>Runs</th><th>With failures</th><th>Task runs</th><th>Success rate</th><th>SLA misses</th></tr>
+{{range .Containers}}<tr><td>{{.Name}}</td><td>{{.Runs}}</td><td>{{.FailedRuns}}</td><td>{{.TaskRuns}}</td><td>{{pct .SuccessRate}}</td><td>{{.SlaMisses}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Failures}}
+<h3>Failures ({{len .Failures}} of {{.FailureTotal}})</h3>
+<table border="1" cellpadding="6" style="border-collapse: collapse;">
+<tr><th>Time</th><th>Container</th><th>Task</th><th>Exit</th><th>Message</th></tr>
+{{range .Failures}}<tr><td>{{datems .EndAt}}</td><td>{{.ContainerName}}</td><td>{{if .URL}}<a href="{{.URL}}">{{.TaskName}}</a>{{else}}{{.TaskName}}{{end}}</td><td>{{.ExitCode}}</td><td>{{.Msg}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Slowest}}
+<h3>Slowest tasks</h3>
+<table border="1" cellpadding="6" style="border-collapse: collapse;">
+<tr><th>Container</th><th>Task</th><th>Avg</th><th>Max</th><th>Runs</th></tr>
+{{range .Slowest}}<tr><td>{{.ContainerName}}</td><td>{{.TaskName}}</td><td>{{duration .AvgDuration}}</td><td>{{duration .MaxDuration}}</td><td>{{.Runs}}</td></tr>
+{{end}}</table>
+{{end}}{{if .SlaMisses}}
+<h3>SLA misses ({{len .SlaMisses}} of {{.SlaMissTotal}})</h3>
+<table border="1" cellpadding="6" style="border-collapse: collapse;">
+<tr><th>Time</th><th>Container</th><th>Kind</th><th>Message</th></tr>
+{{range .SlaMisses}}<tr><td>{{date .CreateAt}}</td><td>{{.ContainerName}}</td><td>{{.Kind}}</td><td>{{.Msg}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// reportTemplate 纯文本和 HTML 模板的公共接口
+type reportTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// renderReport 执行报表模板
+func renderReport(tmpl reportTemplate, report *Report) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
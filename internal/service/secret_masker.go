@@ -0,0 +1,154 @@
+package service
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"clock/internal/config"
+	"clock/internal/logger"
+)
+
+// maskReplacement 脱敏后的替换文本
+const maskReplacement = "***"
+
+// minSecretValueLen 环境变量值短于该长度时不脱敏，避免误替换常见短字符串
+const minSecretValueLen = 4
+
+// secretGroup 正则中的命名分组，存在时只替换该分组
+const secretGroup = "secret"
+
+// builtinSecretPatterns 常见凭据格式
+var builtinSecretPatterns = []string{
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,                          // AWS Access Key ID
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,                         // GitHub Token
+	`\bgithub_pat_[A-Za-z0-9_]{22,}\b`,                       // GitHub Fine-grained Token
+	`\bxox[abprs]-[A-Za-z0-9-]{10,}`,                         // Slack Token
+	`\bsk-[A-Za-z0-9_-]{20,}`,                                // OpenAI / Stripe 风格密钥
+	`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`, // JWT
+	`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/-]+=*)`,        // Authorization: Bearer
+	`://[^/\s:@]+:(?P<secret>[^/\s@]+)@`,                     // URL 中的密码
+	`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)["']?\s*[=:]\s*["']?(?P<secret>[^\s"'&,;]+)`, // key=value
+}
+
+// 私钥块的起止行，块内的行整体替换
+var (
+	privateKeyBegin = regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`)
+	privateKeyEnd   = regexp.MustCompile(`-----END [A-Z0-9 ]*PRIVATE KEY-----`)
+)
+
+// secretMasker 任务输出脱敏器，替换输出中的敏感信息
+type secretMasker struct {
+	values   *strings.Replacer // 敏感环境变量的值
+	patterns []*regexp.Regexp
+	builtin  bool
+}
+
+// maskState 单个输出流的跨行脱敏状态
+type maskState struct {
+	inPrivateKey bool
+}
+
+// newSecretMasker 根据配置创建脱敏器，无效的正则记录错误后忽略
+func newSecretMasker(cfg *config.MaskingConfig) *secretMasker {
+	m := &secretMasker{builtin: !cfg.DisableBuiltin}
+
+	var values []string
+	for _, env := range os.Environ() {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || len(value) < minSecretValueLen || !matchEnvName(cfg.SecretEnv, name) {
+			continue
+		}
+		values = append(values, value)
+	}
+	if len(values) > 0 {
+		// 较长的值优先匹配，避免只替换其中的一部分
+		sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+		pairs := make([]string, 0, len(values)*2)
+		for _, value := range values {
+			pairs = append(pairs, value, maskReplacement)
+		}
+		m.values = strings.NewReplacer(pairs...)
+	}
+
+	patterns := cfg.Patterns
+	if m.builtin {
+		patterns = append(append([]string{}, builtinSecretPatterns...), patterns...)
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			logger.Errorf("[masking] invalid pattern %q: %v", pattern, err)
+			continue
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m
+}
+
+// matchEnvName 判断环境变量名是否匹配任一模式（支持 * 通配）
+func matchEnvName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask 替换一行输出中的敏感信息
+func (m *secretMasker) Mask(text string, state *maskState) string {
+	if m == nil {
+		return text
+	}
+
+	if m.builtin && state != nil {
+		if state.inPrivateKey {
+			if privateKeyEnd.MatchString(text) {
+				state.inPrivateKey = false
+				return text
+			}
+			return maskReplacement
+		}
+		if privateKeyBegin.MatchString(text) && !privateKeyEnd.MatchString(text) {
+			state.inPrivateKey = true
+			return text
+		}
+	}
+
+	if m.values != nil {
+		text = m.values.Replace(text)
+	}
+	for _, re := range m.patterns {
+		text = maskPattern(re, text)
+	}
+	return text
+}
+
+// maskPattern 替换正则匹配的内容，含 secret 分组时只替换该分组
+func maskPattern(re *regexp.Regexp, text string) string {
+	group := re.SubexpIndex(secretGroup)
+	if group < 0 {
+		return re.ReplaceAllLiteralString(text, maskReplacement)
+	}
+
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range matches {
+		start, end := loc[2*group], loc[2*group+1]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(maskReplacement)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// SLA 检查参数
+const (
+	slaCheckInterval   = 10 * time.Second
+	slaMaxExpectations = 100 // 每个容器最多跟踪的未到期调度，避免高频 cron 无限累积
+	slaScheduleSlack   = time.Second
+)
+
+// slaDeadline 解析后的截止时间定义
+type slaDeadline struct {
+	clock  bool          // true 表示一天中的时刻
+	hour   int           // 时刻（clock 为 true 时有效）
+	minute int           // 分钟（clock 为 true 时有效）
+	offset time.Duration // 相对调度时间的偏移（clock 为 false 时有效）
+}
+
+// parseSlaDeadline 解析 SLA 截止时间："HH:MM" 或正的时长（如 "4h30m"）
+func parseSlaDeadline(text string) (*slaDeadline, error) {
+	if h, m, ok := strings.Cut(text, ":"); ok {
+		hour, err1 := strconv.Atoi(h)
+		minute, err2 := strconv.Atoi(m)
+		if err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+			return nil, fmt.Errorf("invalid sla deadline %q, expect HH:MM", text)
+		}
+		return &slaDeadline{clock: true, hour: hour, minute: minute}, nil
+	}
+	offset, err := time.ParseDuration(text)
+	if err != nil || offset <= 0 {
+		return nil, fmt.Errorf("invalid sla deadline %q, expect HH:MM or a positive duration", text)
+	}
+	return &slaDeadline{offset: offset}, nil
+}
+
+// at 计算调度时间对应的截止时间，时刻早于调度时间时取第二天的该时刻
+func (d *slaDeadline) at(scheduled time.Time) time.Time {
+	if !d.clock {
+		return scheduled.Add(d.offset)
+	}
+	deadline := time.Date(scheduled.Year(), scheduled.Month(), scheduled.Day(), d.hour, d.minute, 0, 0, scheduled.Location())
+	if !deadline.After(scheduled) {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	return deadline
+}
+
+// ValidateSla 校验容器的 SLA 定义
+func ValidateSla(container *domain.Container) error {
+	if container.SlaMaxDuration < 0 {
+		return apperrors.InvalidParam("sla_max_duration must not be negative")
+	}
+	if container.SlaDeadline == "" {
+		return nil
+	}
+	if _, err := parseSlaDeadline(container.SlaDeadline); err != nil {
+		return apperrors.InvalidParam(err.Error())
+	}
+	return nil
+}
+
+// slaRun 运行中的批次
+type slaRun struct {
+	cid     int
+	startAt time.Time
+	missed  bool // 已记录运行时间超出
+}
+
+// slaTask 运行中的任务
+type slaTask struct {
+	runID       string
+	cid         int
+	name        string
+	startAt     time.Time
+	maxDuration time.Duration
+	missed      bool
+}
+
+// slaExpectation 一次调度需在截止时间前成功完成
+type slaExpectation struct {
+	scheduled time.Time
+	deadline  time.Time
+}
+
+// slaRunEnd 批次的结束情况，用于说明截止时间未达成的原因
+type slaRunEnd struct {
+	startAt time.Time
+	status  string
+}
+
+// slaMonitor SLA 监控服务实现
+//
+// 订阅批次和任务的生命周期事件，定期检查：
+//   - 容器配置了截止时间时，每次逻辑调度都需在截止时间前成功完成；
+//   - 容器或任务配置了预期最长运行时间时，运行时间不应超出。
+//
+// 未达成时保存记录，并发布 sla_miss 事件（通知和 webhook 订阅该事件）。
+type slaMonitor struct {
+	repo          repository.SlaMissRepository
+	containerRepo repository.ContainerRepository
+	taskRepo      repository.TaskRepository
+	hub           *StreamHub
+
+	// 以下状态只在监控协程中访问
+	runs         map[string]*slaRun
+	tasks        map[int]*slaTask
+	expectations map[int][]*slaExpectation
+	lastEnd      map[int]*slaRunEnd // 容器最近一次结束的批次
+	lastScan     time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSlaMonitor 创建 SLA 监控服务
+func NewSlaMonitor(
+	repo repository.SlaMissRepository,
+	containerRepo repository.ContainerRepository,
+	taskRepo repository.TaskRepository,
+	hub *StreamHub,
+) SlaMonitor {
+	return &slaMonitor{
+		repo:          repo,
+		containerRepo: containerRepo,
+		taskRepo:      taskRepo,
+		hub:           hub,
+	}
+}
+
+// Start 开始监控，服务启动前的调度不补查
+func (m *slaMonitor) Start() {
+	if m.cancel != nil {
+		return
+	}
+	m.runs = make(map[string]*slaRun)
+	m.tasks = make(map[int]*slaTask)
+	m.expectations = make(map[int][]*slaExpectation)
+	m.lastEnd = make(map[int]*slaRunEnd)
+	m.lastScan = time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	events := m.hub.Subscribe(ctx, slaFilter, 0)
+
+	logger.Infof("[sla] sla monitor started")
+	go m.loop(ctx, events)
+}
+
+// Stop 停止监控
+func (m *slaMonitor) Stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+	m.cancel = nil
+}
+
+// slaFilter SLA 监控订阅的事件
+var slaFilter = &StreamFilter{Kinds: []string{"run_start", "run_end", "task_start", "task_end"}}
+
+// loop 处理事件并定期检查
+func (m *slaMonitor) loop(ctx context.Context, events <-chan StreamEvent) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(slaCheckInterval)
+	defer ticker.Stop()
+
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Warnf("[sla] subscription dropped, resuming from event %d", lastID)
+				events = m.hub.Subscribe(ctx, slaFilter, lastID)
+				continue
+			}
+			lastID = ev.ID
+			m.handle(&ev)
+		case now := <-ticker.C:
+			m.check(now)
+		}
+	}
+}
+
+// handle 跟踪批次和任务的运行状态
+func (m *slaMonitor) handle(ev *StreamEvent) {
+	at := time.UnixMilli(ev.TS)
+	switch ev.Kind {
+	case "run_start":
+		m.runs[ev.RunID] = &slaRun{cid: ev.Cid, startAt: at}
+	case "run_end":
+		run, ok := m.runs[ev.RunID]
+		if !ok {
+			return
+		}
+		delete(m.runs, ev.RunID)
+		m.lastEnd[run.cid] = &slaRunEnd{startAt: run.startAt, status: ev.Status}
+		m.checkRunDuration(ev.RunID, run, at)
+
+		// 成功完成的批次满足其开始之前的调度
+		if ev.Status == domain.StatusText(domain.StatusSuccess) {
+			var rest []*slaExpectation
+			for _, exp := range m.expectations[run.cid] {
+				if exp.scheduled.After(run.startAt.Add(slaScheduleSlack)) {
+					rest = append(rest, exp)
+				}
+			}
+			m.expectations[run.cid] = rest
+		}
+	case "task_start":
+		task, err := m.taskRepo.GetByIDUnscoped(ev.Tid)
+		if err != nil || task.SlaMaxDuration <= 0 {
+			return
+		}
+		m.tasks[ev.Tid] = &slaTask{
+			runID:       ev.RunID,
+			cid:         ev.Cid,
+			name:        ev.TaskName,
+			startAt:     at,
+			maxDuration: time.Duration(task.SlaMaxDuration) * time.Second,
+		}
+	case "task_end":
+		task, ok := m.tasks[ev.Tid]
+		if !ok {
+			return
+		}
+		delete(m.tasks, ev.Tid)
+		m.checkTaskDuration(ev.Tid, task, at)
+	}
+}
+
+// check 检查运行时间和截止时间
+func (m *slaMonitor) check(now time.Time) {
+	for runID, run := range m.runs {
+		m.checkRunDuration(runID, run, now)
+	}
+	for tid, task := range m.tasks {
+		m.checkTaskDuration(tid, task, now)
+	}
+
+	containers, err := m.containerRepo.FindAll()
+	if err != nil {
+		logger.Errorf("[sla] failed to load containers: %v", err)
+		return
+	}
+	for _, container := range containers {
+		m.checkDeadline(container, now)
+	}
+	m.lastScan = now
+}
+
+// checkRunDuration 批次运行时间超过容器预期时记录一次
+func (m *slaMonitor) checkRunDuration(runID string, run *slaRun, now time.Time) {
+	if run.missed {
+		return
+	}
+	container, err := m.containerRepo.GetByIDUnscoped(run.cid)
+	if err != nil || container.SlaMaxDuration <= 0 {
+		return
+	}
+	expected := time.Duration(container.SlaMaxDuration) * time.Second
+	elapsed := now.Sub(run.startAt)
+	if elapsed <= expected {
+		return
+	}
+	run.missed = true
+	m.miss(&domain.SlaMiss{
+		Cid:      run.cid,
+		RunID:    runID,
+		Kind:     domain.SlaKindDuration,
+		Expected: expected.Milliseconds(),
+		Actual:   elapsed.Milliseconds(),
+		Msg:      fmt.Sprintf("run %s of container %s exceeded expected duration %s", runID, container.Name, expected),
+	}, "")
+}
+
+// checkTaskDuration 任务运行时间超过预期时记录一次
+func (m *slaMonitor) checkTaskDuration(tid int, task *slaTask, now time.Time) {
+	elapsed := now.Sub(task.startAt)
+	if task.missed || elapsed <= task.maxDuration {
+		return
+	}
+	task.missed = true
+	m.miss(&domain.SlaMiss{
+		Cid:      task.cid,
+		Tid:      tid,
+		RunID:    task.runID,
+		Kind:     domain.SlaKindDuration,
+		Expected: task.maxDuration.Milliseconds(),
+		Actual:   elapsed.Milliseconds(),
+		Msg:      fmt.Sprintf("task %s exceeded expected duration %s", task.name, task.maxDuration),
+	}, task.name)
+}
+
+// checkDeadline 记录新的调度，并检查已过截止时间仍未成功完成的调度
+func (m *slaMonitor) checkDeadline(container *domain.Container, now time.Time) {
+	if container.Disable || container.SlaDeadline == "" {
+		delete(m.expectations, container.Cid)
+		return
+	}
+	deadline, err := parseSlaDeadline(container.SlaDeadline)
+	if err != nil {
+		return
+	}
+	schedule, err := cron.ParseStandard(container.Expression)
+	if err != nil {
+		return
+	}
+
+	pending := m.expectations[container.Cid]
+	for at := schedule.Next(m.lastScan); !at.IsZero() && !at.After(now); at = schedule.Next(at) {
+		pending = append(pending, &slaExpectation{scheduled: at, deadline: deadline.at(at)})
+	}
+	if len(pending) > slaMaxExpectations {
+		pending = pending[len(pending)-slaMaxExpectations:]
+	}
+
+	var rest []*slaExpectation
+	for _, exp := range pending {
+		if now.Before(exp.deadline) {
+			rest = append(rest, exp)
+			continue
+		}
+		runID, reason := m.deadlineReason(container.Cid, exp.scheduled)
+		m.miss(&domain.SlaMiss{
+			Cid:         container.Cid,
+			RunID:       runID,
+			Kind:        domain.SlaKindDeadline,
+			ScheduledAt: exp.scheduled.Unix(),
+			Deadline:    exp.deadline.Unix(),
+			Actual:      now.Sub(exp.scheduled).Milliseconds(),
+			Msg: fmt.Sprintf("container %s scheduled at %s did not finish by %s: %s", container.Name,
+				exp.scheduled.Format("2006-01-02 15:04"), exp.deadline.Format("2006-01-02 15:04"), reason),
+		}, "")
+	}
+	m.expectations[container.Cid] = rest
+}
+
+// deadlineReason 说明调度未按时完成的原因
+func (m *slaMonitor) deadlineReason(cid int, scheduled time.Time) (string, string) {
+	for runID, run := range m.runs {
+		if run.cid == cid && !run.startAt.Before(scheduled.Add(-slaScheduleSlack)) {
+			return runID, "still running"
+		}
+	}
+	if end, ok := m.lastEnd[cid]; ok && !end.startAt.Before(scheduled.Add(-slaScheduleSlack)) {
+		return "", "finished with status " + end.status
+	}
+	return "", "not started"
+}
+
+// miss 保存记录并发布 sla_miss 事件
+func (m *slaMonitor) miss(miss *domain.SlaMiss, taskName string) {
+	logger.Warnf("[sla] %s", miss.Msg)
+	if err := m.repo.Save(miss); err != nil {
+		logger.Errorf("[sla] failed to save sla miss: %v", err)
+	}
+	m.hub.Publish(StreamEvent{
+		Kind:       "sla_miss",
+		RunID:      miss.RunID,
+		Tid:        miss.Tid,
+		Cid:        miss.Cid,
+		TaskName:   taskName,
+		DurationMs: miss.Actual,
+		Msg:        miss.Msg,
+	})
+}
+
+// Misses 查询 SLA 未达成记录
+func (m *slaMonitor) Misses(query *repository.SlaMissQuery) (*ListResult[*domain.SlaMiss], error) {
+	misses, err := m.repo.List(query)
+	if err != nil {
+		return nil, err
+	}
+	return &ListResult[*domain.SlaMiss]{
+		Items: misses,
+		Page:  &query.Page,
+	}, nil
+}
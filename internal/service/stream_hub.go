@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,52 +15,114 @@ import (
 type StreamEvent struct {
 	ID         int64  `json:"id"`
 	TS         int64  `json:"ts"`
-	Kind       string `json:"kind"` // task_start | task_end | stdout | stderr | meta
+	Kind       string `json:"kind"` // run_start | run_end | task_start | task_end | stdout | stderr | meta | gap
 	RunID      string `json:"runId,omitempty"`
 	Tid        int    `json:"tid,omitempty"`
 	Cid        int    `json:"cid,omitempty"`
 	TaskName   string `json:"taskName,omitempty"`
-	Status     string `json:"status,omitempty"` // only for task_end
+	Status     string `json:"status,omitempty"` // only for task_end / run_end
 	DurationMs int64  `json:"durationMs,omitempty"`
+	ExitCode   *int   `json:"exitCode,omitempty"` // only for task_end, when the process exited
 	Msg        string `json:"msg,omitempty"`
 }
 
+// StreamFilter 订阅过滤条件，零值字段不过滤
+type StreamFilter struct {
+	Cid   int
+	Cids  []int // 只接收这些容器的事件，nil 不限（用于按权限过滤）
+	Tid   int
+	RunID string
+	Kinds []string
+}
+
+// Match 判断事件是否满足过滤条件
+func (f *StreamFilter) Match(ev *StreamEvent) bool {
+	if f == nil {
+		return true
+	}
+	if f.Cid > 0 && ev.Cid != f.Cid {
+		return false
+	}
+	if f.Cids != nil && !slices.Contains(f.Cids, ev.Cid) {
+		return false
+	}
+	if f.Tid > 0 && ev.Tid != f.Tid {
+		return false
+	}
+	if f.RunID != "" && ev.RunID != f.RunID {
+		return false
+	}
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, ev.Kind) {
+		return false
+	}
+	return true
+}
+
 // StreamHub broadcasts StreamEvents to all current subscribers.
 // Each subscriber gets its own buffered channel.
 //
+// Events are filtered per subscriber before they are enqueued, so filtered-out
+// events never count against a subscriber's buffer.
+//
 // Backpressure policy: if a subscriber channel is full, that subscriber is disconnected.
 // This protects the system from slow clients.
+//
+// Recent events are kept in a bounded ring buffer, so a client reconnecting with
+// its last event ID gets the missed events replayed (see Subscribe).
 type StreamHub struct {
 	subBuffer int
 
 	nextID atomic.Int64
 
 	mu   sync.RWMutex
-	subs map[chan StreamEvent]struct{}
+	subs map[chan StreamEvent]*StreamFilter
+
+	// history 最近事件的环形缓冲区，head 为下一个写入位置，size 为已保存的事件数
+	history []StreamEvent
+	head    int
+	size    int
 
 	slowDisconnects atomic.Int64
 }
 
-func NewStreamHub(subBuffer int) *StreamHub {
+// NewStreamHub 创建事件中心，subBuffer 为每个订阅者的缓冲大小，historySize 为保留的最近事件数
+func NewStreamHub(subBuffer int, historySize int) *StreamHub {
 	if subBuffer <= 0 {
 		subBuffer = 1000
 	}
+	if historySize <= 0 {
+		historySize = 10000
+	}
 	return &StreamHub{
 		subBuffer: subBuffer,
-		subs:      make(map[chan StreamEvent]struct{}),
+		subs:      make(map[chan StreamEvent]*StreamFilter),
+		history:   make([]StreamEvent, historySize),
 	}
 }
 
-func (h *StreamHub) Subscribe(ctx context.Context) <-chan StreamEvent {
-	ch := make(chan StreamEvent, h.subBuffer)
-
+// Subscribe registers a subscriber receiving events that match filter (nil for all events).
+// The channel is closed when ctx is done or the subscriber is too slow.
+//
+// When lastID > 0 the buffered events after lastID are replayed first. If some of them
+// have already been evicted from the ring buffer, a "gap" event is sent before the replay.
+func (h *StreamHub) Subscribe(ctx context.Context, filter *StreamFilter, lastID int64) <-chan StreamEvent {
 	h.mu.Lock()
-	h.subs[ch] = struct{}{}
+	var replay []StreamEvent
+	if lastID > 0 {
+		replay = h.replay(filter, lastID)
+	}
+
+	// 回放事件在注册前写入，保证先于之后发布的事件到达且不会重复
+	ch := make(chan StreamEvent, h.subBuffer+len(replay))
+	for _, ev := range replay {
+		ch <- ev
+	}
+	h.subs[ch] = filter
 	h.mu.Unlock()
 
 	go func() {
 		<-ctx.Done()
-		h.removeSub(ch, false)
+		h.removeSub(ch)
 	}()
 
 	return ch
@@ -67,38 +132,99 @@ func (h *StreamHub) Publish(ev StreamEvent) {
 	if ev.TS == 0 {
 		ev.TS = time.Now().UnixMilli()
 	}
+
+	// Record into history and enqueue to subscribers under one lock, so that
+	// every subscriber receives events in ID order and a resuming subscriber
+	// sees each event exactly once. Sends are non-blocking, slow subscribers
+	// are removed before the lock is released.
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	if ev.ID == 0 {
 		ev.ID = h.nextID.Add(1)
 	}
-
-	// Copy subscriber set to avoid holding lock while sending.
-	h.mu.RLock()
-	subs := make([]chan StreamEvent, 0, len(h.subs))
-	for ch := range h.subs {
-		subs = append(subs, ch)
+	h.history[h.head] = ev
+	h.head = (h.head + 1) % len(h.history)
+	if h.size < len(h.history) {
+		h.size++
 	}
-	h.mu.RUnlock()
 
-	for _, ch := range subs {
+	var slow []chan StreamEvent
+	for ch, filter := range h.subs {
+		if !filter.Match(&ev) {
+			continue
+		}
 		select {
 		case ch <- ev:
 		default:
-			h.removeSub(ch, true)
+			slow = append(slow, ch)
 		}
 	}
+	for _, ch := range slow {
+		h.removeSubLocked(ch)
+		h.slowDisconnects.Add(1)
+	}
+}
+
+// History 返回缓冲中 lastID 之后匹配过滤条件的事件，部分事件已淘汰时以 gap 事件开头
+func (h *StreamHub) History(filter *StreamFilter, lastID int64) []StreamEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.replay(filter, lastID)
+}
+
+// replay 返回 lastID 之后缓冲中匹配过滤条件的事件，调用方需持有锁
+func (h *StreamHub) replay(filter *StreamFilter, lastID int64) []StreamEvent {
+	var events []StreamEvent
+
+	oldest := (h.head - h.size + len(h.history)) % len(h.history)
+	at := func(i int) *StreamEvent {
+		return &h.history[(oldest+i)%len(h.history)]
+	}
+	first, latest := h.nextID.Load()+1, h.nextID.Load()
+	if h.size > 0 {
+		first = at(0).ID
+	}
+
+	switch {
+	case lastID > latest:
+		// lastID 来自重启前的服务，事件 ID 已重新计数
+		events = append(events, StreamEvent{
+			ID:   first - 1,
+			TS:   time.Now().UnixMilli(),
+			Kind: "gap",
+			Msg:  fmt.Sprintf("event stream was restarted, events after %d are no longer available", lastID),
+		})
+		lastID = 0
+	case lastID+1 < first:
+		// lastID 之后的部分事件已从缓冲区淘汰
+		events = append(events, StreamEvent{
+			ID:   first - 1,
+			TS:   time.Now().UnixMilli(),
+			Kind: "gap",
+			Msg:  fmt.Sprintf("events %d-%d are no longer available", lastID+1, first-1),
+		})
+	}
+
+	start := sort.Search(h.size, func(i int) bool { return at(i).ID > lastID })
+	for i := start; i < h.size; i++ {
+		if ev := at(i); filter.Match(ev) {
+			events = append(events, *ev)
+		}
+	}
+	return events
 }
 
-func (h *StreamHub) removeSub(ch chan StreamEvent, slow bool) {
+func (h *StreamHub) removeSub(ch chan StreamEvent) {
 	h.mu.Lock()
+	h.removeSubLocked(ch)
+	h.mu.Unlock()
+}
+
+// removeSubLocked 移除并关闭订阅者，调用方需持有锁
+func (h *StreamHub) removeSubLocked(ch chan StreamEvent) {
 	if _, ok := h.subs[ch]; !ok {
-		h.mu.Unlock()
 		return
 	}
 	delete(h.subs, ch)
 	close(ch)
-	h.mu.Unlock()
-
-	if slow {
-		h.slowDisconnects.Add(1)
-	}
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestStreamHubReplay(t *testing.T) {
+	hub := NewStreamHub(10, 5)
+	for i := 1; i <= 8; i++ {
+		kind := "stdout"
+		if i%2 == 0 {
+			kind = "stderr"
+		}
+		hub.Publish(StreamEvent{Kind: kind})
+	}
+
+	tests := []struct {
+		name   string
+		filter *StreamFilter
+		lastID int64
+		want   []int64
+		gap    bool
+	}{
+		{name: "in buffer", lastID: 6, want: []int64{7, 8}},
+		{name: "latest", lastID: 8, want: nil},
+		{name: "oldest boundary", lastID: 3, want: []int64{4, 5, 6, 7, 8}},
+		{name: "evicted", lastID: 2, want: []int64{3, 4, 5, 6, 7, 8}, gap: true},
+		{name: "restarted", lastID: 100, want: []int64{3, 4, 5, 6, 7, 8}, gap: true},
+		{name: "filtered", filter: &StreamFilter{Kinds: []string{"stderr"}}, lastID: 4, want: []int64{6, 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub.mu.Lock()
+			events := hub.replay(tt.filter, tt.lastID)
+			hub.mu.Unlock()
+
+			var ids []int64
+			gap := false
+			for _, ev := range events {
+				if ev.Kind == "gap" {
+					gap = true
+				}
+				ids = append(ids, ev.ID)
+			}
+			if gap != tt.gap {
+				t.Fatalf("gap = %v, want %v", gap, tt.gap)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("ids = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamHubConcurrentResume(t *testing.T) {
+	const (
+		publishers = 8
+		perWorker  = 2000
+		total      = publishers * perWorker
+	)
+	// 多个发布协程真正并行时才会暴露乱序
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+
+	hub := NewStreamHub(16, total)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := hub.Subscribe(ctx, nil, 0)
+	started := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for w := 0; w < publishers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if w == 0 && i == 100 {
+					// 订阅者尚未读取，缓冲已满，保证至少断开一次
+					close(started)
+				}
+				kind := "stdout"
+				if i%2 == 1 {
+					kind = "stderr"
+				}
+				hub.Publish(StreamEvent{Kind: kind, Tid: w})
+			}
+		}(w)
+	}
+
+	<-started
+	var lastID int64
+	seen := 0
+	for seen < total {
+		ev, ok := <-events
+		if !ok {
+			events = hub.Subscribe(ctx, nil, lastID)
+			continue
+		}
+		if ev.Kind == "gap" {
+			t.Fatalf("unexpected gap after %d: %s", lastID, ev.Msg)
+		}
+		if ev.ID != lastID+1 {
+			t.Fatalf("got event %d after %d", ev.ID, lastID)
+		}
+		lastID = ev.ID
+		seen++
+	}
+	wg.Wait()
+
+	if hub.slowDisconnects.Load() == 0 {
+		t.Fatal("subscriber was never dropped")
+	}
+}
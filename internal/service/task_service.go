@@ -1,6 +1,9 @@
 package service
 
 import (
+	"time"
+
+	"clock/internal/config"
 	"clock/internal/domain"
 	apperrors "clock/internal/errors"
 	"clock/internal/repository"
@@ -8,21 +11,30 @@ import (
 
 // taskService 任务服务实现
 type taskService struct {
-	taskRepo     repository.TaskRepository
-	relationRepo repository.RelationRepository
-	executor     *Executor
+	taskRepo      repository.TaskRepository
+	relationRepo  repository.RelationRepository
+	containerRepo repository.ContainerRepository
+	tx            repository.Transactor
+	executor      *Executor
+	deleteCfg     *config.DeleteConfig
 }
 
 // NewTaskService 创建任务服务
 func NewTaskService(
 	taskRepo repository.TaskRepository,
 	relationRepo repository.RelationRepository,
+	containerRepo repository.ContainerRepository,
+	tx repository.Transactor,
 	executor *Executor,
+	deleteCfg *config.DeleteConfig,
 ) TaskService {
 	return &taskService{
-		taskRepo:     taskRepo,
-		relationRepo: relationRepo,
-		executor:     executor,
+		taskRepo:      taskRepo,
+		relationRepo:  relationRepo,
+		containerRepo: containerRepo,
+		tx:            tx,
+		executor:      executor,
+		deleteCfg:     deleteCfg,
 	}
 }
 
@@ -46,27 +58,84 @@ func (s *taskService) List(query *repository.TaskQuery) (*ListResult[*domain.Tas
 
 // Save 保存任务
 func (s *taskService) Save(task *domain.Task) error {
+	if task.SlaMaxDuration < 0 {
+		return apperrors.InvalidParam("sla_max_duration must not be negative")
+	}
 	return s.taskRepo.Save(task)
 }
 
 // Delete 删除任务（同时删除关联关系）
-func (s *taskService) Delete(tid int) error {
-	// 删除任务
-	if err := s.taskRepo.Delete(tid); err != nil {
+func (s *taskService) Delete(tid int, opts *DeleteOptions) error {
+	task, err := s.taskRepo.GetByIDUnscoped(tid)
+	if err != nil {
+		return err
+	}
+
+	purge := opts.Purge || !s.deleteCfg.Trash || task.DeletedAt.Valid
+	cascadeLogs := opts.CascadeLogs || s.deleteCfg.CascadeLogs
+
+	err = s.tx.Transaction(func(repos *repository.Repositories) error {
+		if !purge {
+			at := time.Now().UTC().Truncate(time.Millisecond)
+			if err := repos.Task.SoftDelete(tid, at); err != nil {
+				return err
+			}
+			return repos.Relation.SoftDeleteByTID(tid, at)
+		}
+
+		// 删除任务
+		if err := repos.Task.Delete(tid); err != nil {
+			return err
+		}
+
+		// 删除相关关系（作为前置任务）
+		if err := repos.Relation.DeleteByTID(tid); err != nil {
+			return err
+		}
+
+		// 删除相关关系（作为后续任务）
+		if err := repos.Relation.DeleteByNextTID(tid); err != nil {
+			return err
+		}
+
+		if cascadeLogs {
+			return repos.TaskLog.DeleteByTimeRange(&repository.LogQuery{Tid: tid})
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
-	// 删除相关关系（作为前置任务）
-	if err := s.relationRepo.DeleteByTID(tid); err != nil {
+	// 终止正在运行的任务
+	_ = s.executor.CancelTask(tid)
+	return nil
+}
+
+// Restore 从回收站恢复任务及与其同时删除的关系
+func (s *taskService) Restore(tid int) error {
+	task, err := s.taskRepo.GetByIDUnscoped(tid)
+	if err != nil {
 		return err
 	}
+	if !task.DeletedAt.Valid {
+		return apperrors.InvalidParam("task is not in trash")
+	}
 
-	// 删除相关关系（作为后续任务）
-	if err := s.relationRepo.DeleteByNextTID(tid); err != nil {
+	if _, err := s.containerRepo.GetByID(task.Cid); err != nil {
+		if apperrors.IsNotFound(err) {
+			return apperrors.InvalidParam("container of the task is deleted, restore the container first")
+		}
 		return err
 	}
 
-	return nil
+	at := task.DeletedAt.Time
+	return s.tx.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Task.Restore(tid); err != nil {
+			return err
+		}
+		return repos.Relation.RestoreByTID(tid, at)
+	})
 }
 
 // Run 执行任务
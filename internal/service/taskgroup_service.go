@@ -0,0 +1,138 @@
+package service
+
+import (
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// taskGroupService 任务组服务实现
+type taskGroupService struct {
+	groupRepo repository.TaskGroupRepository
+	taskRepo  repository.TaskRepository
+	tx        repository.Transactor
+	executor  *Executor
+}
+
+// NewTaskGroupService 创建任务组服务
+func NewTaskGroupService(
+	groupRepo repository.TaskGroupRepository,
+	taskRepo repository.TaskRepository,
+	tx repository.Transactor,
+	executor *Executor,
+) TaskGroupService {
+	return &taskGroupService{
+		groupRepo: groupRepo,
+		taskRepo:  taskRepo,
+		tx:        tx,
+		executor:  executor,
+	}
+}
+
+// List 获取容器内的任务组
+func (s *taskGroupService) List(cid int) ([]*domain.TaskGroup, error) {
+	return s.groupRepo.GetByCID(cid)
+}
+
+// Save 保存任务组（校验父任务组属于同一容器且不形成环）
+func (s *taskGroupService) Save(group *domain.TaskGroup) error {
+	if group.ParentGid != 0 {
+		groups, err := s.groupRepo.GetByCID(group.Cid)
+		if err != nil {
+			return err
+		}
+
+		tree := newGroupTree(groups)
+		if _, ok := tree.groups[group.ParentGid]; !ok {
+			return apperrors.InvalidParam("parent group not found in container")
+		}
+		if group.Gid != 0 && tree.contains(group.Gid, group.ParentGid) {
+			return apperrors.InvalidParam("group cannot be nested inside itself")
+		}
+	}
+
+	return s.groupRepo.Save(group)
+}
+
+// Delete 删除任务组，组内任务和子组移动到父任务组，以该组为端点的关系一并删除
+func (s *taskGroupService) Delete(gid int) error {
+	group, err := s.groupRepo.GetByID(gid)
+	if err != nil {
+		return err
+	}
+
+	return s.tx.Transaction(func(repos *repository.Repositories) error {
+		if err := repos.Task.MoveGroup(gid, group.ParentGid); err != nil {
+			return err
+		}
+		if err := repos.TaskGroup.Reparent(gid, group.ParentGid); err != nil {
+			return err
+		}
+		if err := repos.Relation.DeleteByGID(gid); err != nil {
+			return err
+		}
+		return repos.TaskGroup.Delete(gid)
+	})
+}
+
+// Cancel 取消任务组，正在运行和尚未执行的成员都不再执行
+func (s *taskGroupService) Cancel(gid int) error {
+	group, members, err := s.members(gid)
+	if err != nil {
+		return err
+	}
+
+	tids := make([]int, 0, len(members))
+	for _, task := range members {
+		tids = append(tids, task.Tid)
+	}
+	s.executor.CancelGroup(group, tids)
+	logger.Infof("[group] group %d cancelled", gid)
+	return nil
+}
+
+// Retry 重新执行任务组
+func (s *taskGroupService) Retry(gid int) error {
+	group, err := s.groupRepo.GetByID(gid)
+	if err != nil {
+		return err
+	}
+	return s.executor.RunGroup(group)
+}
+
+// Clear 将任务组内所有任务的状态重置为等待中
+func (s *taskGroupService) Clear(gid int) error {
+	_, members, err := s.members(gid)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range members {
+		task.Status = domain.StatusPending
+		if err := s.taskRepo.UpdateStatus(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// members 获取任务组及其（含嵌套子组）内的任务
+func (s *taskGroupService) members(gid int) (*domain.TaskGroup, []*domain.Task, error) {
+	group, err := s.groupRepo.GetByID(gid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groups, err := s.groupRepo.GetByCID(group.Cid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tasks, err := s.taskRepo.GetByCID(group.Cid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return group, newGroupTree(groups).members(gid, tasks), nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	"clock/internal/repository"
+)
+
+func TestTaskGroupCancel(t *testing.T) {
+	tests := []struct {
+		name      string
+		container bool // 在容器批次中执行，否则单独执行任务组
+	}{
+		{name: "group run"},
+		{name: "container run", container: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			containers := repository.NewContainerRepository(db)
+			tasks := repository.NewTaskRepository(db)
+			relations := repository.NewRelationRepository(db)
+			groups := repository.NewTaskGroupRepository(db)
+			hub := NewStreamHub(64, 64)
+			executor := NewExecutor(tasks, relations, repository.NewTaskLogRepository(db, nil), containers,
+				repository.NewTaskRunRepository(db), groups, hub, &config.TaskLogConfig{}, &config.MaskingConfig{})
+			svc := NewTaskGroupService(groups, tasks, repository.NewTransactor(db, nil), executor)
+
+			container := &domain.Container{Name: "c", Expression: "0 0 * * * *"}
+			if err := containers.Save(container); err != nil {
+				t.Fatal(err)
+			}
+			group := &domain.TaskGroup{Cid: container.Cid, Name: "g"}
+			if err := groups.Save(group); err != nil {
+				t.Fatal(err)
+			}
+			// 组内 a 与 x→b→c 并行，b、c 不依赖被取消的 a，组外的 d 依赖任务组
+			list := []*domain.Task{
+				{Cid: container.Cid, Gid: group.Gid, Name: "a", Command: "sleep 10"},
+				{Cid: container.Cid, Gid: group.Gid, Name: "x", Command: "true"},
+				{Cid: container.Cid, Gid: group.Gid, Name: "b", Command: "true"},
+				{Cid: container.Cid, Gid: group.Gid, Name: "c", Command: "true"},
+				{Cid: container.Cid, Name: "d", Command: "true"},
+			}
+			for _, task := range list {
+				if err := tasks.Save(task); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, rel := range []*domain.Relation{
+				{Cid: container.Cid, Tid: list[1].Tid, NextTid: list[2].Tid},
+				{Cid: container.Cid, Tid: list[2].Tid, NextTid: list[3].Tid},
+				{Cid: container.Cid, Gid: group.Gid, NextTid: list[4].Tid},
+			} {
+				if err := relations.Save(rel); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				if tt.container {
+					rels, _ := relations.GetByCID(container.Cid)
+					done <- executor.RunContainer(container, list, rels)
+				} else {
+					done <- svc.Retry(group.Gid)
+				}
+			}()
+
+			deadline := time.Now().Add(5 * time.Second)
+			for !executor.IsTaskRunning(list[0].Tid) {
+				if time.Now().After(deadline) {
+					t.Fatal("first member did not start")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if err := svc.Cancel(group.Gid); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatal(err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("run did not stop after cancelling the group")
+			}
+
+			// x 与 a 在同一阶段，可能先于 a 执行
+			var started []string
+			for _, ev := range hub.History(&StreamFilter{Kinds: []string{"task_start"}}, 0) {
+				started = append(started, ev.TaskName)
+				if ev.TaskName != "a" && ev.TaskName != "x" {
+					t.Errorf("task %s started after the group was cancelled", ev.TaskName)
+				}
+			}
+			if !slices.Contains(started, "a") {
+				t.Errorf("started tasks = %v, want a", started)
+			}
+			if first, _ := tasks.GetByID(list[0].Tid); first.Status != domain.StatusCancelled {
+				t.Errorf("first member status = %d, want cancelled", first.Status)
+			}
+		})
+	}
+}
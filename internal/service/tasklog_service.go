@@ -1,10 +1,26 @@
 package service
 
 import (
+	"io"
+
 	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
 	"clock/internal/repository"
 )
 
+// logMigrateBatchSize 输出迁移每批处理的日志条数
+const logMigrateBatchSize = 100
+
+// logIndexBatchSize 重建全文索引时每批写入的行数
+const logIndexBatchSize = 500
+
+// 日志行查询每页行数
+const (
+	defaultLogLineLimit = 1000
+	maxLogLineLimit     = 10000
+)
+
 // taskLogService 任务日志服务实现
 type taskLogService struct {
 	taskLogRepo repository.TaskLogRepository
@@ -44,3 +60,59 @@ func (s *taskLogService) DeleteByID(lid string) error {
 func (s *taskLogService) DeleteAll() error {
 	return s.taskLogRepo.DeleteAll()
 }
+
+// OpenOutput 流式读取日志输出
+func (s *taskLogService) OpenOutput(lid string, stream string) (io.ReadCloser, error) {
+	return s.taskLogRepo.OpenOutput(lid, stream)
+}
+
+// Lines 查询日志行记录（按时间顺序交错的 stdout/stderr）
+func (s *taskLogService) Lines(lid string, query *repository.LogLineQuery) (*LogLinesResult, error) {
+	switch query.Stream {
+	case "", domain.LogStreamStdout, domain.LogStreamStderr, domain.LogStreamMeta:
+	default:
+		return nil, apperrors.InvalidParam("invalid stream: " + query.Stream)
+	}
+	if query.From < 1 {
+		query.From = 1
+	}
+	if query.To > 0 && query.To < query.From {
+		return nil, apperrors.InvalidParam("to must not be less than from")
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultLogLineLimit
+	}
+	if query.Limit > maxLogLineLimit {
+		query.Limit = maxLogLineLimit
+	}
+
+	lines, hasMore, err := s.taskLogRepo.ReadLines(lid, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LogLinesResult{Items: lines, HasMore: hasMore}
+	if hasMore && len(lines) > 0 {
+		result.Next = lines[len(lines)-1].No + 1
+	}
+	return result, nil
+}
+
+// Migrate 将数据库中的历史输出迁移到当前存储后端
+func (s *taskLogService) Migrate() (int, error) {
+	return s.taskLogRepo.MigrateOutput(logMigrateBatchSize)
+}
+
+// Reindex 为尚未建立全文索引的日志建立索引
+func (s *taskLogService) Reindex() (int, error) {
+	return s.taskLogRepo.Reindex(logIndexBatchSize)
+}
+
+// Recover 结束服务异常退出时仍标记为运行中的日志
+func (s *taskLogService) Recover() (int, error) {
+	recovered, err := s.taskLogRepo.RecoverRunning()
+	if recovered > 0 {
+		logger.Warnf("[tasklog] marked %d logs interrupted by a restart as finished", recovered)
+	}
+	return recovered, err
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// 用户参数
+const (
+	minPasswordLen = 8
+	maxUserNameLen = 64
+	// defaultAdminName 和 defaultAdminPassword 为未配置时的初始账号
+	defaultAdminName     = "admin"
+	defaultAdminPassword = "admin"
+)
+
+// UserRequest 创建用户参数
+type UserRequest struct {
+	Name     string      `json:"name"`
+	Password string      `json:"password"`
+	Role     domain.Role `json:"role"` // 全局角色，为空时不授权，之后按容器授权
+}
+
+// PasswordRequest 修改密码参数，重置他人密码时不需要 OldPassword
+type PasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	Password    string `json:"password"`
+}
+
+// userService 用户服务实现
+type userService struct {
+	cfg         *config.AuthConfig
+	repo        repository.UserRepository
+	bindingRepo repository.RoleBindingRepository
+}
+
+// NewUserService 创建用户服务
+func NewUserService(cfg *config.AuthConfig, repo repository.UserRepository, bindingRepo repository.RoleBindingRepository) UserService {
+	return &userService{
+		cfg:         cfg,
+		repo:        repo,
+		bindingRepo: bindingRepo,
+	}
+}
+
+// Bootstrap 用户表为空时按配置创建初始账号，并在没有任何授权时为已有用户授予全局管理员
+//
+// 配置中的 auth.user/auth.password 只在首次启动时使用。使用默认的
+// admin/admin 时，账号登录后必须先修改密码。从没有角色的版本升级时，
+// 已有用户保持原来的全部权限。
+func (s *userService) Bootstrap() error {
+	count, err := s.repo.Count(false)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := s.createInitialUser(); err != nil {
+			return err
+		}
+	}
+
+	bindings, err := s.bindingRepo.Count()
+	if err != nil || bindings > 0 {
+		return err
+	}
+	users, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := s.bindingRepo.Save(&domain.RoleBinding{UserID: user.ID, Role: domain.RoleAdmin}); err != nil {
+			return err
+		}
+		logger.Infof("[user] granted admin to %s", user.Name)
+	}
+	return nil
+}
+
+// createInitialUser 按配置创建初始账号
+func (s *userService) createInitialUser() error {
+	name, password := s.cfg.User, s.cfg.Password
+	if name == "" {
+		name = defaultAdminName
+	}
+	if password == "" {
+		password = defaultAdminPassword
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user := &domain.User{
+		Name:               name,
+		Password:           hash,
+		MustChangePassword: password == defaultAdminPassword,
+	}
+	if err := s.repo.Save(user); err != nil {
+		return err
+	}
+	logger.Infof("[user] created initial user %s", name)
+	return nil
+}
+
+// Authenticate 校验用户名和密码，成功时记录登录时间
+func (s *userService) Authenticate(name string, password string) (*domain.User, error) {
+	user, err := s.repo.GetByName(name)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			// 用户不存在时同样计算一次哈希，避免通过响应时间判断用户名是否存在
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+			return nil, apperrors.Unauthorized("invalid username or password")
+		}
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, apperrors.Unauthorized("invalid username or password")
+	}
+	if user.Disable {
+		return nil, apperrors.Unauthorized("user is disabled")
+	}
+
+	user.LastLoginAt = time.Now().Unix()
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// dummyPasswordHash 用户不存在时用于比较的哈希
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("clock"), bcrypt.DefaultCost)
+
+// Get 获取用户
+func (s *userService) Get(id int) (*domain.User, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetByName 根据用户名获取用户
+func (s *userService) GetByName(name string) (*domain.User, error) {
+	return s.repo.GetByName(name)
+}
+
+// List 获取全部用户
+func (s *userService) List() ([]*domain.User, error) {
+	return s.repo.List()
+}
+
+// Create 创建用户，新用户登录后必须修改密码
+func (s *userService) Create(req *UserRequest) (*domain.User, error) {
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxUserNameLen {
+		return nil, apperrors.InvalidParam("name is required and must not exceed 64 characters")
+	}
+	if _, err := s.repo.GetByName(req.Name); err == nil {
+		return nil, apperrors.InvalidParam("user already exists: " + req.Name)
+	} else if !apperrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err := validatePassword(req.Password); err != nil {
+		return nil, err
+	}
+	if req.Role != "" && !req.Role.Valid() {
+		return nil, apperrors.InvalidParam("invalid role: " + string(req.Role))
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := &domain.User{
+		Name:               req.Name,
+		Password:           hash,
+		MustChangePassword: true,
+	}
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+	if req.Role != "" {
+		if err := s.bindingRepo.Save(&domain.RoleBinding{UserID: user.ID, Role: req.Role}); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// SetDisable 启用或禁用用户，至少保留一个启用的全局管理员
+func (s *userService) SetDisable(id int, disable bool) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if user.Disable == disable {
+		return nil
+	}
+	if disable {
+		count, err := s.repo.Count(true)
+		if err != nil {
+			return err
+		}
+		if count <= 1 {
+			return apperrors.InvalidParam("cannot disable the last enabled user")
+		}
+		ok, err := hasOtherAdmin(s.repo, s.bindingRepo, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperrors.InvalidParam("cannot disable the last administrator")
+		}
+	}
+	user.Disable = disable
+	return s.repo.Save(user)
+}
+
+// ResetPassword 重置用户密码，用户下次登录后必须修改密码
+func (s *userService) ResetPassword(id int, password string) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+	if user.Password, err = hashPassword(password); err != nil {
+		return err
+	}
+	user.MustChangePassword = true
+	return s.repo.Save(user)
+}
+
+// ChangePassword 修改自己的密码，需要验证原密码
+func (s *userService) ChangePassword(id int, req *PasswordRequest) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)) != nil {
+		return apperrors.InvalidParam("old password is incorrect")
+	}
+	if req.Password == req.OldPassword {
+		return apperrors.InvalidParam("new password must differ from the old one")
+	}
+	if err := validatePassword(req.Password); err != nil {
+		return err
+	}
+	if user.Password, err = hashPassword(req.Password); err != nil {
+		return err
+	}
+	user.MustChangePassword = false
+	return s.repo.Save(user)
+}
+
+// validatePassword 校验密码长度（bcrypt 只使用前 72 字节）
+func validatePassword(password string) error {
+	if len(password) < minPasswordLen || len(password) > 72 {
+		return apperrors.InvalidParam("password must be 8 to 72 bytes")
+	}
+	return nil
+}
+
+// hashPassword 计算 bcrypt 密码哈希
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
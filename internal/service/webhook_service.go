@@ -0,0 +1,558 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+	apperrors "clock/internal/errors"
+	"clock/internal/logger"
+	"clock/internal/repository"
+)
+
+// webhook 投递参数
+const (
+	webhookBatchSize     = 500
+	webhookFlushInterval = time.Second
+	webhookPollInterval  = time.Second
+	webhookCleanInterval = time.Hour
+	webhookErrorLimit    = 512 // 保存的错误信息最大长度
+)
+
+// webhook 请求头
+const (
+	WebhookHeaderEvent     = "X-Clock-Event"
+	WebhookHeaderDelivery  = "X-Clock-Delivery"
+	WebhookHeaderAttempt   = "X-Clock-Attempt"
+	WebhookHeaderSignature = "X-Clock-Signature-256" // sha256=<hex(HMAC-SHA256(secret, body))>
+)
+
+// webhookKinds 可订阅的事件类型，ping 为测试事件，总是投递
+var webhookKinds = []string{"run_start", "run_end", "task_start", "task_end", "meta", "sla_miss", "anomaly", domain.LogStreamStdout, domain.LogStreamStderr}
+
+// webhookDefaultKinds 未指定事件类型时订阅的生命周期事件
+var webhookDefaultKinds = []string{"run_start", "run_end", "task_start", "task_end"}
+
+// webhookService webhook 订阅服务实现
+//
+// 事件流订阅者只把匹配的事件写入投递记录，由独立的投递协程发送，
+// 慢速或不可用的地址只会延迟自身的投递，不会阻塞 StreamHub.Publish 和执行器。
+// 投递记录持久化保存，服务重启后继续重试未完成的投递。
+type webhookService struct {
+	cfg           *config.WebhookConfig
+	repo          repository.WebhookRepository
+	deliveryRepo  repository.WebhookDeliveryRepository
+	containerRepo repository.ContainerRepository
+	hub           *StreamHub
+	client        *http.Client
+
+	mu    sync.RWMutex
+	hooks []*domain.Webhook // 启用的订阅
+
+	inflightMu sync.Mutex
+	inflight   map[int]struct{} // 正在投递的订阅，每个订阅同一时间只投递一条
+	next       int              // 下一轮优先投递的订阅位置，避免总是先投递靠前的订阅
+
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	done    sync.WaitGroup
+	sending sync.WaitGroup
+}
+
+// NewWebhookService 创建 webhook 订阅服务
+func NewWebhookService(
+	cfg *config.WebhookConfig,
+	repo repository.WebhookRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	containerRepo repository.ContainerRepository,
+	hub *StreamHub,
+) WebhookService {
+	return &webhookService{
+		cfg:           cfg,
+		repo:          repo,
+		deliveryRepo:  deliveryRepo,
+		containerRepo: containerRepo,
+		hub:           hub,
+		client:        &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		inflight:      make(map[int]struct{}),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// Start 开始记录事件并投递
+func (s *webhookService) Start() {
+	if s.cancel != nil {
+		return
+	}
+	if err := s.reload(); err != nil {
+		logger.Errorf("[webhook] failed to load webhooks: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	// 先订阅再返回，启动后发生的事件都会被记录
+	events := s.hub.Subscribe(ctx, nil, 0)
+
+	s.done.Add(2)
+	go s.record(ctx, events)
+	go s.dispatch(ctx)
+	logger.Infof("[webhook] webhook service started, workers=%d", s.cfg.Workers)
+}
+
+// Stop 停止服务，等待进行中的投递结束，未完成的投递在下次启动后继续
+func (s *webhookService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.done.Wait()
+	s.sending.Wait()
+	s.cancel = nil
+}
+
+// reload 刷新启用的订阅缓存
+func (s *webhookService) reload() error {
+	webhooks, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+	hooks := make([]*domain.Webhook, 0, len(webhooks))
+	for _, hook := range webhooks {
+		if !hook.Disable {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	s.mu.Lock()
+	s.hooks = hooks
+	s.mu.Unlock()
+	return nil
+}
+
+// notify 唤醒投递协程
+func (s *webhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// record 订阅事件流，为匹配的订阅批量创建投递记录
+func (s *webhookService) record(ctx context.Context, events <-chan StreamEvent) {
+	defer s.done.Done()
+
+	ticker := time.NewTicker(webhookFlushInterval)
+	defer ticker.Stop()
+
+	var pending []*domain.WebhookDelivery
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := s.deliveryRepo.SaveBatch(pending); err != nil {
+			logger.Errorf("[webhook] failed to save %d deliveries: %v", len(pending), err)
+		}
+		pending = pending[:0]
+		s.notify()
+	}
+	defer flush()
+
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return
+				}
+				// 处理过慢被断开订阅，从最后收到的事件处继续
+				flush()
+				logger.Warnf("[webhook] subscription dropped, resuming from event %d", lastID)
+				events = s.hub.Subscribe(ctx, nil, lastID)
+				continue
+			}
+			lastID = ev.ID
+			if ev.Kind == "gap" {
+				logger.Warnf("[webhook] %s", ev.Msg)
+				continue
+			}
+			pending = append(pending, s.match(&ev)...)
+			if len(pending) >= webhookBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// match 为订阅了该事件的 webhook 创建投递记录
+func (s *webhookService) match(ev *StreamEvent) []*domain.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deliveries []*domain.WebhookDelivery
+	var payload string
+	for _, hook := range s.hooks {
+		if hook.Cid > 0 && hook.Cid != ev.Cid {
+			continue
+		}
+		if !slices.Contains(webhookHookKinds(hook), ev.Kind) {
+			continue
+		}
+		if payload == "" {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				logger.Errorf("[webhook] failed to encode event %d: %v", ev.ID, err)
+				return nil
+			}
+			payload = string(data)
+		}
+		deliveries = append(deliveries, newDelivery(hook.ID, ev.ID, ev.Kind, payload))
+	}
+	return deliveries
+}
+
+// webhookHookKinds 返回订阅的事件类型
+func webhookHookKinds(hook *domain.Webhook) []string {
+	kinds := splitList(hook.Kinds)
+	if len(kinds) == 0 {
+		return webhookDefaultKinds
+	}
+	return kinds
+}
+
+// newDelivery 创建待投递的记录
+func newDelivery(webhookID int, eventID int64, kind string, payload string) *domain.WebhookDelivery {
+	now := time.Now().UnixMilli()
+	return &domain.WebhookDelivery{
+		ID:        genGUID(32),
+		WebhookID: webhookID,
+		EventID:   eventID,
+		Kind:      kind,
+		Payload:   payload,
+		Status:    domain.DeliveryPending,
+		NextAt:    now,
+		CreateAt:  now,
+		UpdateAt:  now,
+	}
+}
+
+// dispatch 定期取出到期的投递记录并投递
+func (s *webhookService) dispatch(ctx context.Context) {
+	defer s.done.Done()
+
+	poll := time.NewTicker(webhookPollInterval)
+	defer poll.Stop()
+	clean := time.NewTicker(webhookCleanInterval)
+	defer clean.Stop()
+	s.clean()
+
+	for {
+		if ctx.Err() == nil {
+			s.deliverDue()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-poll.C:
+		case <-clean.C:
+			s.clean()
+		}
+	}
+}
+
+// deliverDue 为空闲的订阅各取出一条到期记录投递
+//
+// 每个订阅同一时间只投递一条，按事件顺序发送，慢速地址只占用一个并发数，
+// 不影响其他订阅。投递结束后唤醒投递协程继续下一条。
+func (s *webhookService) deliverDue() {
+	s.mu.RLock()
+	hooks := slices.Clone(s.hooks)
+	s.mu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	now := time.Now().UnixMilli()
+	start := s.next % len(hooks)
+	s.next++
+	for i := range hooks {
+		if len(s.inflight) >= s.cfg.Workers {
+			return
+		}
+		hook := hooks[(start+i)%len(hooks)]
+		if _, busy := s.inflight[hook.ID]; busy {
+			continue
+		}
+
+		deliveries, err := s.deliveryRepo.FindDue(hook.ID, now, 1)
+		if err != nil {
+			logger.Errorf("[webhook] failed to find due deliveries: %v", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			continue
+		}
+
+		s.inflight[hook.ID] = struct{}{}
+		s.sending.Add(1)
+		go func(delivery *domain.WebhookDelivery) {
+			defer func() {
+				s.inflightMu.Lock()
+				delete(s.inflight, delivery.WebhookID)
+				s.inflightMu.Unlock()
+				s.sending.Done()
+				s.notify()
+			}()
+			s.attempt(delivery)
+		}(deliveries[0])
+	}
+}
+
+// attempt 投递一次并更新记录，失败时按指数退避安排重试
+func (s *webhookService) attempt(delivery *domain.WebhookDelivery) {
+	hook, err := s.repo.GetByID(delivery.WebhookID)
+	if err != nil {
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = "webhook not found"
+		s.saveDelivery(delivery)
+		return
+	}
+	if hook.Disable {
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = "webhook disabled"
+		s.saveDelivery(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	start := time.Now()
+	code, err := s.post(hook, delivery)
+	delivery.Duration = time.Since(start).Milliseconds()
+	delivery.LastCode = code
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.DeliverySuccess
+		delivery.LastError = ""
+	case delivery.Attempts >= s.cfg.MaxAttempts:
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = truncate(err.Error(), webhookErrorLimit)
+		logger.Warnf("[webhook] delivery %s to %s failed after %d attempts: %v", delivery.ID, hook.URL, delivery.Attempts, err)
+	default:
+		delivery.LastError = truncate(err.Error(), webhookErrorLimit)
+		delivery.NextAt = time.Now().Add(s.backoff(delivery.Attempts)).UnixMilli()
+	}
+	s.saveDelivery(delivery)
+}
+
+// post 发送请求，返回响应状态码
+func (s *webhookService) post(hook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "clock-webhook")
+	req.Header.Set(WebhookHeaderEvent, delivery.Kind)
+	req.Header.Set(WebhookHeaderDelivery, delivery.ID)
+	req.Header.Set(WebhookHeaderAttempt, strconv.Itoa(delivery.Attempts))
+	if hook.Secret != "" {
+		req.Header.Set(WebhookHeaderSignature, SignWebhook(hook.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorLimit))
+		return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+// SignWebhook 计算请求体签名，接收方使用相同密钥计算并比较 X-Clock-Signature-256
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff 第 n 次失败后的重试间隔
+func (s *webhookService) backoff(attempts int) time.Duration {
+	wait := time.Duration(s.cfg.Backoff) * time.Second
+	limit := time.Duration(s.cfg.MaxBackoff) * time.Second
+	for i := 1; i < attempts && wait < limit; i++ {
+		wait *= 2
+	}
+	return min(wait, limit)
+}
+
+// saveDelivery 保存投递结果
+func (s *webhookService) saveDelivery(delivery *domain.WebhookDelivery) {
+	if err := s.deliveryRepo.Save(delivery); err != nil {
+		logger.Errorf("[webhook] failed to save delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// truncate 截断过长的文本
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit]
+}
+
+// clean 删除超过保留天数的投递记录
+func (s *webhookService) clean() {
+	if s.cfg.MaxAge <= 0 {
+		return
+	}
+	before := time.Now().AddDate(0, 0, -s.cfg.MaxAge).UnixMilli()
+	deleted, err := s.deliveryRepo.DeleteBefore(before)
+	if err != nil {
+		logger.Errorf("[webhook] failed to clean deliveries: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logger.Infof("[webhook] cleaned %d deliveries older than %d days", deleted, s.cfg.MaxAge)
+	}
+}
+
+// List 获取全部订阅，不返回签名密钥
+func (s *webhookService) List() ([]*domain.Webhook, error) {
+	webhooks, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range webhooks {
+		hook.Secret = ""
+	}
+	return webhooks, nil
+}
+
+// Save 校验并保存订阅，更新时密钥为空则保留原密钥
+func (s *webhookService) Save(hook *domain.Webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return apperrors.InvalidParam("invalid url: " + hook.URL)
+	}
+	kinds := splitList(hook.Kinds)
+	for _, kind := range kinds {
+		if !slices.Contains(webhookKinds, kind) {
+			return apperrors.InvalidParam("invalid kind: " + kind)
+		}
+	}
+	hook.Kinds = strings.Join(kinds, ",")
+	if hook.Cid > 0 {
+		if _, err := s.containerRepo.GetByID(hook.Cid); err != nil {
+			return err
+		}
+	}
+
+	if hook.ID > 0 {
+		old, err := s.repo.GetByID(hook.ID)
+		if err != nil {
+			return err
+		}
+		if hook.Secret == "" {
+			hook.Secret = old.Secret
+		}
+	}
+	if err := s.repo.Save(hook); err != nil {
+		return err
+	}
+	hook.Secret = ""
+	return s.reload()
+}
+
+// Delete 删除订阅及其投递记录
+func (s *webhookService) Delete(id int) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if err := s.deliveryRepo.DeleteByWebhookID(id); err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// Deliveries 查询投递记录
+func (s *webhookService) Deliveries(query *repository.DeliveryQuery) (*ListResult[*domain.WebhookDelivery], error) {
+	deliveries, err := s.deliveryRepo.List(query)
+	if err != nil {
+		return nil, err
+	}
+	return &ListResult[*domain.WebhookDelivery]{
+		Items: deliveries,
+		Page:  &query.Page,
+	}, nil
+}
+
+// GetDelivery 获取投递记录详情（含请求体）
+func (s *webhookService) GetDelivery(id string) (*domain.WebhookDelivery, error) {
+	return s.deliveryRepo.GetByID(id)
+}
+
+// Redeliver 使用原请求体创建一次新的投递
+func (s *webhookService) Redeliver(id string) (*domain.WebhookDelivery, error) {
+	old, err := s.deliveryRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetByID(old.WebhookID); err != nil {
+		return nil, err
+	}
+	return s.enqueue(newDelivery(old.WebhookID, old.EventID, old.Kind, old.Payload))
+}
+
+// Ping 向订阅投递一个测试事件
+func (s *webhookService) Ping(id int) (*domain.WebhookDelivery, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(StreamEvent{TS: time.Now().UnixMilli(), Kind: "ping", Msg: "ping"})
+	if err != nil {
+		return nil, err
+	}
+	return s.enqueue(newDelivery(id, 0, "ping", string(data)))
+}
+
+// enqueue 保存投递记录并唤醒投递协程
+func (s *webhookService) enqueue(delivery *domain.WebhookDelivery) (*domain.WebhookDelivery, error) {
+	if err := s.deliveryRepo.SaveBatch([]*domain.WebhookDelivery{delivery}); err != nil {
+		return nil, err
+	}
+	s.notify()
+	return delivery, nil
+}
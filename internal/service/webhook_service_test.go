@@ -0,0 +1,100 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"clock/internal/config"
+	"clock/internal/domain"
+)
+
+func TestSignWebhook(t *testing.T) {
+	tests := []struct {
+		secret string
+		body   string
+		want   string
+	}{
+		{secret: "It's a Secret to Everybody", body: "Hello, World!", want: "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"},
+		{secret: "key", body: "", want: "sha256=5d5d139563c95b5967b9bd9a8c9b233a9dedb45072794cd232dc1b74832607d0"},
+	}
+	for _, tt := range tests {
+		if got := SignWebhook(tt.secret, []byte(tt.body)); got != tt.want {
+			t.Errorf("SignWebhook(%q, %q) = %s, want %s", tt.secret, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  int
+		max      int
+		attempts int
+		want     time.Duration
+	}{
+		{name: "first retry", backoff: 10, max: 3600, attempts: 1, want: 10 * time.Second},
+		{name: "doubles", backoff: 10, max: 3600, attempts: 2, want: 20 * time.Second},
+		{name: "doubles again", backoff: 10, max: 3600, attempts: 4, want: 80 * time.Second},
+		{name: "capped", backoff: 10, max: 60, attempts: 4, want: 60 * time.Second},
+		{name: "many attempts stay capped", backoff: 10, max: 3600, attempts: 100, want: time.Hour},
+		{name: "backoff above max", backoff: 120, max: 60, attempts: 1, want: 60 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &webhookService{cfg: &config.WebhookConfig{Backoff: tt.backoff, MaxBackoff: tt.max}}
+			if got := s.backoff(tt.attempts); got != tt.want {
+				t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookPost(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		status   int
+		wantSign bool
+		wantErr  bool
+	}{
+		{name: "signed", secret: "s3cret", status: http.StatusOK, wantSign: true},
+		{name: "unsigned", status: http.StatusNoContent},
+		{name: "server error", secret: "s3cret", status: http.StatusInternalServerError, wantSign: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			s := NewWebhookService(&config.WebhookConfig{Timeout: 5}, nil, nil, nil, nil).(*webhookService)
+			delivery := &domain.WebhookDelivery{ID: "d1", Kind: "run_end", Payload: `{"kind":"run_end"}`, Attempts: 2}
+			code, err := s.post(&domain.Webhook{URL: server.URL, Secret: tt.secret}, delivery)
+			if code != tt.status || (err != nil) != tt.wantErr {
+				t.Fatalf("post() = %d, %v, want %d", code, err, tt.status)
+			}
+
+			if string(body) != delivery.Payload {
+				t.Errorf("body = %s, want %s", body, delivery.Payload)
+			}
+			if header.Get(WebhookHeaderEvent) != "run_end" || header.Get(WebhookHeaderDelivery) != "d1" || header.Get(WebhookHeaderAttempt) != "2" {
+				t.Errorf("headers = %v", header)
+			}
+			signature := header.Get(WebhookHeaderSignature)
+			if tt.wantSign && signature != SignWebhook(tt.secret, body) {
+				t.Errorf("signature = %q, want %q", signature, SignWebhook(tt.secret, body))
+			}
+			if !tt.wantSign && signature != "" {
+				t.Errorf("unexpected signature %q", signature)
+			}
+		})
+	}
+}
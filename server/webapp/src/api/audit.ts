@@ -0,0 +1,15 @@
+import { get, ApiResponse } from '.'
+import type { AuditLog, ListResponse } from '@/types/model'
+
+export function getAudits(params: {
+  actor?: string
+  action?: string
+  target_type?: string
+  target_id?: string
+  left_ts?: number
+  right_ts?: number
+  count?: number
+  index?: number
+}): Promise<ApiResponse<ListResponse<AuditLog>>> {
+  return get('/audit', params)
+}
@@ -1,5 +1,6 @@
 import axios, { AxiosInstance, AxiosRequestConfig } from 'axios'
 import { ElMessage } from 'element-plus'
+import { useUserStore } from '@/stores/user'
 
 // 统一使用 /v1 前缀
 const BASE_URL = '/v1'
@@ -35,7 +36,12 @@ service.interceptors.response.use(
     return Promise.reject(new Error(res.msg || '请求失败'))
   },
   (error) => {
-    ElMessage.error(error.message || '网络错误')
+    const res = error.response?.data
+    // 1007: 需要先修改密码
+    if (res?.code === 1007) {
+      useUserStore().setMustChangePassword(true)
+    }
+    ElMessage.error(res?.msg || error.message || '网络错误')
     return Promise.reject(error)
   }
 )
@@ -1,7 +1,7 @@
-import { get, del, ApiResponse } from '.'
-import type { TaskLog, ListResponse } from '@/types/model'
+import { get, post, del, ApiResponse } from '.'
+import type { TaskLog, LogLinesResponse, ListResponse } from '@/types/model'
 
-export function getLogs(params?: { count?: number; index?: number; left_ts?: number; right_ts?: number; tid?: number; cid?: number }): Promise<ApiResponse<ListResponse<TaskLog>>> {
+export function getLogs(params?: { count?: number; index?: number; left_ts?: number; right_ts?: number; tid?: number; cid?: number; search?: string }): Promise<ApiResponse<ListResponse<TaskLog>>> {
   return get('/log', params)
 }
 
@@ -16,3 +16,40 @@ export function deleteLogByID(lid: string): Promise<ApiResponse> {
 export function deleteAllLogs(): Promise<ApiResponse> {
   return del('/log/all')
 }
+
+// 日志输出为纯文本流，不经过 axios 的 JSON 响应拦截器
+export async function getLogOutput(lid: string, stream: 'stdout' | 'stderr'): Promise<string> {
+  const token = localStorage.getItem('token')
+  const res = await fetch(`/v1/log/${lid}/output?stream=${stream}`, {
+    headers: token ? { token } : {}
+  })
+  if (!res.ok) {
+    throw new Error(`读取日志输出失败: ${res.status}`)
+  }
+  return res.text()
+}
+
+// 下载日志纯文本输出（stream 为 all 时按时间顺序合并），gzip 为 true 时下载压缩文件
+export async function downloadLog(lid: string, stream: 'all' | 'stdout' | 'stderr', gzip = false): Promise<void> {
+  const token = localStorage.getItem('token')
+  const res = await fetch(`/v1/log/${lid}/download?stream=${stream}&gzip=${gzip}`, {
+    headers: token ? { token } : {}
+  })
+  if (!res.ok) {
+    throw new Error(`下载日志失败: ${res.status}`)
+  }
+  const url = URL.createObjectURL(await res.blob())
+  const link = document.createElement('a')
+  link.href = url
+  link.download = `${lid}.${stream}.log${gzip ? '.gz' : ''}`
+  link.click()
+  URL.revokeObjectURL(url)
+}
+
+export function getLogLines(lid: string, params?: { stream?: string; from?: number; to?: number; limit?: number }): Promise<ApiResponse<LogLinesResponse>> {
+  return get(`/log/${lid}/lines`, params)
+}
+
+export function migrateLogs(): Promise<ApiResponse<{ migrated: number }>> {
+  return post('/log/migrate')
+}
@@ -0,0 +1,32 @@
+import { get, put, post, del, ApiResponse } from '.'
+import type { NotifyChannel, NotifyRule, NotifyPreview } from '@/types/model'
+
+export function getNotifyChannels(): Promise<ApiResponse<NotifyChannel[]>> {
+  return get('/notify/channel')
+}
+
+export function getNotifyRules(params: { cid?: number; tid?: number }): Promise<ApiResponse<NotifyRule[]>> {
+  return get('/notify/rule', params)
+}
+
+export function putNotifyRule(data: NotifyRule): Promise<ApiResponse<number>> {
+  return put('/notify/rule', data)
+}
+
+export function deleteNotifyRule(id: number): Promise<ApiResponse> {
+  return del(`/notify/rule/${id}`)
+}
+
+export function testNotifyChannel(channel: string): Promise<ApiResponse> {
+  return post(`/notify/test?channel=${encodeURIComponent(channel)}`)
+}
+
+export function previewNotifyTemplate(data: {
+  template?: string
+  channel?: string
+  rule_id?: number
+  run_id?: string
+  tid?: number
+}): Promise<ApiResponse<NotifyPreview>> {
+  return post('/notify/preview', data)
+}
@@ -0,0 +1,11 @@
+import { get, ApiResponse } from '.'
+import type { SlaMiss, ListResponse } from '@/types/model'
+
+export function getSlaMisses(params: {
+  cid?: number
+  tid?: number
+  count?: number
+  index?: number
+}): Promise<ApiResponse<ListResponse<SlaMiss>>> {
+  return get('/sla/miss', params)
+}
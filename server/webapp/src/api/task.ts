@@ -1,5 +1,5 @@
 import { get, put, del, post, ApiResponse } from '.'
-import type { Task, ListResponse } from '@/types/model'
+import type { Task, DurationStats, ListResponse } from '@/types/model'
 
 // 运行中任务信息
 export interface RunningTaskInfo {
@@ -18,6 +18,10 @@ export function getTask(tid: number): Promise<ApiResponse<Task>> {
   return get(`/task/${tid}`)
 }
 
+export function getDurationStats(tid: number): Promise<ApiResponse<DurationStats>> {
+  return get(`/task/${tid}/duration`)
+}
+
 export function putTask(data: Partial<Task> & { tid?: number }): Promise<ApiResponse<{ tid: number }>> {
   return put('/task', data)
 }
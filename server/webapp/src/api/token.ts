@@ -0,0 +1,14 @@
+import { get, put, del, ApiResponse } from '.'
+import type { APIToken, APITokenCreated } from '@/types/model'
+
+export function getTokens(params?: { user_id?: number }): Promise<ApiResponse<APIToken[]>> {
+  return get('/token', params)
+}
+
+export function createToken(data: { name: string; scopes: string; expire_at: number }): Promise<ApiResponse<APITokenCreated>> {
+  return put('/token', data)
+}
+
+export function deleteToken(id: number): Promise<ApiResponse> {
+  return del(`/token/${id}`)
+}
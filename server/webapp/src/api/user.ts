@@ -0,0 +1,42 @@
+import { get, put, post, del, ApiResponse } from '.'
+import type { Me, Role, RoleBinding, User } from '@/types/model'
+
+export function getUsers(): Promise<ApiResponse<User[]>> {
+  return get('/user')
+}
+
+export function createUser(data: { name: string; password: string; role?: Role | '' }): Promise<ApiResponse<User>> {
+  return put('/user', data)
+}
+
+export function disableUser(id: number): Promise<ApiResponse> {
+  return post(`/user/${id}/disable`)
+}
+
+export function enableUser(id: number): Promise<ApiResponse> {
+  return post(`/user/${id}/enable`)
+}
+
+export function resetPassword(id: number, password: string): Promise<ApiResponse> {
+  return post(`/user/${id}/password`, { password })
+}
+
+export function getMe(): Promise<ApiResponse<Me>> {
+  return get('/user/me')
+}
+
+export function changePassword(data: { old_password: string; password: string }): Promise<ApiResponse> {
+  return put('/user/me/password', data)
+}
+
+export function getRoles(params: { user_id?: number; cid?: number }): Promise<ApiResponse<RoleBinding[]>> {
+  return get('/role', params)
+}
+
+export function putRole(data: RoleBinding): Promise<ApiResponse<RoleBinding>> {
+  return put('/role', data)
+}
+
+export function deleteRole(id: number): Promise<ApiResponse> {
+  return del(`/role/${id}`)
+}
@@ -0,0 +1,35 @@
+import { get, put, post, del, ApiResponse } from '.'
+import type { Webhook, WebhookDelivery, ListResponse } from '@/types/model'
+
+export function getWebhooks(): Promise<ApiResponse<Webhook[]>> {
+  return get('/webhook')
+}
+
+export function putWebhook(data: Webhook): Promise<ApiResponse<number>> {
+  return put('/webhook', data)
+}
+
+export function deleteWebhook(id: number): Promise<ApiResponse> {
+  return del(`/webhook/${id}`)
+}
+
+export function pingWebhook(id: number): Promise<ApiResponse<WebhookDelivery>> {
+  return post(`/webhook/${id}/ping`)
+}
+
+export function getDeliveries(params: {
+  webhook_id?: number
+  status?: string
+  count?: number
+  index?: number
+}): Promise<ApiResponse<ListResponse<WebhookDelivery>>> {
+  return get('/webhook/delivery', params)
+}
+
+export function getDelivery(id: string): Promise<ApiResponse<WebhookDelivery>> {
+  return get(`/webhook/delivery/${id}`)
+}
+
+export function redeliver(id: string): Promise<ApiResponse<WebhookDelivery>> {
+  return post(`/webhook/delivery/${id}/redeliver`)
+}
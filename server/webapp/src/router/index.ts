@@ -48,6 +48,30 @@ const routes: Array<RouteRecordRaw> = [
         name: 'LogList',
         component: () => import('@/views/Log/List.vue'),
         meta: { title: '日志中心' }
+      },
+      {
+        path: 'webhook/list',
+        name: 'WebhookList',
+        component: () => import('@/views/Webhook/List.vue'),
+        meta: { title: 'Webhook' }
+      },
+      {
+        path: 'user/list',
+        name: 'UserList',
+        component: () => import('@/views/User/List.vue'),
+        meta: { title: '用户管理' }
+      },
+      {
+        path: 'token/list',
+        name: 'TokenList',
+        component: () => import('@/views/Token/List.vue'),
+        meta: { title: 'API 令牌' }
+      },
+      {
+        path: 'audit/list',
+        name: 'AuditList',
+        component: () => import('@/views/Audit/List.vue'),
+        meta: { title: '审计日志' }
       }
     ]
   },
@@ -2,14 +2,36 @@ import { defineStore } from 'pinia'
 import { ref, computed } from 'vue'
 import { useRouter } from 'vue-router'
 import { login } from '@/api/auth'
+import { getMe } from '@/api/user'
+import type { Permissions, Role } from '@/types/model'
+
+// 角色级别，高级角色包含低级角色的权限
+const roleLevels: Record<string, number> = { viewer: 1, operator: 2, editor: 3, admin: 4 }
 
 export const useUserStore = defineStore('user', () => {
   const router = useRouter()
 
   const token = ref(localStorage.getItem('token') || '')
   const userName = ref(localStorage.getItem('userName') || '')
+  const mustChangePassword = ref(localStorage.getItem('mustChangePassword') === 'true')
+
+  const permissions = ref<Permissions>({ global: '', containers: {} })
 
   const isLoggedIn = computed(() => !!token.value)
+  const isAdmin = computed(() => permissions.value.global === 'admin')
+
+  // 判断在容器中是否具有角色，cid 为 0 时只看全局角色
+  function can(cid: number, role: Role) {
+    const global = roleLevels[permissions.value.global] || 0
+    const container = cid ? roleLevels[permissions.value.containers[cid]] || 0 : 0
+    return Math.max(global, container) >= roleLevels[role]
+  }
+
+  async function fetchPermissions() {
+    const res = await getMe()
+    permissions.value = res.data.permissions || { global: '', containers: {} }
+    setMustChangePassword(!!res.data.must_change_password)
+  }
 
   function setToken(t: string) {
     token.value = t
@@ -21,12 +43,22 @@ export const useUserStore = defineStore('user', () => {
     localStorage.setItem('userName', name)
   }
 
+  function setMustChangePassword(value: boolean) {
+    mustChangePassword.value = value
+    if (value) {
+      localStorage.setItem('mustChangePassword', 'true')
+    } else {
+      localStorage.removeItem('mustChangePassword')
+    }
+  }
+
   async function handleLogin(userNameVal: string, userPwd: string) {
     try {
       const res = await login({ user: userNameVal, pwd: userPwd })
       if (res.data && typeof res.data === 'object' && res.data.token) {
         setToken(res.data.token)
-        setUserName(userNameVal)
+        setUserName(res.data.name || userNameVal)
+        setMustChangePassword(!!res.data.must_change_password)
         return true
       }
       return false
@@ -40,6 +72,8 @@ export const useUserStore = defineStore('user', () => {
     userName.value = ''
     localStorage.removeItem('token')
     localStorage.removeItem('userName')
+    setMustChangePassword(false)
+    permissions.value = { global: '', containers: {} }
     router.push('/login')
   }
 
@@ -47,8 +81,14 @@ export const useUserStore = defineStore('user', () => {
     token,
     userName,
     isLoggedIn,
+    mustChangePassword,
+    permissions,
+    isAdmin,
+    can,
+    fetchPermissions,
     setToken,
     setUserName,
+    setMustChangePassword,
     handleLogin,
     handleLogOut
   }
@@ -17,6 +17,12 @@ export interface Container {
   status: number
   disable: boolean
   blocking: boolean  // 阻塞模式（上次未完成则跳过）
+  log_max_age: number          // 日志保留天数，0 继承全局配置，-1 不限
+  log_max_count: number        // 每个任务的日志保留条数，0 继承全局配置，-1 不限
+  log_failure_max_age: number  // 失败日志保留天数，0 继承全局配置，-1 不限
+  log_max_size: number         // 容器内所有日志的输出总大小上限（MB），0 不限
+  sla_deadline: string         // SLA 截止时间："06:00" 或相对调度时间的偏移 "4h30m"，为空不检查
+  sla_max_duration: number     // SLA 预期最长运行时间（秒），0 不检查
   update_at: number
 }
 
@@ -32,10 +38,31 @@ export interface Task {
   timeout: number
   update_at: number
   log_enable: boolean
+  max_output: number
+  log_max_age: number          // 日志保留天数，0 继承容器/全局配置，-1 不限
+  log_max_count: number        // 日志保留条数，0 继承容器/全局配置，-1 不限
+  log_failure_max_age: number  // 失败日志保留天数，0 继承容器/全局配置，-1 不限
+  log_max_size: number         // 该任务日志的输出总大小上限（MB），0 不限
+  sla_max_duration: number     // SLA 预期最长运行时间（秒），0 不检查，超出只告警
   point_x: number
   point_y: number
 }
 
+// 任务历史耗时统计（毫秒）
+export interface DurationStats {
+  tid: number
+  runs: number
+  mean: number
+  stddev: number
+  p50: number
+  p95: number
+  p99: number
+  max: number
+  threshold: number          // 异常阈值，样本不足时为 0
+  timeout: number            // 当前超时时间（秒）
+  suggested_timeout: number  // 建议超时时间（秒），样本不足时为 0
+}
+
 // 关系
 export interface Relation {
   rid: number
@@ -72,13 +99,111 @@ export interface Link {
 // 任务日志
 export interface TaskLog {
   lid: string
+  run_id: string
+  tid: number
+  cid: number
+  storage: string
+  out_size: number
+  err_size: number
+  running: boolean
+  truncated: boolean
+  indexed: boolean
+  matches?: LogMatch[]
+  std_out?: string
+  std_err?: string
+  update_at: number
+}
+
+// 日志搜索命中的行，snippet 中命中内容以 <mark></mark> 标记
+export interface LogMatch {
+  no: number
+  snippet: string
+}
+
+// 日志行记录
+export interface LogLine {
+  no: number
+  ts: number
+  offset: number
+  stream: 'stdout' | 'stderr' | 'meta'
+  text: string
+}
+
+export interface LogLinesResponse {
+  items: LogLine[]
+  has_more: boolean
+  next: number
+}
+
+// 通知通道
+export interface NotifyChannel {
+  name: string
+  type: string
+}
+
+// 通知规则（tid 为 0 时为容器规则）
+export interface NotifyRule {
+  id?: number
+  cid: number
   tid: number
+  scope: string
+  channel: string
+  statuses: string
+  template: string
+  disable: boolean
+  update_at?: number
+}
+
+// 通知模板预览
+export interface NotifyPreview {
+  title: string
+  text: string
+}
+
+// webhook 订阅
+export interface Webhook {
+  id?: number
+  name: string
+  url: string
+  secret?: string
+  kinds: string
   cid: number
-  std_out: string
-  std_err: string
+  disable: boolean
+  update_at?: number
+}
+
+// webhook 投递记录
+export interface WebhookDelivery {
+  id: string
+  webhook_id: number
+  event_id: number
+  kind: string
+  payload?: string
+  status: 'pending' | 'success' | 'failed'
+  attempts: number
+  next_at: number
+  last_code: number
+  last_error: string
+  duration: number
+  create_at: number
   update_at: number
 }
 
+// SLA 未达成记录
+export interface SlaMiss {
+  id: number
+  cid: number
+  tid: number
+  run_id: string
+  kind: 'deadline' | 'duration'
+  scheduled_at: number
+  deadline: number
+  expected: number
+  actual: number
+  msg: string
+  create_at: number
+}
+
 // 统计卡片
 export interface TaskCounter {
   title: string
@@ -117,6 +242,79 @@ export interface LoginRequest {
 // 登录响应
 export interface LoginResponse {
   token: string
+  name: string
+  must_change_password: boolean  // 必须先修改密码
+}
+
+// 角色：viewer 查看，operator 运行和取消，editor 修改，admin 管理用户和授权
+export type Role = 'viewer' | 'operator' | 'editor' | 'admin'
+
+// 角色授权，cid 为 0 时全局生效
+export interface RoleBinding {
+  id?: number
+  user_id: number
+  cid: number
+  role: Role
+  create_at?: number
+}
+
+// 用户生效的权限
+export interface Permissions {
+  global: Role | ''
+  containers: Record<number, Role>
+}
+
+// 用户
+export interface User {
+  id: number
+  name: string
+  disable: boolean
+  must_change_password: boolean
+  last_login_at: number
+  create_at: number
+  update_at: number
+}
+
+// 当前用户
+export interface Me extends User {
+  permissions: Permissions
+}
+
+// API 令牌权限范围
+export type TokenScope = 'read:containers' | 'run:container' | 'write:containers' | 'read:logs' | 'write:logs' | 'admin'
+
+// 个人 API 令牌
+export interface APIToken {
+  id: number
+  user_id: number
+  name: string
+  prefix: string
+  scopes: string
+  expire_at: number
+  last_used_at: number
+  create_at: number
+}
+
+// 新建的 API 令牌，token 只在创建时返回一次
+export interface APITokenCreated extends APIToken {
+  token: string
+}
+
+// 审计记录
+export interface AuditLog {
+  id: number
+  actor: string
+  user_id: number
+  token_id: number
+  action: 'create' | 'update' | 'delete' | 'run' | 'cancel'
+  target_type: string
+  target_id: string
+  diff: string // JSON: {"字段": {"before": 值, "after": 值}}
+  detail: string
+  method: string
+  path: string
+  ip: string
+  create_at: number
 }
 
 // 系统负载